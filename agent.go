@@ -0,0 +1,286 @@
+package majorca
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+const (
+	// defaultAgentRetry is how long the agent waits before reconnecting.
+	defaultAgentRetry = 10 * time.Second
+	// defaultAgentHeartbeat is how often the agent pings the server.
+	defaultAgentHeartbeat = 30 * time.Second
+)
+
+// AgentOptions configures StartAgent.
+type AgentOptions struct {
+	// URL is the wss:// endpoint of the management server.
+	URL string
+	// Token authenticates the device, sent as "Authorization: Bearer".
+	Token string
+	// DeviceID identifies the device to the server; empty uses the host
+	// name.
+	DeviceID string
+	// AllowedURLs lists the URL prefixes the server may navigate to, e.g.
+	// "https://signage.example.com/". Empty forbids navigation.
+	AllowedURLs []string
+	// Retry is the delay between connection attempts; zero means 10s.
+	Retry time.Duration
+	// Heartbeat is how often the agent pings the server; zero means 30s.
+	// When a ping goes unanswered for two intervals the connection is
+	// considered dead and re-dialed, which catches connections silently
+	// dropped by NATs and firewalls.
+	Heartbeat time.Duration
+}
+
+// Health is the report the agent sends for "health" and on connect.
+type Health struct {
+	Device  string        `json:"device"`
+	Version string        `json:"version"`
+	Browser string        `json:"browser,omitempty"`
+	Uptime  time.Duration `json:"uptime"`
+	Windows int           `json:"windows"`
+	Errors  int           `json:"errors"` // Recent library warnings and errors
+	Crash   string        `json:"crash,omitempty"`
+}
+
+// StartAgent connects out to a management server and keeps the connection
+// up until the returned function is called or the app exits. The server
+// sends ControlRequests, one per WebSocket message, and gets a
+// ControlResponse for each. Only a restricted set of commands is accepted:
+//
+//	health      return a Health report
+//	reload      reload Window
+//	navigate    load URL in Window, if it is in AllowedURLs
+//	screenshot  capture Window, see ServeControl
+//
+// On every connect the agent first sends a {"hello": Health} message.
+func (a *App) StartAgent(opts AgentOptions) (func(), error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil || u.Scheme != "wss" {
+		return nil, fmt.Errorf("agent URL must be a wss:// URL")
+	}
+	if opts.Token == "" {
+		return nil, errors.New("agent token is required")
+	}
+	if opts.DeviceID == "" {
+		opts.DeviceID, _ = os.Hostname()
+	}
+	if opts.Retry <= 0 {
+		opts.Retry = defaultAgentRetry
+	}
+	if opts.Heartbeat <= 0 {
+		opts.Heartbeat = defaultAgentHeartbeat
+	}
+
+	ag := &agent{app: a, opts: opts, started: time.Now(), stop: make(chan struct{})}
+	go ag.run()
+
+	var once sync.Once
+	return func() {
+		once.Do(ag.close)
+	}, nil
+}
+
+type agent struct {
+	app     *App
+	opts    AgentOptions
+	started time.Time
+	stop    chan struct{}
+
+	mu   sync.Mutex
+	conn browser.Conn
+}
+
+func (ag *agent) run() {
+	log := ag.app.main.Logger()
+	for {
+		if err := ag.session(); err != nil {
+			log.Warn("management connection failed", "error", err)
+		}
+		select {
+		case <-ag.stop:
+			return
+		case <-ag.app.Done():
+			return
+		case <-time.After(ag.opts.Retry):
+		}
+	}
+}
+
+// session serves one connection until it drops.
+func (ag *agent) session() error {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+ag.opts.Token)
+	conn, err := browser.DialWebSocket(ag.opts.URL, browser.WebSocketOptions{
+		Header:      header,
+		ReadLimit:   1 << 20,
+		Timeout:     30 * time.Second,
+		IdleTimeout: 2 * ag.opts.Heartbeat,
+	})
+	if err != nil {
+		return err
+	}
+	ag.mu.Lock()
+	select {
+	case <-ag.stop:
+		ag.mu.Unlock()
+		conn.Close()
+		return nil
+	default:
+	}
+	ag.conn = conn
+	ag.mu.Unlock()
+	defer conn.Close()
+
+	if err := conn.WriteJSON(map[string]Health{"hello": ag.health()}); err != nil {
+		return err
+	}
+	ag.app.main.Logger().Info("connected to management server", "url", ag.opts.URL)
+
+	done := make(chan struct{})
+	defer close(done)
+	go heartbeat(conn, ag.opts.Heartbeat, done)
+
+	for {
+		var req ControlRequest
+		var resp ControlResponse
+		err := conn.ReadJSON(&req)
+		if errors.Is(err, browser.ErrMessageTooLarge) {
+			continue
+		}
+		if browser.IsConnError(err) {
+			return err
+		}
+		if err != nil {
+			resp.Error = fmt.Sprintf("malformed request: %v", err)
+		} else {
+			resp.ID = req.ID
+			result, err := ag.command(req)
+			if err == nil && result != nil {
+				resp.Result, err = json.Marshal(result)
+			}
+			if err != nil {
+				resp.Error = err.Error()
+			}
+		}
+		if err := conn.WriteJSON(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// heartbeat pings conn every interval until done is closed. A ping that is
+// not answered within the connection's IdleTimeout closes it.
+func heartbeat(conn browser.WebSocket, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := conn.Ping(); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// command executes the restricted command set.
+func (ag *agent) command(req ControlRequest) (interface{}, error) {
+	switch req.Command {
+	case "health":
+		return ag.health(), nil
+	case "reload":
+		w, err := ag.app.controlWindow(req.Window)
+		if err != nil {
+			return nil, err
+		}
+		return nil, w.Reload(false)
+	case "navigate":
+		if !urlAllowed(req.URL, ag.opts.AllowedURLs) {
+			return nil, fmt.Errorf("navigation to %q is not allowed", req.URL)
+		}
+		return ag.app.control(req)
+	case "screenshot":
+		return ag.app.control(req)
+	}
+	return nil, fmt.Errorf("command %q is not allowed", req.Command)
+}
+
+func (ag *agent) health() Health {
+	h := Health{
+		Device:  ag.opts.DeviceID,
+		Version: browser.ModuleVersion(),
+		Uptime:  time.Since(ag.started).Round(time.Second),
+		Windows: len(ag.app.Windows()),
+		Errors:  len(ag.app.errors.lines()),
+	}
+	if product, err := ag.app.main.Product(); err == nil {
+		h.Browser = product
+	}
+	if crash := ag.app.main.LastCrashReport(); crash != nil {
+		h.Crash = crash.Time.Format(time.RFC3339) + " " + crash.Err
+	}
+	return h
+}
+
+func (ag *agent) close() {
+	ag.mu.Lock()
+	close(ag.stop)
+	if ag.conn != nil {
+		ag.conn.Close()
+	}
+	ag.mu.Unlock()
+}
+
+// urlAllowed reports whether target has the scheme and host of one of the
+// allowed prefixes and its path lies under the prefix's path, so that
+// "https://a.example" does not admit "https://a.example.evil.com" and
+// "https://a.example/app/" admits neither "/app/../admin" nor "/application".
+// Browsers read a backslash as a slash and may decode escaped separators, so
+// targets containing either are refused rather than compared.
+func urlAllowed(target string, allowed []string) bool {
+	if hasAmbiguousSeparator(target) {
+		return false
+	}
+	t, err := url.Parse(target)
+	if err != nil || t.Host == "" {
+		return false
+	}
+	tp := path.Clean("/" + t.Path)
+	for _, prefix := range allowed {
+		p, err := url.Parse(prefix)
+		if err != nil {
+			continue
+		}
+		pp := strings.TrimSuffix(path.Clean("/"+p.Path), "/")
+		if strings.EqualFold(t.Scheme, p.Scheme) && strings.EqualFold(t.Host, p.Host) &&
+			(tp == pp || strings.HasPrefix(tp, pp+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAmbiguousSeparator reports whether s contains a backslash or a
+// percent-encoded slash or backslash before its query or fragment.
+func hasAmbiguousSeparator(s string) bool {
+	if i := strings.IndexAny(s, "?#"); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.ToLower(s)
+	return strings.Contains(s, `\`) || strings.Contains(s, "%2f") || strings.Contains(s, "%5c")
+}
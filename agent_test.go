@@ -0,0 +1,55 @@
+package majorca_test
+
+import (
+	"testing"
+
+	"github.com/grngxd/majorca"
+)
+
+func TestURLAllowed(t *testing.T) {
+	allowed := []string{"https://signage.example.com/app/", "https://cdn.example.com"}
+	tests := map[string]bool{
+		"https://signage.example.com/app/":               true,
+		"https://signage.example.com/app":                true,
+		"https://signage.example.com/app/board?id=1":     true,
+		"https://SIGNAGE.example.com/app/x":              true,
+		"https://cdn.example.com/anything":               true,
+		"https://signage.example.com/app/../admin":       false,
+		"https://signage.example.com/app/%2e%2e/admin":   false,
+		"https://signage.example.com/application":        false,
+		"https://signage.example.com/":                   false,
+		"http://signage.example.com/app/":                false,
+		"https://signage.example.com.evil.com/app/":      false,
+		"https://evil.com/https://signage.example.com/":  false,
+		`https://signage.example.com/app/x\..\..\admin`:  false,
+		`https://signage.example.com\app/`:               false,
+		"https://signage.example.com/app/x%2f..%2fadmin": false,
+		"https://signage.example.com/app/x%5C..%5Cadmin": false,
+		"https://signage.example.com/app/?next=%2fadmin": true,
+		"javascript:alert(1)":                            false,
+		"/app/relative":                                  false,
+	}
+	for target, want := range tests {
+		if got := majorca.URLAllowed(target, allowed); got != want {
+			t.Errorf("urlAllowed(%q) = %v, want %v", target, got, want)
+		}
+	}
+}
+
+func TestAgentCommandFilter(t *testing.T) {
+	opts := majorca.AgentOptions{AllowedURLs: []string{"https://signage.example.com/"}}
+	tests := []struct {
+		req  majorca.ControlRequest
+		want string
+	}{
+		{majorca.ControlRequest{Command: "eval"}, `command "eval" is not allowed`},
+		{majorca.ControlRequest{Command: "exec"}, `command "exec" is not allowed`},
+		{majorca.ControlRequest{Command: "navigate", URL: "https://evil.com/"}, `navigation to "https://evil.com/" is not allowed`},
+	}
+	for _, tt := range tests {
+		_, err := majorca.AgentCommand(nil, opts, tt.req)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%+v: got %v, want %q", tt.req, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package majorca
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/grngxd/majorca/browser"
+	"github.com/grngxd/majorca/browser/chrome"
+)
+
+// App is a multi-window application. All windows share one browser process,
+// one set of Go bindings and a message bus between windows.
+type App struct {
+	mu       sync.Mutex
+	main     *chrome.Chrome
+	mainUsed bool
+	nextID   int
+	windows  map[int]*Window
+	bindings map[string]browser.BindingFunc
+	bus      bus
+	errors   *errorLog // Recent library errors for the diagnostics page
+	events   *eventLog // See Events
+}
+
+// Window is a single app window. It embeds the Chrome connection to its
+// page, so Load, Eval, SetBounds and friends work per window.
+type Window struct {
+	*chrome.Chrome
+	ID  int
+	app *App
+}
+
+// WindowOptions configures a new window. Zero sizes keep the defaults.
+type WindowOptions struct {
+	URL    string
+	Width  int
+	Height int
+	Left   int
+	Top    int
+}
+
+// NewApp launches the shared browser process. The first window created with
+// NewWindow reuses the window Chrome opens on launch.
+func NewApp(opts ...browser.Option) (*App, error) {
+	return newApp(chrome.New, opts...)
+}
+
+// newApp builds an App around the browser connect returns.
+func newApp(connect func(...browser.Option) (*chrome.Chrome, error), opts ...browser.Option) (*App, error) {
+	var next slog.Handler
+	if l := browser.NewOptions(opts...).Logger; l != nil {
+		next = l.Handler()
+	}
+	events := &eventLog{}
+	errlog := newErrorLog(next, events)
+	opts = append(opts, browser.WithLogger(slog.New(errlog)))
+
+	c, err := connect(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &App{
+		main:     c,
+		windows:  make(map[int]*Window),
+		bindings: make(map[string]browser.BindingFunc),
+		errors:   errlog,
+		events:   events,
+	}, nil
+}
+
+// NewWindow opens a window and registers all app bindings in it.
+func (a *App) NewWindow(opts WindowOptions) (*Window, error) {
+	url := opts.URL
+	if url == "" || url == DiagnosticsURL {
+		url = "about:blank"
+	}
+
+	a.mu.Lock()
+	reuse := !a.mainUsed
+	a.mainUsed = true
+	a.nextID++
+	id := a.nextID
+	a.mu.Unlock()
+
+	var c *chrome.Chrome
+	var err error
+	if reuse {
+		c = a.main
+		err = c.Load(url)
+	} else {
+		c, err = a.main.OpenWindow(url)
+	}
+	if err != nil {
+		if reuse {
+			a.releaseMain()
+		}
+		return nil, err
+	}
+
+	w := &Window{Chrome: c, ID: id, app: a}
+	if opts.Width > 0 || opts.Height > 0 || opts.Left != 0 || opts.Top != 0 {
+		if err := c.SetBounds(browser.Bounds{Left: opts.Left, Top: opts.Top, Width: opts.Width, Height: opts.Height}); err != nil {
+			a.closeWindow(w)
+			return nil, err
+		}
+	}
+
+	if err := a.install(w); err != nil {
+		a.closeWindow(w)
+		return nil, err
+	}
+
+	a.mu.Lock()
+	a.windows[id] = w
+	a.mu.Unlock()
+	a.trackWindow(w)
+
+	if opts.URL == DiagnosticsURL {
+		if err := w.ShowDiagnostics(); err != nil {
+			a.mu.Lock()
+			delete(a.windows, id)
+			a.mu.Unlock()
+			a.closeWindow(w)
+			return nil, err
+		}
+	}
+
+	go func() {
+		<-c.Done()
+		a.mu.Lock()
+		delete(a.windows, id)
+		a.mu.Unlock()
+	}()
+
+	return w, nil
+}
+
+// install registers the app bindings and the message bus in w.
+func (a *App) install(w *Window) error {
+	a.mu.Lock()
+	bindings := make(map[string]browser.BindingFunc, len(a.bindings))
+	for name, f := range a.bindings {
+		bindings[name] = f
+	}
+	a.mu.Unlock()
+
+	for name, f := range bindings {
+		if err := w.Bind(name, a.loggedBinding(w.ID, name, f)); err != nil {
+			return err
+		}
+	}
+	return a.installBus(w)
+}
+
+// closeWindow undoes a window NewWindow failed to set up. The main page
+// stays open and is handed to the next NewWindow.
+func (a *App) closeWindow(w *Window) {
+	if w.Chrome != a.main {
+		w.Kill()
+		return
+	}
+	// Forget what install registered, so the next window can bind again.
+	a.mu.Lock()
+	names := []string{busBinding}
+	for name := range a.bindings {
+		names = append(names, name)
+	}
+	a.mu.Unlock()
+	w.Lock()
+	for _, name := range names {
+		delete(w.Bindings, name)
+	}
+	w.Unlock()
+	a.releaseMain()
+}
+
+func (a *App) releaseMain() {
+	a.mu.Lock()
+	a.mainUsed = false
+	a.mu.Unlock()
+}
+
+// Bind exposes f as name in every current and future window.
+func (a *App) Bind(name string, f browser.BindingFunc) error {
+	a.mu.Lock()
+	if _, exists := a.bindings[name]; exists {
+		a.mu.Unlock()
+		return fmt.Errorf("binding %s already exists", name)
+	}
+	a.bindings[name] = f
+	windows := a.windowList()
+	a.mu.Unlock()
+
+	for _, w := range windows {
+		if err := w.Bind(name, a.loggedBinding(w.ID, name, f)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Windows returns the open windows.
+func (a *App) Windows() []*Window {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.windowList()
+}
+
+func (a *App) windowList() []*Window {
+	windows := make([]*Window, 0, len(a.windows))
+	for _, w := range a.windows {
+		windows = append(windows, w)
+	}
+	return windows
+}
+
+// Window returns the open window with the given id.
+func (a *App) Window(id int) (*Window, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	w, ok := a.windows[id]
+	return w, ok
+}
+
+// Done is closed when the browser process exits.
+func (a *App) Done() <-chan struct{} {
+	return a.main.Done()
+}
+
+// Quit closes all windows and stops the browser.
+func (a *App) Quit() error {
+	return a.main.Kill()
+}
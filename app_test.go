@@ -0,0 +1,109 @@
+package majorca_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grngxd/majorca"
+	"github.com/grngxd/majorca/internal/cdptest"
+)
+
+// fakePages answers like Chrome pages do, records the bus dispatches each
+// target evaluates and fails the methods in fail.
+type fakePages struct {
+	mu         sync.Mutex
+	fail       map[string]bool
+	dispatches map[string][]string
+}
+
+func newFakePages() *fakePages {
+	return &fakePages{fail: make(map[string]bool), dispatches: make(map[string][]string)}
+}
+
+func (p *fakePages) reply(target, method string, params json.RawMessage) interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fail[method] {
+		return errors.New(method + " failed")
+	}
+	if method == "Runtime.evaluate" {
+		var e struct {
+			Expression string `json:"expression"`
+		}
+		json.Unmarshal(params, &e)
+		const prefix = "window.majorca && window.majorca.dispatch("
+		if strings.HasPrefix(e.Expression, prefix) {
+			call := strings.TrimSuffix(strings.TrimPrefix(e.Expression, prefix), ")")
+			p.dispatches[target] = append(p.dispatches[target], call)
+		}
+	}
+	return nil
+}
+
+func (p *fakePages) setFail(method string, fail bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fail[method] = fail
+}
+
+// takeDispatches returns and forgets the dispatches target evaluated.
+func (p *fakePages) takeDispatches(target string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	d := p.dispatches[target]
+	delete(p.dispatches, target)
+	return d
+}
+
+func newFakeApp(t *testing.T, p *fakePages) (*majorca.App, *cdptest.Server) {
+	t.Helper()
+	d := cdptest.New(t, p.reply)
+	a, err := majorca.AttachApp(d.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.Quit() })
+	return a, d
+}
+
+func TestNewWindowCleanup(t *testing.T) {
+	p := newFakePages()
+	a, d := newFakeApp(t, p)
+
+	// The first window reuses the main page, which must survive a failure
+	// and be reused again.
+	p.setFail("Runtime.addBinding", true)
+	if _, err := a.NewWindow(majorca.WindowOptions{}); err == nil {
+		t.Fatal("NewWindow succeeded although the bus could not be bound")
+	}
+	p.setFail("Runtime.addBinding", false)
+	if n := len(a.Windows()); n != 0 {
+		t.Errorf("failed window is listed: %d windows", n)
+	}
+	select {
+	case <-a.Done():
+		t.Fatal("a failed first window closed the main page")
+	default:
+	}
+	if _, err := a.NewWindow(majorca.WindowOptions{}); err != nil {
+		t.Fatalf("NewWindow after a failure: %v", err)
+	}
+	if d.Called("Target.createTarget") {
+		t.Error("the main page was not reused after a failed first window")
+	}
+
+	// Later windows are new pages, closed again when setting them up fails.
+	p.setFail("Browser.setWindowBounds", true)
+	if _, err := a.NewWindow(majorca.WindowOptions{Width: 800, Height: 600}); err == nil {
+		t.Fatal("NewWindow succeeded although its bounds could not be set")
+	}
+	if !d.Called("Page.close") {
+		t.Error("the page of the failed window was not closed")
+	}
+	if n := len(a.Windows()); n != 1 {
+		t.Errorf("got %d windows after a failed second window, want 1", n)
+	}
+}
@@ -0,0 +1,98 @@
+package browser
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"os"
+	"runtime"
+)
+
+// HostArch returns the native architecture of the machine in GOARCH terms.
+// It differs from runtime.GOARCH when the app itself runs under emulation,
+// e.g. an amd64 build on Windows-on-ARM or under Rosetta on Apple Silicon.
+func HostArch() string {
+	if arch := nativeArch(); arch != "" {
+		return arch
+	}
+	return runtime.GOARCH
+}
+
+// BinaryArchs reports the architectures an executable was built for.
+// Universal macOS binaries report more than one.
+func BinaryArchs(path string) ([]string, error) {
+	if f, err := pe.Open(path); err == nil {
+		defer f.Close()
+		switch f.Machine {
+		case pe.IMAGE_FILE_MACHINE_AMD64:
+			return []string{"amd64"}, nil
+		case pe.IMAGE_FILE_MACHINE_I386:
+			return []string{"386"}, nil
+		case pe.IMAGE_FILE_MACHINE_ARM64:
+			return []string{"arm64"}, nil
+		}
+		return nil, nil
+	}
+
+	if f, err := macho.OpenFat(path); err == nil {
+		defer f.Close()
+		var archs []string
+		for _, a := range f.Arches {
+			archs = append(archs, machoArch(a.Cpu)...)
+		}
+		return archs, nil
+	}
+	if f, err := macho.Open(path); err == nil {
+		defer f.Close()
+		return machoArch(f.Cpu), nil
+	}
+
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	switch f.Machine {
+	case elf.EM_X86_64:
+		return []string{"amd64"}, nil
+	case elf.EM_386:
+		return []string{"386"}, nil
+	case elf.EM_AARCH64:
+		return []string{"arm64"}, nil
+	}
+	return nil, nil
+}
+
+func machoArch(cpu macho.Cpu) []string {
+	switch cpu {
+	case macho.CpuAmd64:
+		return []string{"amd64"}
+	case macho.CpuArm64:
+		return []string{"arm64"}
+	}
+	return nil
+}
+
+// SelectBinary returns the first existing path built for the host
+// architecture, falling back to the first existing path at all so that
+// emulated binaries are still used when nothing native is installed.
+func SelectBinary(paths []string) (string, bool) {
+	host := HostArch()
+	fallback := ""
+	for _, p := range paths {
+		p = os.ExpandEnv(p)
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		archs, _ := BinaryArchs(p)
+		for _, a := range archs {
+			if a == host {
+				return p, true
+			}
+		}
+		if fallback == "" {
+			fallback = p
+		}
+	}
+	return fallback, fallback != ""
+}
@@ -0,0 +1,15 @@
+package browser
+
+import (
+	"runtime"
+	"syscall"
+)
+
+// nativeArch detects Apple Silicon when the app runs translated by Rosetta.
+func nativeArch() string {
+	translated, err := syscall.SysctlUint32("sysctl.proc_translated")
+	if err == nil && translated == 1 {
+		return "arm64"
+	}
+	return runtime.GOARCH
+}
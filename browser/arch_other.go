@@ -0,0 +1,9 @@
+//go:build !windows && !darwin
+
+package browser
+
+import "runtime"
+
+func nativeArch() string {
+	return runtime.GOARCH
+}
@@ -0,0 +1,28 @@
+package browser_test
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+func TestBinaryArchs(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("Failed to locate test binary: %v", err)
+	}
+
+	archs, err := browser.BinaryArchs(exe)
+	if err != nil {
+		t.Fatalf("Failed to read binary architecture: %v", err)
+	}
+	found := false
+	for _, a := range archs {
+		found = found || a == runtime.GOARCH
+	}
+	if !found {
+		t.Errorf("BinaryArchs returned %v, want %s", archs, runtime.GOARCH)
+	}
+}
@@ -0,0 +1,41 @@
+package browser
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procIsWow64Process2 = syscall.NewLazyDLL("kernel32.dll").NewProc("IsWow64Process2")
+
+// nativeArch asks Windows for the native machine type, which is the only
+// reliable way to detect ARM64 hardware from an emulated x86/x64 process.
+func nativeArch() string {
+	if procIsWow64Process2.Find() != nil {
+		return "" // Windows older than 10 1511
+	}
+
+	process, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return ""
+	}
+
+	var processMachine, nativeMachine uint16
+	r, _, _ := procIsWow64Process2.Call(
+		uintptr(process),
+		uintptr(unsafe.Pointer(&processMachine)),
+		uintptr(unsafe.Pointer(&nativeMachine)),
+	)
+	if r == 0 {
+		return ""
+	}
+
+	switch nativeMachine {
+	case 0xAA64: // IMAGE_FILE_MACHINE_ARM64
+		return "arm64"
+	case 0x8664: // IMAGE_FILE_MACHINE_AMD64
+		return "amd64"
+	case 0x14c: // IMAGE_FILE_MACHINE_I386
+		return "386"
+	}
+	return ""
+}
@@ -0,0 +1,194 @@
+package browser
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/grngxd/majorca/browser/dialog"
+	"github.com/grngxd/majorca/browser/fetcher"
+)
+
+// Factory describes a browser backend to the registry.
+type Factory struct {
+	// New launches the backend.
+	New func(opts ...Option) (Browser, error)
+	// Available reports whether the backend can run on this machine, e.g.
+	// whether its browser is installed. Nil means always.
+	Available func() bool
+	// Priority orders backends during automatic selection; higher wins.
+	Priority int
+	// Description is shown in diagnostics and tooling.
+	Description string
+}
+
+var (
+	backendsMu sync.Mutex
+	backends   = make(map[string]Factory)
+)
+
+// Register makes a backend available under name, so it can be selected with
+// WithBackend or picked automatically by majorca.New. Backends usually
+// register themselves in an init function. Register panics if name is taken
+// or factory has no New function.
+func Register(name string, factory Factory) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	if factory.New == nil {
+		panic("browser: Register factory for " + name + " has no New function")
+	}
+	if _, dup := backends[name]; dup {
+		panic("browser: Register called twice for backend " + name)
+	}
+	backends[name] = factory
+}
+
+// Lookup returns the backend registered under name.
+func Lookup(name string) (Factory, bool) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	f, ok := backends[name]
+	return f, ok
+}
+
+// Backends returns the names of all registered backends, highest priority
+// first.
+func Backends() []string {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := backends[names[i]], backends[names[j]]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// Fallback handles a machine without a supported browser. It receives the
+// error Auto would return and reports whether Auto should look again, e.g.
+// after the handler downloaded a browser or the user installed one.
+type Fallback func(err error) (retry bool)
+
+// MissingBrowserText is the message ShowMissingBrowser displays.
+var MissingBrowserText = "This app needs Google Chrome, Microsoft Edge or Firefox to run. " +
+	"Please install one of them and start the app again."
+
+// ShowMissingBrowser is the default Fallback. It tells the user in a native
+// message box that a browser is required and does not retry.
+func ShowMissingBrowser(err error) bool {
+	dialog.Message("Browser required", MissingBrowserText)
+	return false
+}
+
+// Open launches a backend: the one selected with WithBackend, or otherwise
+// the one chosen by Auto. With WithCriticalRetry the backend is wrapped so
+// that it is relaunched when it dies, with WithLazyLaunch it only starts
+// once needed, and WithSignalHandling installs HandleSignals.
+func Open(opts ...Option) (Browser, error) {
+	o := NewOptions(opts...)
+	launch := func() (Browser, error) { return open(o, opts) }
+	if o.CriticalRetry {
+		launch = func() (Browser, error) {
+			r, err := newRetrying(func() (Browser, error) { return open(o, opts) }, o.Logger)
+			if err != nil {
+				return nil, err
+			}
+			return r, nil
+		}
+	}
+
+	var b Browser
+	var err error
+	if o.LazyLaunch {
+		b = newLazy(launch)
+	} else if b, err = launch(); err != nil {
+		return nil, err
+	}
+	if o.HandleSignals {
+		HandleSignals(b)
+	}
+	return b, nil
+}
+
+func open(o *Options, opts []Option) (Browser, error) {
+	if o.Backend == "" {
+		return Auto(opts...)
+	}
+	f, ok := Lookup(o.Backend)
+	if !ok {
+		return nil, fmt.Errorf("unknown browser backend %q", o.Backend)
+	}
+	return f.New(opts...)
+}
+
+// Auto launches the best backend available on this machine. Backends named
+// with WithPreferredBackends are tried first, in the given order, followed
+// by all others by priority. The built-in priorities are:
+//
+//	chrome    100  Chrome, Chromium, Edge, Brave
+//	webkit     60  Safari, macOS only
+//	firefox    50  Firefox
+//	webview2   40  Edge WebView2 Runtime, Windows only
+//
+// Backends only take part once their package is imported; the majorca
+// package imports all built-in ones. If none is available, the build pinned
+// with WithBrowserDownload is provisioned and launched. Without one the
+// error wraps ErrNoBrowser, after consulting the handler set with
+// WithFallbackMessage.
+func Auto(opts ...Option) (Browser, error) {
+	o := NewOptions(opts...)
+	for {
+		if f, ok := available(o.PreferredBackends); ok {
+			return f.New(opts...)
+		}
+		if f, ok := downloadable(o.BrowserManifest); ok {
+			return f.New(opts...)
+		}
+		err := fmt.Errorf("%w, tried %v", ErrNoBrowser, Backends())
+		if o.Fallback == nil || !o.Fallback(err) {
+			return nil, err
+		}
+	}
+}
+
+// downloadable returns the backend able to launch the build pinned by m,
+// which provisions it on launch.
+func downloadable(m *fetcher.Manifest) (Factory, bool) {
+	if m == nil {
+		return Factory{}, false
+	}
+	name := m.Browser
+	if name == "chromium" {
+		name = "chrome"
+	}
+	return Lookup(name)
+}
+
+// available returns the first backend that can run here, trying preferred
+// ones first.
+func available(preferred []string) (Factory, bool) {
+	order := append([]string(nil), preferred...)
+	order = append(order, Backends()...)
+
+	tried := make(map[string]bool)
+	for _, name := range order {
+		if tried[name] {
+			continue
+		}
+		tried[name] = true
+		f, ok := Lookup(name)
+		if !ok {
+			continue
+		}
+		if f.Available == nil || f.Available() {
+			return f, true
+		}
+	}
+	return Factory{}, false
+}
@@ -0,0 +1,163 @@
+package browser_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+func TestOpenSelectsBackend(t *testing.T) {
+	errLow := errors.New("low")
+	errHigh := errors.New("high")
+	browser.Register("test-low", browser.Factory{
+		New:      func(...browser.Option) (browser.Browser, error) { return nil, errLow },
+		Priority: 1000,
+	})
+	browser.Register("test-high", browser.Factory{
+		New:       func(...browser.Option) (browser.Browser, error) { return nil, errHigh },
+		Available: func() bool { return false },
+		Priority:  2000,
+	})
+
+	// The unavailable backend is skipped during automatic selection...
+	if _, err := browser.Open(); err != errLow {
+		t.Errorf("Automatic selection returned %v, want %v", err, errLow)
+	}
+	// ...but can still be requested explicitly.
+	if _, err := browser.Open(browser.WithBackend("test-high")); err != errHigh {
+		t.Errorf("Explicit selection returned %v, want %v", err, errHigh)
+	}
+	if _, err := browser.Open(browser.WithBackend("missing")); err == nil {
+		t.Error("Expected an error for an unknown backend")
+	}
+}
+
+func TestAutoPreference(t *testing.T) {
+	errPreferred := errors.New("preferred")
+	browser.Register("test-preferred", browser.Factory{
+		New:      func(...browser.Option) (browser.Browser, error) { return nil, errPreferred },
+		Priority: -1,
+	})
+
+	_, err := browser.Auto(browser.WithPreferredBackends("missing", "test-preferred"))
+	if err != errPreferred {
+		t.Errorf("Auto returned %v, want the preferred backend's %v", err, errPreferred)
+	}
+}
+
+type flaky struct {
+	browser.BaseBrowser
+	gen    int
+	loaded *[]string
+}
+
+func (f *flaky) Load(url string) error {
+	if f.gen == 1 && url == "https://example.com/b" {
+		return browser.ErrBrowserGone
+	}
+	*f.loaded = append(*f.loaded, url)
+	return nil
+}
+
+func TestCriticalRetry(t *testing.T) {
+	var loaded []string
+	launches := 0
+	browser.Register("test-flaky", browser.Factory{
+		New: func(...browser.Option) (browser.Browser, error) {
+			launches++
+			return &flaky{
+				BaseBrowser: browser.BaseBrowser{Stop: make(chan struct{}), Bindings: make(map[string]browser.BindingFunc)},
+				gen:         launches,
+				loaded:      &loaded,
+			}, nil
+		},
+		Available: func() bool { return false },
+	})
+
+	b, err := browser.Open(browser.WithBackend("test-flaky"), browser.WithCriticalRetry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Bind("hello", func([]json.RawMessage) (interface{}, error) { return nil, nil })
+	if err := b.Load("https://example.com/a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Load("https://example.com/b"); err != nil {
+		t.Fatalf("Expected the load to be retried, got %v", err)
+	}
+
+	if launches != 2 {
+		t.Errorf("Expected one restart, got %d launches", launches)
+	}
+	// The restarted browser restores the last page before retrying.
+	want := []string{"https://example.com/a", "https://example.com/a", "https://example.com/b"}
+	if fmt.Sprint(loaded) != fmt.Sprint(want) {
+		t.Errorf("Loaded %v, want %v", loaded, want)
+	}
+	if f := browser.Unwrap(b).(*flaky); f.Bindings["hello"] == nil {
+		t.Error("Expected bindings to be restored")
+	}
+}
+
+func TestCriticalRetryAfterKill(t *testing.T) {
+	var loaded []string
+	launches := 0
+	browser.Register("test-killed", browser.Factory{
+		New: func(...browser.Option) (browser.Browser, error) {
+			launches++
+			return &flaky{
+				BaseBrowser: browser.BaseBrowser{Stop: make(chan struct{}), Bindings: make(map[string]browser.BindingFunc)},
+				loaded:      &loaded,
+			}, nil
+		},
+		Available: func() bool { return false },
+	})
+
+	b, err := browser.Open(browser.WithBackend("test-killed"), browser.WithCriticalRetry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Kill()
+	if err := b.Load("https://example.com/a"); !errors.Is(err, browser.ErrBrowserGone) {
+		t.Errorf("Load after Kill returned %v, want ErrBrowserGone", err)
+	}
+	if launches != 1 || len(loaded) != 0 {
+		t.Errorf("Kill was followed by %d launches and loads %v", launches-1, loaded)
+	}
+}
+
+func TestLazyLaunch(t *testing.T) {
+	var loaded []string
+	launches := 0
+	browser.Register("test-lazy", browser.Factory{
+		New: func(...browser.Option) (browser.Browser, error) {
+			launches++
+			return &flaky{
+				BaseBrowser: browser.BaseBrowser{Stop: make(chan struct{}), Bindings: make(map[string]browser.BindingFunc)},
+				loaded:      &loaded,
+			}, nil
+		},
+		Available: func() bool { return false },
+	})
+
+	b, err := browser.Open(browser.WithBackend("test-lazy"), browser.WithLazyLaunch())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Bind("hello", func([]json.RawMessage) (interface{}, error) { return nil, nil })
+	if launches != 0 {
+		t.Fatalf("Expected no launch before first use, got %d", launches)
+	}
+	if err := b.Load("https://example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if launches != 1 || len(loaded) != 1 {
+		t.Errorf("Got %d launches and %d loads, want 1 each", launches, len(loaded))
+	}
+	if f := browser.Unwrap(b).(*flaky); f.Bindings["hello"] == nil {
+		t.Error("Expected bindings registered before launch to be installed")
+	}
+}
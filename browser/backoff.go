@@ -0,0 +1,83 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how often and how fast an operation is retried, such
+// as connecting to the browser's debugging endpoint while it starts.
+type RetryPolicy struct {
+	Attempts   int           // Total tries; zero or less tries once
+	Delay      time.Duration // Wait after the first failure
+	MaxDelay   time.Duration // Caps the growing delay; zero means no cap
+	Multiplier float64       // Delay growth per attempt; 1 or less keeps it constant
+	Jitter     float64       // Randomizes each delay by up to ± this fraction, e.g. 0.2
+	Deadline   time.Duration // Gives up once this much time has passed; zero means none
+}
+
+// DefaultConnectRetry is used to connect to a starting browser unless
+// WithConnectRetry says otherwise: ten attempts, one second apart.
+var DefaultConnectRetry = RetryPolicy{Attempts: 10, Delay: time.Second}
+
+// ExponentialBackoff returns a policy that doubles the delay after every
+// failure, starting at base and capped at max, with 20% jitter so that many
+// clients don't retry in lockstep.
+func ExponentialBackoff(attempts int, base, max time.Duration) RetryPolicy {
+	return RetryPolicy{Attempts: attempts, Delay: base, MaxDelay: max, Multiplier: 2, Jitter: 0.2}
+}
+
+// Retry calls fn until it succeeds, the attempts are used up or the
+// deadline passed. On failure the error joins the reason of every attempt.
+func (p RetryPolicy) Retry(fn func() error) error {
+	return p.RetryWithin(func(time.Duration) error { return fn() })
+}
+
+// RetryWithin is Retry for attempts that block, such as waiting for a port:
+// fn gets the time left before the Deadline and should return by then.
+// Without a Deadline it gets zero.
+func (p RetryPolicy) RetryWithin(fn func(remaining time.Duration) error) error {
+	start := time.Now()
+	var errs []error
+	for i := 0; ; i++ {
+		var remaining time.Duration
+		if p.Deadline > 0 {
+			remaining = p.Deadline - time.Since(start)
+			if remaining <= 0 && i > 0 {
+				break
+			}
+		}
+		err := fn(remaining)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("attempt %d: %w", i+1, err))
+		if i+1 >= p.Attempts {
+			break
+		}
+		wait := p.delay(i)
+		if p.Deadline > 0 && time.Since(start)+wait >= p.Deadline {
+			break
+		}
+		time.Sleep(wait)
+	}
+	return fmt.Errorf("failed after %d attempts: %w", len(errs), errors.Join(errs...))
+}
+
+// delay returns the wait after failed attempt i, counting from zero.
+func (p RetryPolicy) delay(i int) time.Duration {
+	d := float64(p.Delay)
+	if p.Multiplier > 1 {
+		d *= math.Pow(p.Multiplier, float64(i))
+	}
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(d)
+}
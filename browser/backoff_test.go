@@ -0,0 +1,75 @@
+package browser_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	errRefused := errors.New("refused")
+	calls := 0
+	err := browser.ExponentialBackoff(3, time.Millisecond, 2*time.Millisecond).Retry(func() error {
+		calls++
+		return errRefused
+	})
+	if calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls)
+	}
+	if !errors.Is(err, errRefused) || !strings.Contains(err.Error(), "attempt 3: refused") {
+		t.Errorf("Expected every attempt's error, got %v", err)
+	}
+
+	calls = 0
+	p := browser.RetryPolicy{Attempts: 100, Delay: 20 * time.Millisecond, Deadline: 50 * time.Millisecond}
+	p.Retry(func() error {
+		calls++
+		return errRefused
+	})
+	if calls > 3 {
+		t.Errorf("Expected the deadline to stop retrying, got %d attempts", calls)
+	}
+
+	calls = 0
+	if err := p.Retry(func() error {
+		calls++
+		if calls < 2 {
+			return errRefused
+		}
+		return nil
+	}); err != nil {
+		t.Errorf("Expected success on the second attempt, got %v", err)
+	}
+}
+
+func TestRetryWithin(t *testing.T) {
+	p := browser.RetryPolicy{Attempts: 100, Delay: 10 * time.Millisecond, Deadline: 100 * time.Millisecond}
+	start := time.Now()
+	var got []time.Duration
+	p.RetryWithin(func(remaining time.Duration) error {
+		got = append(got, remaining)
+		// A blocking attempt, e.g. waiting for a port, uses up its share.
+		time.Sleep(remaining / 2)
+		return errors.New("refused")
+	})
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("retrying took %v, want the 100ms deadline to hold", elapsed)
+	}
+	for i, r := range got {
+		if r <= 0 || r > p.Deadline || (i > 0 && r >= got[i-1]) {
+			t.Errorf("attempt %d got %v remaining of %v", i+1, r, got)
+		}
+	}
+
+	p.Deadline = 0
+	p.Attempts = 2
+	p.RetryWithin(func(remaining time.Duration) error {
+		if remaining != 0 {
+			t.Errorf("got %v remaining without a deadline", remaining)
+		}
+		return errors.New("refused")
+	})
+}
@@ -0,0 +1,344 @@
+// Package bidi is an experimental Chromium backend that speaks WebDriver
+// BiDi through chromedriver instead of the DevTools Protocol. It implements
+// the same browser.Browser API as the chrome package and registers itself as
+// "chromium-bidi"; import it for its side effect to make it selectable:
+//
+//	import _ "github.com/grngxd/majorca/browser/bidi"
+//
+//	b, err := majorca.New(browser.WithBackend("chromium-bidi"))
+package bidi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// Chromium is a Chromium instance driven over WebDriver BiDi.
+type Chromium struct {
+	browser.BaseBrowser
+
+	driverURL string // chromedriver's HTTP endpoint
+	session   string
+	context   string // Top-level browsing context
+	waitUntil browser.WaitUntil
+}
+
+// message is any frame received over the BiDi connection.
+type message struct {
+	Type    string          `json:"type"` // "success", "error" or "event"
+	ID      int32           `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   string          `json:"error"`
+	Message string          `json:"message"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+func init() {
+	browser.Register("chromium-bidi", browser.Factory{
+		New: func(opts ...browser.Option) (browser.Browser, error) {
+			return New(opts...)
+		},
+		Available: func() bool {
+			_, err := FindDriver()
+			return err == nil
+		},
+		Priority:    10,
+		Description: "Experimental: Chromium via WebDriver BiDi and chromedriver",
+	})
+}
+
+// New starts chromedriver, which launches Chromium with a throwaway profile
+// unless WithProfileDir is given.
+func New(opts ...browser.Option) (*Chromium, error) {
+	o := browser.NewOptions(opts...)
+
+	driver, err := FindDriver()
+	if err != nil {
+		return nil, err
+	}
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Chromium{
+		BaseBrowser: browser.BaseBrowser{
+			Pending:      make(map[string]chan interface{}),
+			Bindings:     make(map[string]browser.BindingFunc),
+			Path:         driver,
+			Log:          o.Logger,
+			Timeout:      o.CommandTimeout,
+			TraceEnabled: o.Trace,
+			Redact:       o.Redaction,
+			ReadLimit:    o.ReadLimit,
+			Compression:  o.Compression,
+			Stop:         make(chan struct{}),
+		},
+		driverURL: "http://" + net.JoinHostPort("127.0.0.1", strconv.Itoa(port)),
+		waitUntil: o.WaitUntil,
+	}
+
+	c.Cmd = exec.Command(driver, "--port="+strconv.Itoa(port))
+	c.Cmd.Stdout = o.Stdout
+	c.Cmd.Stderr = o.Stderr
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+	if !waitForPort(port, 10*time.Second) {
+		c.Kill()
+		return nil, fmt.Errorf("chromedriver did not open port %d", port)
+	}
+
+	wsURL, err := c.newSession(o)
+	if err != nil {
+		c.Kill()
+		return nil, err
+	}
+	c.Logger().Debug("connecting to BiDi endpoint", "url", wsURL)
+	ws, err := c.Dial(wsURL)
+	if err != nil {
+		c.Kill()
+		return nil, fmt.Errorf("failed to dial WebSocket: %w", err)
+	}
+	c.Ws = ws
+
+	c.Wg.Add(1)
+	go c.handleResponse()
+
+	if err := c.setup(); err != nil {
+		c.Kill()
+		return nil, err
+	}
+	return c, nil
+}
+
+// newSession asks chromedriver for a session with BiDi enabled and returns
+// its WebSocket URL.
+func (c *Chromium) newSession(o *browser.Options) (string, error) {
+	args := append([]string(nil), o.Args...)
+	if o.Headless {
+		args = append(args, "--headless=new")
+	}
+	if o.Width > 0 && o.Height > 0 {
+		args = append(args, fmt.Sprintf("--window-size=%d,%d", o.Width, o.Height))
+	}
+	if o.HasPosition {
+		args = append(args, fmt.Sprintf("--window-position=%d,%d", o.X, o.Y))
+	}
+	profile, err := o.Profile()
+	if err != nil {
+		return "", err
+	}
+	if profile != "" {
+		args = append(args, "--user-data-dir="+profile)
+	}
+	chromeOptions := map[string]interface{}{"args": args}
+	if o.ExecutablePath != "" {
+		chromeOptions["binary"] = o.ExecutablePath
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"alwaysMatch": map[string]interface{}{
+				"webSocketUrl":       true,
+				"goog:chromeOptions": chromeOptions,
+			},
+		},
+	})
+	resp, err := http.Post(c.driverURL+"/session", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		Value struct {
+			SessionID    string `json:"sessionId"`
+			Capabilities struct {
+				WebSocketURL string `json:"webSocketUrl"`
+			} `json:"capabilities"`
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		} `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", fmt.Errorf("failed to decode session: %w", err)
+	}
+	if res.Value.Error != "" {
+		return "", fmt.Errorf("failed to create session: %s: %s", res.Value.Error, res.Value.Message)
+	}
+	if res.Value.Capabilities.WebSocketURL == "" {
+		return "", fmt.Errorf("chromedriver does not support WebDriver BiDi")
+	}
+	c.session = res.Value.SessionID
+	return res.Value.Capabilities.WebSocketURL, nil
+}
+
+// setup finds the top-level browsing context and subscribes to the events
+// the backend relies on.
+func (c *Chromium) setup() error {
+	raw, err := c.Send("browsingContext.getTree", map[string]interface{}{"maxDepth": 0})
+	if err != nil {
+		return err
+	}
+	var tree struct {
+		Contexts []struct {
+			Context string `json:"context"`
+		} `json:"contexts"`
+	}
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return fmt.Errorf("failed to unmarshal browsing contexts: %w", err)
+	}
+	if len(tree.Contexts) == 0 {
+		return fmt.Errorf("no browsing context found")
+	}
+	c.context = tree.Contexts[0].Context
+
+	c.On("script.message", c.onMessage)
+	_, err = c.Send("session.subscribe", map[string]interface{}{
+		"events": []string{"script.message"},
+	})
+	return err
+}
+
+// Kill ends the session, which closes Chromium, and stops chromedriver.
+func (c *Chromium) Kill() error {
+	if c.session != "" {
+		req, _ := http.NewRequest(http.MethodDelete, c.driverURL+"/session/"+c.session, nil)
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			resp.Body.Close()
+		}
+		c.session = ""
+	}
+	return c.BaseBrowser.Kill()
+}
+
+// handleResponse listens for responses from the WebSocket and dispatches them.
+func (c *Chromium) handleResponse() {
+	defer c.Wg.Done()
+	for {
+		select {
+		case <-c.Stop:
+			return
+		default:
+			var msg message
+			if err := c.Ws.ReadJSON(&msg); err != nil {
+				if c.Stopping() {
+					return
+				}
+				if err == io.EOF {
+					c.FailPending(browser.ErrConnectionClosed)
+					c.Closed(nil)
+					return
+				}
+				if c.Gone() {
+					return
+				}
+				c.Logger().Error("failed to receive response", "error", err)
+				continue
+			}
+
+			if msg.Type == "event" {
+				c.Trace("event", msg.Method, msg.Params)
+				c.Emit(browser.Event{Method: msg.Method, Params: msg.Params})
+				continue
+			}
+
+			var v interface{} = browser.Result{ID: msg.ID, Result: msg.Result}
+			if msg.Type == "error" {
+				v = fmt.Errorf("%s: %s", msg.Error, msg.Message)
+			}
+			c.Deliver(strconv.Itoa(int(msg.ID)), v)
+		}
+	}
+}
+
+// Send calls an arbitrary BiDi command and waits for its raw result.
+func (c *Chromium) Send(method string, params interface{}) (json.RawMessage, error) {
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+
+	c.Lock()
+	if c.Ws == nil {
+		c.Unlock()
+		return nil, fmt.Errorf("WebSocket connection is not established")
+	}
+	id := c.NextID()
+	idStr := strconv.Itoa(int(id))
+	responseChan := make(chan interface{}, 1)
+	c.Pending[idStr] = responseChan
+	ws := c.Ws
+	c.Unlock()
+
+	c.Logger().Debug("sending message", "id", id, "method", method)
+	c.Trace("send", method, params)
+	// Writes need no lock, so other commands can be sent while this one
+	// waits for its response.
+	err := ws.WriteJSON(map[string]interface{}{
+		"id":     id,
+		"method": method,
+		"params": params,
+	})
+	if err != nil {
+		c.Lock()
+		delete(c.Pending, idStr)
+		c.Unlock()
+		return nil, fmt.Errorf("failed to send WebSocket message: %w", err)
+	}
+
+	res, err := c.Await(idStr, responseChan)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", method, err)
+	}
+	c.Trace("recv", method, res.Result)
+	return res.Result, nil
+}
+
+// FindDriver locates chromedriver, honouring the MAJORCA_CHROMEDRIVER
+// environment variable.
+func FindDriver() (string, error) {
+	if path := os.Getenv("MAJORCA_CHROMEDRIVER"); path != "" {
+		return path, nil
+	}
+	path, err := exec.LookPath("chromedriver")
+	if err != nil {
+		return "", fmt.Errorf("could not find chromedriver: %w", err)
+	}
+	return path, nil
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free port: %w", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// waitForPort checks if a local TCP port is open within a timeout period.
+func waitForPort(port int, timeout time.Duration) bool {
+	address := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		conn, err := net.DialTimeout("tcp", address, 500*time.Millisecond)
+		if err == nil {
+			conn.Close()
+			return true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return false
+}
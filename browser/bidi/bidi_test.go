@@ -0,0 +1,104 @@
+package bidi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/grngxd/majorca/browser/bidi"
+)
+
+// serveBiDi runs a fake BiDi endpoint that answers script.evaluate with the
+// remote value listed for the expression and other commands with an empty
+// result.
+func serveBiDi(t *testing.T, values map[string]interface{}) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{InsecureSkipVerify: true})
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		ctx := context.Background()
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			var cmd struct {
+				ID     int32  `json:"id"`
+				Method string `json:"method"`
+				Params struct {
+					Expression string `json:"expression"`
+				} `json:"params"`
+			}
+			json.Unmarshal(data, &cmd)
+			var result interface{} = map[string]interface{}{}
+			switch cmd.Method {
+			case "browsingContext.getTree":
+				result = map[string]interface{}{"contexts": []map[string]string{{"context": "top"}}}
+			case "script.evaluate":
+				if v, ok := values[cmd.Params.Expression]; ok {
+					result = v
+				}
+			}
+			msg, _ := json.Marshal(map[string]interface{}{"type": "success", "id": cmd.ID, "result": result})
+			conn.Write(ctx, websocket.MessageText, msg)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func success(typ string, value interface{}) map[string]interface{} {
+	result := map[string]interface{}{"type": typ}
+	if value != nil {
+		result["value"] = value
+	}
+	return map[string]interface{}{"type": "success", "result": result}
+}
+
+func TestEvalRemoteValues(t *testing.T) {
+	url := serveBiDi(t, map[string]interface{}{
+		"text":      success("string", "hi"),
+		"number":    success("number", 2.5),
+		"nan":       success("number", "NaN"),
+		"bigint":    success("bigint", "9007199254740993"),
+		"bool":      success("boolean", true),
+		"undefined": success("undefined", nil),
+		"null":      success("null", nil),
+		"object":    success("object", []interface{}{}),
+		"throws": map[string]interface{}{
+			"type":             "exception",
+			"exceptionDetails": map[string]string{"text": "ReferenceError: x is not defined"},
+		},
+	})
+	c, err := bidi.Attach(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Kill()
+
+	for _, tc := range []struct{ expr, value, typ string }{
+		{"text", "hi", "string"},
+		{"number", "2.5", "number"},
+		{"nan", "NaN", "number"},
+		{"bigint", "9007199254740993", "bigint"},
+		{"bool", "true", "boolean"},
+		{"undefined", "undefined", "undefined"},
+		{"null", "null", "null"},
+		{"object", "[object]", "object"},
+	} {
+		value, typ, err := c.Eval(tc.expr)
+		if err != nil || value != tc.value || typ != tc.typ {
+			t.Errorf("Eval(%s) = %q, %q, %v; want %q, %q", tc.expr, value, typ, err, tc.value, tc.typ)
+		}
+	}
+	if _, _, err := c.Eval("throws"); err == nil || !strings.Contains(err.Error(), "ReferenceError") {
+		t.Errorf("Eval of a throwing expression: err = %v", err)
+	}
+}
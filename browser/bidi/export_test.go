@@ -0,0 +1,30 @@
+package bidi
+
+import "github.com/grngxd/majorca/browser"
+
+// Internals exposed to the external tests.
+
+// Attach connects to the BiDi endpoint at url without launching
+// chromedriver.
+func Attach(url string) (*Chromium, error) {
+	c := &Chromium{
+		BaseBrowser: browser.BaseBrowser{
+			Pending:  make(map[string]chan interface{}),
+			Bindings: make(map[string]browser.BindingFunc),
+			Timeout:  browser.DefaultCommandTimeout,
+			Stop:     make(chan struct{}),
+		},
+	}
+	ws, err := c.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	c.Ws = ws
+	c.Wg.Add(1)
+	go c.handleResponse()
+	if err := c.setup(); err != nil {
+		c.Kill()
+		return nil, err
+	}
+	return c, nil
+}
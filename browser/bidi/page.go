@@ -0,0 +1,185 @@
+package bidi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// readiness maps WaitUntil values to browsingContext.navigate's wait
+// argument. BiDi has no network-idle state, so it waits for load instead.
+var readiness = map[browser.WaitUntil]string{
+	"":                           "none",
+	browser.WaitDOMContentLoaded: "interactive",
+	browser.WaitLoad:             "complete",
+	browser.WaitNetworkIdle:      "complete",
+}
+
+// Load navigates to url, waiting as configured with WithWaitUntil.
+func (c *Chromium) Load(url string) error {
+	wait, ok := readiness[c.waitUntil]
+	if !ok {
+		return fmt.Errorf("unknown wait condition %q", c.waitUntil)
+	}
+	_, err := c.Send("browsingContext.navigate", map[string]interface{}{
+		"context": c.context,
+		"url":     url,
+		"wait":    wait,
+	})
+	return err
+}
+
+// remoteValue is a serialized BiDi script value.
+type remoteValue struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// String renders primitives like Chrome's Eval does and other values by
+// their type.
+func (v remoteValue) String() string {
+	switch v.Type {
+	case "string":
+		var s string
+		json.Unmarshal(v.Value, &s)
+		return s
+	case "number", "boolean", "bigint":
+		var s string
+		if json.Unmarshal(v.Value, &s) == nil {
+			return s // NaN, Infinity and bigints are sent as strings
+		}
+		return string(v.Value)
+	case "undefined", "null":
+		return v.Type
+	}
+	return "[" + v.Type + "]"
+}
+
+// Eval evaluates a JavaScript expression in the page, awaiting promises.
+func (c *Chromium) Eval(expr string) (string, string, error) {
+	raw, err := c.Send("script.evaluate", map[string]interface{}{
+		"expression":   expr,
+		"target":       map[string]interface{}{"context": c.context},
+		"awaitPromise": true,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	var res struct {
+		Type             string      `json:"type"`
+		Result           remoteValue `json:"result"`
+		ExceptionDetails struct {
+			Text string `json:"text"`
+		} `json:"exceptionDetails"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return "", "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if res.Type == "exception" {
+		return "", "", fmt.Errorf("javascript error: %s", res.ExceptionDetails.Text)
+	}
+	return res.Result.String(), res.Result.Type, nil
+}
+
+// bindingScript installs a promise-returning window[name] that forwards its
+// calls to Go over a BiDi channel.
+const bindingScript = `(channel) => {
+	const calls = new Map();
+	let seq = 0;
+	const fn = (...args) => new Promise((resolve, reject) => {
+		const id = ++seq;
+		calls.set(id, {resolve, reject});
+		channel(JSON.stringify({seq: id, args}));
+	});
+	fn.majorca = calls;
+	window[%s] = fn;
+}`
+
+// Bind exposes f to the page as a promise-returning window[name], in the
+// current document and every one loaded later.
+func (c *Chromium) Bind(name string, f browser.BindingFunc) error {
+	if err := c.BaseBrowser.Bind(name, f); err != nil {
+		return err
+	}
+	quoted, _ := json.Marshal(name)
+	fn := fmt.Sprintf(bindingScript, quoted)
+	channel := []map[string]interface{}{{
+		"type":  "channel",
+		"value": map[string]interface{}{"channel": "majorca." + name},
+	}}
+
+	if _, err := c.Send("script.addPreloadScript", map[string]interface{}{
+		"functionDeclaration": fn,
+		"arguments":           channel,
+	}); err != nil {
+		return err
+	}
+	_, err := c.Send("script.callFunction", map[string]interface{}{
+		"functionDeclaration": fn,
+		"arguments":           channel,
+		"target":              map[string]interface{}{"context": c.context},
+		"awaitPromise":        false,
+	})
+	return err
+}
+
+func (c *Chromium) onMessage(e browser.Event) {
+	var p struct {
+		Channel string      `json:"channel"`
+		Data    remoteValue `json:"data"`
+		Source  struct {
+			Realm string `json:"realm"`
+		} `json:"source"`
+	}
+	if json.Unmarshal(e.Params, &p) != nil || len(p.Channel) <= len("majorca.") {
+		return
+	}
+	name := p.Channel[len("majorca."):]
+	var call struct {
+		Seq  int               `json:"seq"`
+		Args []json.RawMessage `json:"args"`
+	}
+	if err := json.Unmarshal([]byte(p.Data.String()), &call); err != nil {
+		c.Logger().Error("failed to decode binding call", "error", err)
+		return
+	}
+
+	c.Lock()
+	f, ok := c.Bindings[name]
+	c.Unlock()
+	if !ok {
+		return
+	}
+
+	// Bindings may take a while, and answering waits for a response.
+	go func() {
+		result, err := f(call.Args)
+
+		settle, value := "resolve", "null"
+		if err != nil {
+			msg, _ := json.Marshal(err.Error())
+			settle, value = "reject", fmt.Sprintf("new Error(%s)", msg)
+		} else if data, merr := json.Marshal(result); merr != nil {
+			msg, _ := json.Marshal(merr.Error())
+			settle, value = "reject", fmt.Sprintf("new Error(%s)", msg)
+		} else {
+			value = string(data)
+		}
+
+		quoted, _ := json.Marshal(name)
+		expr := fmt.Sprintf(`(() => {
+			const calls = window[%s].majorca;
+			const call = calls.get(%d);
+			calls.delete(%d);
+			call.%s(%s);
+		})()`, quoted, call.Seq, call.Seq, settle, value)
+		if _, err := c.Send("script.evaluate", map[string]interface{}{
+			"expression":   expr,
+			"target":       map[string]interface{}{"realm": p.Source.Realm},
+			"awaitPromise": false,
+		}); err != nil {
+			c.Logger().Error("failed to return binding result", "name", name, "error", err)
+		}
+	}()
+}
@@ -3,11 +3,12 @@ package browser
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"sync"
-
-	"golang.org/x/net/websocket"
+	"sync/atomic"
+	"time"
 )
 
 type Browser interface {
@@ -16,12 +17,28 @@ type Browser interface {
 	Eval(expr string) (string, string, error)
 	Bind(name string, f BindingFunc) error
 	Load(url string) error
+	Done() <-chan struct{}
+	Wait() error
+	// BrowserVersion returns the browser's version number, e.g.
+	// "120.0.6099.109".
+	BrowserVersion() (string, error)
+	// ProtocolVersion returns the version of the automation protocol the
+	// backend speaks to the browser, e.g. "1.3" for DevTools.
+	ProtocolVersion() (string, error)
+}
+
+// Sender is implemented by backends that accept raw protocol commands, such
+// as Chrome's DevTools Protocol.
+type Sender interface {
+	Send(method string, params interface{}) (json.RawMessage, error)
 }
 
 type BindingFunc func(args []json.RawMessage) (interface{}, error)
 
 type Result struct {
 	ID     int32           `json:"id"`
+	Method string          `json:"method"` // Set for events, which carry no id
+	Params json.RawMessage `json:"params"`
 	Result json.RawMessage `json:"result"`
 	Error  *struct {
 		Code    int    `json:"code"`
@@ -31,14 +48,38 @@ type Result struct {
 
 type BaseBrowser struct {
 	sync.Mutex
-	Path     string
-	Cmd      *exec.Cmd
-	Ws       *websocket.Conn // Changed from *websocket.Conn (gorilla) to *websocket.Conn (golang/x/net)
-	Id       int32
-	Pending  map[string]chan interface{}
-	Bindings map[string]BindingFunc
-	Done     chan struct{}  // Channel to signal goroutine to stop
-	Wg       sync.WaitGroup // WaitGroup to wait for goroutines to finish
+	Path    string
+	Log     *slog.Logger  // Nil means silent, see Logger()
+	Timeout time.Duration // Per-command timeout, zero waits forever
+
+	TraceEnabled bool       // Log full protocol messages, see Trace
+	Redact       *Redaction // Masks sensitive values in traces
+	Cmd          *exec.Cmd
+	Ws           Conn
+	ReadLimit    int64 // Largest accepted protocol message, see Dial
+	Compression  bool  // Negotiate WebSocket compression, see Dial
+	Id           int32 // Last command id handed out, see NextID
+	Pending      map[string]chan interface{}
+	Bindings     map[string]BindingFunc
+	Stop         chan struct{}  // Channel to signal goroutine to stop
+	Wg           sync.WaitGroup // WaitGroup to wait for goroutines to finish
+
+	CrashDir string // Receives minidumps of crashes, see WithCrashReports
+	DumpDir  string // Extra place the browser may write minidumps to
+
+	exitOnce  sync.Once
+	closeOnce sync.Once
+	exited    chan struct{} // Closed when the process exits or the window is closed
+	exitErr   error
+	handlers  handlers // OnExit and OnCrash callbacks
+
+	stderr  *tailWriter
+	tree    processTree // The browser and its helper processes, see Kill
+	crashMu sync.Mutex
+	crash   *CrashReport
+
+	events events
+	tempID string // Registry entry of the temp profile, see TrackTemp
 }
 
 func (b *BaseBrowser) Start() error {
@@ -46,41 +87,70 @@ func (b *BaseBrowser) Start() error {
 	defer b.Unlock()
 
 	if b.Cmd.Process != nil {
-		fmt.Println("Browser process already started")
+		b.Logger().Debug("browser process already started")
 		return nil
 	}
 
+	b.captureStderr()
+	prepareTree(b.Cmd)
+	started := time.Now()
 	if err := b.Cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start browser: %w", err)
 	}
+	tree, err := attachTree(b.Cmd)
+	if err != nil {
+		b.Logger().Warn("helper processes may outlive the browser", "error", err)
+	}
+	b.tree = tree
 
-	fmt.Println("Browser started successfully")
+	go func() {
+		err := b.Cmd.Wait()
+		// Helpers left behind by a crashed browser go with it.
+		b.Lock()
+		b.tree.kill()
+		b.Unlock()
+		if report := b.recordCrash(err, started); report != nil {
+			b.Crashed(report)
+		}
+		b.FailPending(ErrBrowserGone)
+		b.Closed(err)
+	}()
+
+	b.Logger().Info("browser started", "path", b.Path, "pid", b.Cmd.Process.Pid)
 	return nil
 }
 
 func (b *BaseBrowser) Kill() error {
 	b.Lock()
-	defer b.Unlock()
-
 	// Signal handleResponse to stop
 	select {
-	case <-b.Done:
-		// done channel already closed
+	case <-b.Stop:
+		// stop channel already closed
 	default:
-		close(b.Done)
+		close(b.Stop)
 	}
+	ws := b.Ws
+	b.Unlock()
 
-	if b.Ws != nil {
+	if ws != nil {
 		// Close WebSocket connection if applicable
-		if err := b.Ws.Close(); err != nil {
-			fmt.Printf("Error closing WebSocket: %v\n", err)
+		if err := ws.Close(); err != nil {
+			b.Logger().Warn("failed to close WebSocket", "error", err)
 		}
 	}
 
-	// Wait for handleResponse goroutine to finish
+	// Wait for handleResponse goroutine to finish. The lock must not be
+	// held here: the reader takes it to hand out responses.
 	b.Wg.Wait()
 
-	if b.Cmd.Process != nil {
+	b.Lock()
+	defer b.Unlock()
+	if b.Cmd != nil && b.Cmd.Process != nil {
+		// Killing the launcher alone leaves renderer and GPU processes
+		// behind, so take down the whole tree first.
+		if err := b.tree.kill(); err != nil {
+			b.Logger().Warn("failed to kill browser process tree", "error", err)
+		}
 		if err := b.Cmd.Process.Kill(); err != nil {
 			// On Windows, TerminateProcess can fail if the process is already terminated.
 			// Therefore, check if the process is still running before returning an error.
@@ -94,14 +164,177 @@ func (b *BaseBrowser) Kill() error {
 	return nil
 }
 
+// NextID returns a fresh command id. It is safe for concurrent use; ids
+// start at 1.
+func (b *BaseBrowser) NextID() int32 {
+	return atomic.AddInt32(&b.Id, 1)
+}
+
+// Await waits for the response registered under id in Pending. Response
+// channels must be buffered so that the reader never blocks on a caller that
+// already gave up. On timeout the pending entry is removed and ErrTimeout is
+// returned.
+func (b *BaseBrowser) Await(id string, ch chan interface{}) (Result, error) {
+	var timeout <-chan time.Time
+	if b.Timeout > 0 {
+		timer := time.NewTimer(b.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var v interface{}
+	select {
+	case v = <-ch:
+	case <-timeout:
+		b.Lock()
+		delete(b.Pending, id)
+		b.Unlock()
+		return Result{}, ErrTimeout
+	}
+
+	switch res := v.(type) {
+	case Result:
+		return res, nil
+	case error:
+		return Result{}, res
+	}
+	return Result{}, fmt.Errorf("unexpected response type")
+}
+
+// FailPending fails every command still waiting for a response with err.
+func (b *BaseBrowser) FailPending(err error) {
+	b.Lock()
+	defer b.Unlock()
+	for id, ch := range b.Pending {
+		select {
+		case ch <- err:
+		default:
+		}
+		delete(b.Pending, id)
+	}
+}
+
+// Deliver hands a response or error to the command registered under id and
+// reports whether one was waiting. Responses may arrive in any order; each
+// command has its own buffered channel, so delivery never blocks the reader
+// and never happens under the browser lock.
+func (b *BaseBrowser) Deliver(id string, v interface{}) bool {
+	b.Lock()
+	ch, ok := b.Pending[id]
+	delete(b.Pending, id)
+	b.Unlock()
+	if !ok {
+		return false
+	}
+	select {
+	case ch <- v:
+	default:
+	}
+	return true
+}
+
+// Done returns a channel that is closed once the browser process exits or
+// the user closes the app window.
+func (b *BaseBrowser) Done() <-chan struct{} {
+	return b.exitChan()
+}
+
+// Wait blocks until Done is closed and returns the process exit error, if any.
+func (b *BaseBrowser) Wait() error {
+	<-b.exitChan()
+	return b.exitErr
+}
+
+// Closed marks the browser as gone and runs the OnExit handlers. It is
+// called when the process exits and by backends when the browser closes the
+// page connection.
+func (b *BaseBrowser) Closed(err error) {
+	ch := b.exitChan()
+	first := false
+	b.closeOnce.Do(func() {
+		b.exitErr = err
+		close(ch)
+		first = true
+	})
+	if first {
+		for _, h := range b.handlers.exitHandlers() {
+			go h(err)
+		}
+	}
+}
+
+// OnExit registers handler to run once the browser is done, with the same
+// error Wait returns. Handlers run on their own goroutine, so they may call
+// Kill or start a new browser. The returned function removes the handler.
+func (b *BaseBrowser) OnExit(handler func(err error)) func() {
+	h := &b.handlers
+	f := &handler
+	h.mu.Lock()
+	h.exit = append(h.exit, f)
+	h.mu.Unlock()
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		for i, g := range h.exit {
+			if g == f {
+				h.exit = append(h.exit[:i:i], h.exit[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Gone reports whether the browser process has exited, failing the
+// commands still in flight if so. Read loops call it on receive errors so
+// they stop instead of spinning on a dead connection.
+func (b *BaseBrowser) Gone() bool {
+	select {
+	case <-b.exitChan():
+		b.FailPending(ErrBrowserGone)
+		return true
+	default:
+		return false
+	}
+}
+
+// Stopping reports whether Kill was called. Read loops check it after a
+// read error and return without touching the browser state, which Kill is
+// tearing down.
+func (b *BaseBrowser) Stopping() bool {
+	select {
+	case <-b.Stop:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *BaseBrowser) exitChan() chan struct{} {
+	b.exitOnce.Do(func() {
+		b.exited = make(chan struct{})
+	})
+	return b.exited
+}
+
 func (b *BaseBrowser) Eval(expr string) (string, string, error) {
 	// This method should be implemented by specific browsers
-	return "", "", fmt.Errorf("Eval not implemented")
+	return "", "", fmt.Errorf("Eval %w", ErrNotImplemented)
 }
 
 func (b *BaseBrowser) Load(url string) error {
 	// This method should be implemented by specific browsers
-	return fmt.Errorf("Load not implemented")
+	return fmt.Errorf("Load %w", ErrNotImplemented)
+}
+
+func (b *BaseBrowser) BrowserVersion() (string, error) {
+	// This method should be implemented by specific browsers
+	return "", fmt.Errorf("BrowserVersion %w", ErrNotImplemented)
+}
+
+func (b *BaseBrowser) ProtocolVersion() (string, error) {
+	// This method should be implemented by specific browsers
+	return "", fmt.Errorf("ProtocolVersion %w", ErrNotImplemented)
 }
 
 func (b *BaseBrowser) Bind(name string, f BindingFunc) error {
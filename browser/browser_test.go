@@ -0,0 +1,225 @@
+package browser_test
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+func TestAwaitTimeout(t *testing.T) {
+	b := &browser.BaseBrowser{
+		Pending: make(map[string]chan interface{}),
+		Timeout: 10 * time.Millisecond,
+	}
+	ch := make(chan interface{}, 1)
+	b.Pending["1"] = ch
+
+	if _, err := b.Await("1", ch); !errors.Is(err, browser.ErrTimeout) {
+		t.Fatalf("Expected ErrTimeout, got %v", err)
+	}
+	if _, ok := b.Pending["1"]; ok {
+		t.Errorf("Pending entry was not removed after timeout")
+	}
+}
+
+func TestFailPending(t *testing.T) {
+	b := &browser.BaseBrowser{Pending: make(map[string]chan interface{})}
+	ch := make(chan interface{}, 1)
+	b.Pending["1"] = ch
+
+	b.FailPending(browser.ErrConnectionClosed)
+	if _, err := b.Await("1", ch); !errors.Is(err, browser.ErrConnectionClosed) {
+		t.Errorf("Expected ErrConnectionClosed, got %v", err)
+	}
+	if len(b.Pending) != 0 {
+		t.Errorf("Pending map was not cleared")
+	}
+}
+
+func TestNextID(t *testing.T) {
+	var b browser.BaseBrowser
+	var mu sync.Mutex
+	seen := make(map[int32]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				id := b.NextID()
+				mu.Lock()
+				if seen[id] {
+					t.Errorf("id %d handed out twice", id)
+				}
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if !seen[1] || !seen[800] {
+		t.Errorf("ids do not cover 1..800")
+	}
+}
+
+func TestDeliverOutOfOrder(t *testing.T) {
+	b := &browser.BaseBrowser{Pending: make(map[string]chan interface{})}
+	ids := []string{"1", "2", "3"}
+	chans := make(map[string]chan interface{})
+	for _, id := range ids {
+		chans[id] = make(chan interface{}, 1)
+		b.Pending[id] = chans[id]
+	}
+
+	got := make(chan error, len(ids))
+	for _, id := range ids {
+		id := id
+		go func() {
+			res, err := b.Await(id, chans[id])
+			if err == nil && fmt.Sprint(res.ID) != id {
+				err = fmt.Errorf("command %s received response %d", id, res.ID)
+			}
+			got <- err
+		}()
+	}
+
+	for _, id := range []int32{3, 1, 2} {
+		if !b.Deliver(fmt.Sprint(id), browser.Result{ID: id}) {
+			t.Fatalf("Deliver(%d) found no pending command", id)
+		}
+	}
+	for range ids {
+		select {
+		case err := <-got:
+			if err != nil {
+				t.Error(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Response was not delivered")
+		}
+	}
+	if b.Deliver("1", browser.Result{ID: 1}) {
+		t.Errorf("Duplicate response was delivered")
+	}
+}
+
+func TestOn(t *testing.T) {
+	b := &browser.BaseBrowser{Stop: make(chan struct{})}
+	defer close(b.Stop)
+
+	got := make(chan string, 2)
+	off := b.On("Page.loadEventFired", func(e browser.Event) { got <- e.Method })
+	b.On("*", func(e browser.Event) { got <- "*" + e.Method })
+
+	b.Emit(browser.Event{Method: "Page.loadEventFired"})
+	for i := 0; i < 2; i++ {
+		select {
+		case <-got:
+		case <-time.After(time.Second):
+			t.Fatalf("Event was not delivered to both handlers")
+		}
+	}
+
+	off()
+	b.Emit(browser.Event{Method: "Page.loadEventFired"})
+	select {
+	case m := <-got:
+		if m != "*Page.loadEventFired" {
+			t.Errorf("Unsubscribed handler received %s", m)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Wildcard handler did not receive event")
+	}
+}
+
+func TestOnReplay(t *testing.T) {
+	b := &browser.BaseBrowser{Stop: make(chan struct{})}
+	defer close(b.Stop)
+
+	seen := make(chan struct{}, 2)
+	b.On("*", func(browser.Event) { seen <- struct{}{} })
+	b.Emit(browser.Event{Method: "Runtime.consoleAPICalled"})
+	b.Emit(browser.Event{Method: "Network.dataReceived"})
+	for i := 0; i < 2; i++ {
+		select {
+		case <-seen:
+		case <-time.After(time.Second):
+			t.Fatalf("Events were not dispatched")
+		}
+	}
+
+	got := make(chan string, 2)
+	b.OnReplay("*", func(e browser.Event) { got <- e.Method })
+	select {
+	case m := <-got:
+		if m != "Runtime.consoleAPICalled" {
+			t.Errorf("Replayed %s, want Runtime.consoleAPICalled", m)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Buffered event was not replayed")
+	}
+
+	b.Emit(browser.Event{Method: "Page.loadEventFired"})
+	select {
+	case m := <-got:
+		if m != "Page.loadEventFired" {
+			t.Errorf("Received %s after replay, want Page.loadEventFired", m)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Live event was not delivered after replay")
+	}
+}
+
+func TestLastCrashReport(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no shell available")
+	}
+	b := &browser.BaseBrowser{Stop: make(chan struct{})}
+	b.Cmd = exec.Command(sh, "-c", "echo boom >&2; exit 3")
+	if err := b.Start(); err != nil {
+		t.Fatal(err)
+	}
+	b.Wait()
+
+	report := b.LastCrashReport()
+	if report == nil {
+		t.Fatal("Expected a crash report")
+	}
+	if report.ExitCode != 3 || report.Stderr != "boom\n" {
+		t.Errorf("Got exit code %d and stderr %q", report.ExitCode, report.Stderr)
+	}
+}
+
+func TestCrashFailsPendingAndNotifies(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no shell available")
+	}
+	b := &browser.BaseBrowser{Stop: make(chan struct{}), Pending: make(map[string]chan interface{})}
+	b.Cmd = exec.Command(sh, "-c", "sleep 0.1; exit 3")
+	crashed := make(chan *browser.CrashReport, 1)
+	exited := make(chan error, 1)
+	b.OnCrash(func(r *browser.CrashReport) { crashed <- r })
+	b.OnExit(func(err error) { exited <- err })
+
+	ch := make(chan interface{}, 1)
+	b.Pending["1"] = ch
+	if err := b.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Await("1", ch); !errors.Is(err, browser.ErrBrowserGone) {
+		t.Errorf("Expected ErrBrowserGone, got %v", err)
+	}
+	if r := <-crashed; r.ExitCode != 3 {
+		t.Errorf("Got exit code %d", r.ExitCode)
+	}
+	if err := <-exited; err == nil {
+		t.Error("Expected the exit error")
+	}
+}
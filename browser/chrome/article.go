@@ -0,0 +1,143 @@
+package chrome
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrNoArticle is returned by ExtractArticle for pages without a readable
+// body of text, such as app shells or image galleries.
+var ErrNoArticle = errors.New("page has no readable article")
+
+// Article is the main content of a page as found by ExtractArticle.
+type Article struct {
+	Title    string `json:"title"`
+	Byline   string `json:"byline"`
+	SiteName string `json:"siteName"`
+	Excerpt  string `json:"excerpt"` // From the description meta tags
+	Lang     string `json:"lang"`
+	Text     string `json:"text"` // Paragraphs separated by blank lines
+	HTML     string `json:"html"` // The cleaned content element
+}
+
+// readerWorld names the isolated world ExtractArticle runs in.
+const readerWorld = "majorca-reader"
+
+// readabilityScript finds the element holding most of the page's prose,
+// scoring paragraphs like Readability does: text length and commas count for
+// the parent, half as much for the grandparent, and link-heavy blocks are
+// penalized. It works on a clone, so the page is left untouched.
+const readabilityScript = `(() => {
+	const meta = (...names) => {
+		for (const name of names) {
+			const el = document.querySelector('meta[name="' + name + '"], meta[property="' + name + '"]');
+			if (el && el.content.trim()) return el.content.trim();
+		}
+		return "";
+	};
+	const text = (el) => (el ? el.textContent.replace(/\s+/g, " ").trim() : "");
+	const linkDensity = (el) => {
+		const total = text(el).length || 1;
+		let links = 0;
+		for (const a of el.querySelectorAll("a")) links += text(a).length;
+		return links / total;
+	};
+
+	const scores = new Map();
+	const add = (el, score) => { if (el && el !== document.documentElement) scores.set(el, (scores.get(el) || 0) + score); };
+	for (const p of document.body.querySelectorAll("p, pre, td, blockquote")) {
+		const t = text(p);
+		if (t.length < 25) continue;
+		const score = 1 + t.split(",").length + Math.min(Math.floor(t.length / 100), 3);
+		add(p.parentElement, score);
+		add(p.parentElement && p.parentElement.parentElement, score / 2);
+	}
+	let top = null, best = 0;
+	for (const [el, score] of scores) {
+		const s = score * (1 - linkDensity(el));
+		if (s > best) { top = el; best = s; }
+	}
+	// Prefer the semantic container around the winner unless it mostly
+	// holds other things.
+	const container = top ? top.closest("article, [role=main], main") : document.querySelector("article, [role=main], main");
+	if (container && (!top || text(container).length < text(top).length * 3)) top = container;
+	if (!top) return null;
+
+	const content = top.cloneNode(true);
+	const junk = "script, style, noscript, iframe, form, nav, aside, footer, button, svg, " +
+		"[aria-hidden=true], [class*=share], [class*=comment], [class*=related], [class*=promo], [id*=comment]";
+	for (const el of content.querySelectorAll(junk)) el.remove();
+	for (const el of content.querySelectorAll("*")) {
+		if (el.matches("div, section, ul") && text(el).length > 0 && linkDensity(el) > 0.5) el.remove();
+	}
+
+	const blocks = "p, h1, h2, h3, h4, h5, h6, li, pre, blockquote, figcaption";
+	const paragraphs = [...content.querySelectorAll(blocks)]
+		.filter((el) => !el.parentElement || !el.parentElement.closest(blocks))
+		.map(text)
+		.filter(Boolean);
+	const body = paragraphs.length ? paragraphs.join("\n\n") : text(content);
+	if (!body) return null;
+
+	const byline = meta("author", "article:author") ||
+		text(document.querySelector('[rel=author], [itemprop=author], .byline, .author'));
+	return {
+		title: meta("og:title", "twitter:title") || document.title.trim() || text(document.querySelector("h1")),
+		byline: byline,
+		siteName: meta("og:site_name", "application-name"),
+		excerpt: meta("description", "og:description", "twitter:description"),
+		lang: document.documentElement.lang || "",
+		text: body,
+		html: content.innerHTML,
+	};
+})()`
+
+// ExtractArticle returns the readable main content of the page, reader-mode
+// style: title, byline and the prose without navigation, ads or comments.
+// It runs in an isolated world, so page scripts can neither see nor tamper
+// with it, and it does not modify the page.
+func (c *Chrome) ExtractArticle() (*Article, error) {
+	c.Lock()
+	frame := c.mainFrame
+	c.Unlock()
+
+	raw, err := c.Send("Page.createIsolatedWorld", map[string]interface{}{
+		"frameId":   frame,
+		"worldName": readerWorld,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var world struct {
+		ExecutionContextID int `json:"executionContextId"`
+	}
+	if err := json.Unmarshal(raw, &world); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal isolated world: %w", err)
+	}
+
+	raw, err = c.Send("Runtime.evaluate", map[string]interface{}{
+		"expression":    readabilityScript,
+		"contextId":     world.ExecutionContextID,
+		"returnByValue": true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		Result struct {
+			Value *Article `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *exceptionDetails `json:"exceptionDetails"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if res.ExceptionDetails != nil {
+		return nil, res.ExceptionDetails.jsError()
+	}
+	if res.Result.Value == nil {
+		return nil, ErrNoArticle
+	}
+	return res.Result.Value, nil
+}
@@ -0,0 +1,46 @@
+package chrome
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// Attach connects to a Chrome that is already running with remote debugging
+// enabled instead of launching one. endpoint is either a page's WebSocket
+// debugger URL, e.g. "ws://localhost:9222/devtools/page/<id>", or the
+// DevTools HTTP address, e.g. "http://localhost:9222", in which case the
+// first page is used. Kill only drops the connection; the browser keeps
+// running.
+func Attach(endpoint string, opts ...browser.Option) (*Chrome, error) {
+	o := browser.NewOptions(opts...)
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid DevTools endpoint %q", endpoint)
+	}
+	c := newChrome(o, "")
+	c.attached = true
+	c.debugAddr = u.Host
+
+	wsURL := endpoint
+	switch u.Scheme {
+	case "ws", "wss":
+	case "http", "https":
+		if wsURL, err = c.pageTarget(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid DevTools endpoint %q", endpoint)
+	}
+	if err := c.dialPage(wsURL); err != nil {
+		return nil, err
+	}
+
+	if err := c.setup(o); err != nil {
+		c.Kill()
+		return nil, err
+	}
+	return c, nil
+}
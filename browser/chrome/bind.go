@@ -0,0 +1,105 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// bindingWrapper replaces the raw binding Chrome installs with a function
+// that returns a promise, settled once Go has handled the call.
+const bindingWrapper = `(() => {
+	const name = %s;
+	const binding = window[name];
+	if (!binding || binding.majorca) return;
+	const calls = new Map();
+	let seq = 0;
+	const wrapper = (...args) => new Promise((resolve, reject) => {
+		seq++;
+		calls.set(seq, {resolve, reject});
+		binding(JSON.stringify({name, seq, args}));
+	});
+	wrapper.majorca = calls;
+	window[name] = wrapper;
+})()`
+
+// Bind exposes f to the page as an async JavaScript function called name.
+// The binding is available on every document loaded after the call.
+func (c *Chrome) Bind(name string, f browser.BindingFunc) error {
+	if err := c.BaseBrowser.Bind(name, f); err != nil {
+		return err
+	}
+
+	c.bindOnce.Do(func() {
+		c.On("Runtime.bindingCalled", c.onBindingCalled)
+	})
+	return c.installBinding(name)
+}
+
+// installBinding registers name with Chrome on the current connection.
+func (c *Chrome) installBinding(name string) error {
+	if _, err := c.Send("Runtime.addBinding", map[string]interface{}{"name": name}); err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf(bindingWrapper, quote(name))
+	if _, err := c.Send("Page.addScriptToEvaluateOnNewDocument", map[string]interface{}{"source": script}); err != nil {
+		return err
+	}
+	_, err := c.Send("Runtime.evaluate", map[string]interface{}{"expression": script})
+	return err
+}
+
+func (c *Chrome) onBindingCalled(e browser.Event) {
+	var p struct {
+		Payload            string `json:"payload"`
+		ExecutionContextID int    `json:"executionContextId"`
+	}
+	if err := json.Unmarshal(e.Params, &p); err != nil {
+		return
+	}
+	var call struct {
+		Name string            `json:"name"`
+		Seq  int               `json:"seq"`
+		Args []json.RawMessage `json:"args"`
+	}
+	if err := json.Unmarshal([]byte(p.Payload), &call); err != nil {
+		c.Logger().Error("failed to decode binding call", "error", err)
+		return
+	}
+
+	c.Lock()
+	f, ok := c.Bindings[call.Name]
+	c.Unlock()
+	if !ok {
+		return
+	}
+
+	// Bindings may take a while, so don't hold up other events.
+	go func() {
+		result, err := f(call.Args)
+
+		settle, value := "resolve", "null"
+		if err != nil {
+			settle, value = "reject", fmt.Sprintf("new Error(%s)", quote(err.Error()))
+		} else if data, merr := json.Marshal(result); merr != nil {
+			settle, value = "reject", fmt.Sprintf("new Error(%s)", quote(merr.Error()))
+		} else {
+			value = string(data)
+		}
+
+		expr := fmt.Sprintf(`(() => {
+			const calls = window[%s].majorca;
+			const call = calls.get(%d);
+			calls.delete(%d);
+			call.%s(%s);
+		})()`, quote(call.Name), call.Seq, call.Seq, settle, value)
+		if _, err := c.Send("Runtime.evaluate", map[string]interface{}{
+			"expression": expr,
+			"contextId":  p.ExecutionContextID,
+		}); err != nil {
+			c.Logger().Error("failed to return binding result", "name", call.Name, "error", err)
+		}
+	}()
+}
@@ -0,0 +1,158 @@
+package chrome
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// captureData runs a capture command and decodes its base64 data field.
+// Captures are large, so they go on the background lane.
+func (c *Chrome) captureData(method string, params map[string]interface{}) ([]byte, error) {
+	raw, err := c.SendPriority(PriorityBackground, method, params)
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s result: %w", method, err)
+	}
+	data, err := base64.StdEncoding.DecodeString(res.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return data, nil
+}
+
+// Screenshot captures the visible part of the page. format is "png", "jpeg"
+// or "webp"; quality (0-100) only applies to the lossy formats.
+func (c *Chrome) Screenshot(format string, quality int) ([]byte, error) {
+	if format == "" {
+		format = "png"
+	}
+	params := map[string]interface{}{"format": format}
+	if format != "png" {
+		params["quality"] = quality
+	}
+	return c.captureData("Page.captureScreenshot", params)
+}
+
+// ScreenshotElement captures the first element matching selector as a PNG,
+// including parts of it that are scrolled out of view.
+func (c *Chrome) ScreenshotElement(selector string) ([]byte, error) {
+	var rect *struct {
+		X      float64 `json:"x"`
+		Y      float64 `json:"y"`
+		Width  float64 `json:"width"`
+		Height float64 `json:"height"`
+	}
+	err := c.evalJSON(fmt.Sprintf(`(() => {
+		const el = document.querySelector(%s);
+		if (!el) return null;
+		const r = el.getBoundingClientRect();
+		return {x: r.left + window.scrollX, y: r.top + window.scrollY, width: r.width, height: r.height};
+	})()`, quote(selector)), &rect)
+	if err != nil {
+		return nil, err
+	}
+	if rect == nil {
+		return nil, fmt.Errorf("no element matches %s", selector)
+	}
+	if rect.Width == 0 || rect.Height == 0 {
+		return nil, fmt.Errorf("element %s has no size", selector)
+	}
+
+	return c.captureData("Page.captureScreenshot", map[string]interface{}{
+		"format":                "png",
+		"captureBeyondViewport": true,
+		"clip": map[string]interface{}{
+			"x":      rect.X,
+			"y":      rect.Y,
+			"width":  rect.Width,
+			"height": rect.Height,
+			"scale":  1,
+		},
+	})
+}
+
+// PDFOptions configures PrintToPDF. Sizes are in inches; zero values keep
+// Chrome's defaults (US Letter, scale 1). Margins are pointers so that zero
+// margins can be asked for: nil keeps Chrome's ~0.4in, Margin(0) removes it.
+type PDFOptions struct {
+	Landscape       bool
+	PrintBackground bool
+	Scale           float64
+	PaperWidth      float64
+	PaperHeight     float64
+	MarginTop       *float64
+	MarginBottom    *float64
+	MarginLeft      *float64
+	MarginRight     *float64
+	PageRanges      string // e.g. "1-5, 8"
+	// Header and footer are HTML templates; Chrome fills elements with the
+	// classes date, title, url, pageNumber and totalPages.
+	HeaderTemplate string
+	FooterTemplate string
+	// PreferCSSPageSize lets @page rules in the document override the paper
+	// size above.
+	PreferCSSPageSize bool
+}
+
+// Common paper sizes in inches, for PDFOptions.PaperWidth/PaperHeight.
+const (
+	LetterWidth  = 8.5
+	LetterHeight = 11
+	A4Width      = 8.27
+	A4Height     = 11.69
+)
+
+// Margin returns a PDFOptions margin of inches.
+func Margin(inches float64) *float64 {
+	return &inches
+}
+
+// PrintToPDF renders the page as a PDF document. Chrome only supports this in
+// headless mode.
+func (c *Chrome) PrintToPDF(opts PDFOptions) ([]byte, error) {
+	params := map[string]interface{}{
+		"landscape":         opts.Landscape,
+		"printBackground":   opts.PrintBackground,
+		"preferCSSPageSize": opts.PreferCSSPageSize,
+	}
+	set := func(key string, v float64) {
+		if v != 0 {
+			params[key] = v
+		}
+	}
+	set("scale", opts.Scale)
+	set("paperWidth", opts.PaperWidth)
+	set("paperHeight", opts.PaperHeight)
+	margin := func(key string, v *float64) {
+		if v != nil {
+			params[key] = *v
+		}
+	}
+	margin("marginTop", opts.MarginTop)
+	margin("marginBottom", opts.MarginBottom)
+	margin("marginLeft", opts.MarginLeft)
+	margin("marginRight", opts.MarginRight)
+	if opts.PageRanges != "" {
+		params["pageRanges"] = opts.PageRanges
+	}
+	if opts.HeaderTemplate != "" || opts.FooterTemplate != "" {
+		params["displayHeaderFooter"] = true
+		// An empty template would fall back to Chrome's default one.
+		params["headerTemplate"] = orEmptySpan(opts.HeaderTemplate)
+		params["footerTemplate"] = orEmptySpan(opts.FooterTemplate)
+	}
+	return c.captureData("Page.printToPDF", params)
+}
+
+func orEmptySpan(template string) string {
+	if template == "" {
+		return "<span></span>"
+	}
+	return template
+}
@@ -0,0 +1,41 @@
+package chrome_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/grngxd/majorca/browser/chrome"
+	"github.com/grngxd/majorca/internal/cdptest"
+)
+
+func TestPrintToPDFMargins(t *testing.T) {
+	var params map[string]interface{}
+	d := cdptest.New(t, func(target, method string, raw json.RawMessage) interface{} {
+		if method == "Page.printToPDF" {
+			params = nil
+			json.Unmarshal(raw, &params)
+			return map[string]string{"data": base64.StdEncoding.EncodeToString([]byte("%PDF"))}
+		}
+		return nil
+	})
+	c, err := chrome.Attach(d.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Kill()
+
+	pdf, err := c.PrintToPDF(chrome.PDFOptions{MarginTop: chrome.Margin(0), MarginLeft: chrome.Margin(1)})
+	if err != nil || string(pdf) != "%PDF" {
+		t.Fatalf("PrintToPDF = %q, %v", pdf, err)
+	}
+	if v, ok := params["marginTop"]; !ok || v != 0.0 {
+		t.Errorf("marginTop = %v, %v; want an explicit 0", v, ok)
+	}
+	if v := params["marginLeft"]; v != 1.0 {
+		t.Errorf("marginLeft = %v, want 1", v)
+	}
+	if _, ok := params["marginBottom"]; ok {
+		t.Error("an unset margin was sent instead of keeping Chrome's default")
+	}
+}
@@ -0,0 +1,136 @@
+package chrome
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// Chaos configures fault injection for resilience tests, see EnableChaos.
+// Zero fields disable the corresponding fault.
+type Chaos struct {
+	// Seed makes the random decisions reproducible for the same sequence
+	// of commands and events.
+	Seed int64
+
+	// DelayRate is the probability that a command is held back for up to
+	// MaxDelay before it is sent.
+	DelayRate float64
+	MaxDelay  time.Duration
+
+	// DropRate is the probability that an event is dropped. DropEvents
+	// limits dropping to the named events; empty means any event.
+	DropRate   float64
+	DropEvents []string
+
+	// ReconnectEvery closes the DevTools connection on a schedule, which
+	// forces a reconnect and fails commands in flight.
+	ReconnectEvery time.Duration
+
+	// CrashEvery crashes the page's renderer on a schedule.
+	CrashEvery time.Duration
+}
+
+// EnableChaos injects the faults described by cfg until the returned
+// function is called, so apps can verify that they recover from slow
+// commands, lost events, dropped connections and renderer crashes.
+func (c *Chrome) EnableChaos(cfg Chaos) func() {
+	var mu sync.Mutex
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	chance := func(p float64) bool {
+		if p <= 0 {
+			return false
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return rng.Float64() < p
+	}
+	delay := func() time.Duration {
+		mu.Lock()
+		defer mu.Unlock()
+		return time.Duration(rng.Int63n(int64(cfg.MaxDelay) + 1))
+	}
+
+	stop := make(chan struct{})
+	var removers []func()
+
+	if cfg.DelayRate > 0 && cfg.MaxDelay > 0 {
+		removers = append(removers, c.Use(func(next Handler) Handler {
+			return func(cmd Command) (json.RawMessage, error) {
+				if chance(cfg.DelayRate) {
+					d := delay()
+					c.Logger().Debug("chaos: delaying command", "method", cmd.Method, "delay", d)
+					select {
+					case <-time.After(d):
+					case <-stop:
+					}
+				}
+				return next(cmd)
+			}
+		}))
+	}
+
+	if cfg.DropRate > 0 {
+		only := make(map[string]bool, len(cfg.DropEvents))
+		for _, name := range cfg.DropEvents {
+			only[name] = true
+		}
+		removers = append(removers, c.UseEvents(func(e browser.Event) bool {
+			if len(only) > 0 && !only[e.Method] {
+				return true
+			}
+			if chance(cfg.DropRate) {
+				c.Logger().Debug("chaos: dropping event", "method", e.Method)
+				return false
+			}
+			return true
+		}))
+	}
+
+	every := func(d time.Duration, fault func()) {
+		if d <= 0 {
+			return
+		}
+		go func() {
+			ticker := time.NewTicker(d)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					fault()
+				case <-stop:
+					return
+				case <-c.Done():
+					return
+				}
+			}
+		}()
+	}
+	every(cfg.ReconnectEvery, func() {
+		c.Logger().Debug("chaos: dropping DevTools connection")
+		c.Lock()
+		ws := c.Ws
+		c.Unlock()
+		if ws != nil {
+			ws.Close()
+		}
+	})
+	every(cfg.CrashEvery, func() {
+		c.Logger().Debug("chaos: crashing renderer")
+		// Page.crash never answers, the renderer is gone.
+		c.SendAsync("Page.crash", nil)
+	})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			for _, remove := range removers {
+				remove()
+			}
+		})
+	}
+}
@@ -9,34 +9,97 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/grngxd/majorca/browser"
-	"golang.org/x/net/websocket"
 )
 
 type Chrome struct {
 	browser.BaseBrowser
-	Id int32
-	mu sync.Mutex
+	mu    sync.Mutex
+	wsURL string // DevTools endpoint of the connected page target
+
+	debugAddr string // host:port of the DevTools HTTP endpoint
+	attached  bool   // Connected with Attach; Kill leaves the browser running
+
+	profile     string
+	keepProfile bool // persistent profiles survive Kill
+
+	lanes      lanes
+	network    networkTracker
+	downloads  downloadTracker
+	uploads    uploadTracker
+	intercepts interceptTracker
+	visibility visibilityTracker
+	watches    watchTracker
+	appearance appearance
+	middleware middlewares
+	telemetry  telemetry
+	pubsub     pubsub
+	bindOnce   sync.Once
+	parent     *Chrome               // Set for windows opened with OpenWindow
+	containers map[string]*Container // Guarded by the browser lock
+
+	waitUntil     browser.WaitUntil
+	deterministic bool
+	blockFonts    bool
+	fontCSS       string // Style sheet for WithFonts and WithFontStack
+
+	initScripts  []string // Sources added with AddInitScript
+	extraHeaders map[string]string
+	userAgent    string
+	metrics      *deviceMetrics // Set by SetDeviceMetrics
+	grants       []grant        // Permissions granted with GrantPermissions
+	suspended    bool
+	hidden       *browser.Bounds // Bounds before Hide, nil while shown
+	mainFrame    string
+	trackOnce    sync.Once
 }
 
-func New(args ...string) (*Chrome, error) {
-	path, err := FindPath()
+func New(opts ...browser.Option) (*Chrome, error) {
+	o := browser.NewOptions(opts...)
+
+	path := o.ExecutablePath
+	var err error
+	if path == "" && o.Portable {
+		path, err = o.FindPortable(binaryNames()...)
+	} else if path == "" {
+		path, err = FindPath()
+		if err != nil && o.BrowserManifest != nil {
+			path, err = o.DownloadBrowser("chromium")
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
-	os.Setenv("MAJORCA_BROWSER", path)
 
-	chrome := &Chrome{
-		BaseBrowser: browser.BaseBrowser{
-			Pending:  make(map[string]chan interface{}),
-			Bindings: make(map[string]browser.BindingFunc),
-			Path:     path,
-			Done:     make(chan struct{}), // Initialize done channel
-		},
-		Id: 1, // Initialize Chrome-specific ID counter
+	chrome := newChrome(o, path)
+
+	// Use a throwaway profile unless a persistent one was requested, so we
+	// neither collide with a running Chrome nor pollute the user's history.
+	profileDir, err := o.Profile()
+	if err != nil {
+		return nil, err
+	}
+	if profileDir == "" {
+		profileDir = filepath.Join(os.TempDir(), fmt.Sprintf("chrome_profile_%d", time.Now().UnixNano()))
+	} else {
+		chrome.keepProfile = true
+	}
+	if err := os.MkdirAll(profileDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create Chrome profile directory: %w", err)
+	}
+	chrome.profile = profileDir
+	chrome.Logger().Debug("using profile directory", "path", profileDir)
+
+	args := append(o.Args, "--user-data-dir="+profileDir)
+	if o.Width > 0 && o.Height > 0 {
+		args = append(args, fmt.Sprintf("--window-size=%d,%d", o.Width, o.Height))
+	}
+	if o.HasPosition {
+		args = append(args, fmt.Sprintf("--window-position=%d,%d", o.X, o.Y))
 	}
 
 	// Add necessary flags
@@ -52,86 +115,270 @@ func New(args ...string) (*Chrome, error) {
 		"--disable-session-crashed-bubble",
 		"--disable-features=TranslateUI",
 		"--disable-features=HoverCard",
-		"--app=data:text/html,<!DOCTYPE html><html><head><title>about:blank</title></head><body></body></html>",
 	)
 
+	if o.Deterministic {
+		args = append(args, deterministicFlags...)
+	}
+	if o.Kiosk {
+		args = append(args, "--kiosk")
+	}
+	if o.Fullscreen {
+		args = append(args, "--start-fullscreen")
+	}
+	if o.CrashDir != "" {
+		// Crashpad keeps its database in the profile unless told otherwise;
+		// look there too in case this build ignores --crash-dumps-dir.
+		crashDir, err := filepath.Abs(o.CrashDir)
+		if err != nil {
+			return nil, err
+		}
+		chrome.CrashDir = crashDir
+		chrome.DumpDir = filepath.Join(profileDir, "Crashpad")
+		args = append(args, "--enable-crash-reporter", "--crash-dumps-dir="+crashDir)
+	}
+
+	// Headless instances have no window, so the start page is opened as a
+	// regular tab instead of an --app window.
+	startURL := dataURL(blankPage(o.Title))
+	if o.Headless {
+		args = append(args, "--headless=new", startURL)
+	} else {
+		args = append(args, "--app="+startURL)
+	}
+
 	chrome.Cmd = exec.Command(path, args...)
-	chrome.Cmd.Stdout = os.Stdout
-	chrome.Cmd.Stderr = os.Stderr
+	chrome.Cmd.Stdout = o.Stdout
+	chrome.Cmd.Stderr = o.Stderr
 
 	if err := chrome.Start(); err != nil {
+		if !chrome.keepProfile {
+			os.RemoveAll(profileDir)
+		}
 		return nil, err
 	}
+	if !chrome.keepProfile {
+		chrome.TrackTemp(profileDir, 9222)
+	}
 
 	// Establish the WebSocket connection with retries
-	if err := chrome.connectWebSocketWithRetry(10, 1*time.Second); err != nil {
+	if err := chrome.connectWebSocketWithRetry(o.ConnectRetry); err != nil {
+		chrome.Kill()
+		return nil, err
+	}
+	if err := chrome.setup(o); err != nil {
 		chrome.Kill()
 		return nil, err
 	}
-
-	// Start handling responses
-	chrome.Wg.Add(1)
-	go chrome.handleResponse()
 
 	return chrome, nil
 }
 
-// connectWebSocketWithRetry tries to connect to the WebSocket endpoint with retries.
-func (c *Chrome) connectWebSocketWithRetry(maxRetries int, delay time.Duration) error {
-	var err error
-	for i := 0; i < maxRetries; i++ {
-		err = c.connectWebSocket()
+// newChrome returns an unconnected Chrome configured by o.
+func newChrome(o *browser.Options, path string) *Chrome {
+	return &Chrome{
+		BaseBrowser: browser.BaseBrowser{
+			Pending:      make(map[string]chan interface{}),
+			Bindings:     make(map[string]browser.BindingFunc),
+			Path:         path,
+			Log:          o.Logger,
+			Timeout:      o.CommandTimeout,
+			TraceEnabled: o.Trace,
+			Redact:       o.Redaction,
+			ReadLimit:    o.ReadLimit,
+			Compression:  o.Compression,
+			Stop:         make(chan struct{}), // Initialize stop channel
+		},
+		telemetry:     telemetry{since: time.Now(), slow: o.SlowCommand, large: o.LargeMessage},
+		waitUntil:     o.WaitUntil,
+		deterministic: o.Deterministic,
+		blockFonts:    o.BlockRemoteFonts,
+		fontCSS:       o.FontCSS(),
+		debugAddr:     "localhost:9222",
+	}
+}
+
+// setup starts reading from the connected page and applies the per-page
+// options. The caller kills c if it fails.
+func (c *Chrome) setup(o *browser.Options) error {
+	if o.MinVersion != "" {
+		version, err := c.BrowserVersion()
 		if err == nil {
-			return nil
+			err = o.CheckVersion(version)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Start handling responses
+	c.Wg.Add(1)
+	go c.handleResponse()
+
+	if err := c.trackNavigations(); err != nil {
+		return err
+	}
+	if c.deterministic {
+		if err := c.setupDeterministic(); err != nil {
+			return err
+		}
+	}
+	if err := c.setupFonts(); err != nil {
+		return err
+	}
+	if err := c.installRuntime(); err != nil {
+		return err
+	}
+	if o.DownloadDir != "" {
+		if err := c.HandleDownloads(DownloadOptions{Dir: o.DownloadDir}); err != nil {
+			return err
 		}
-		fmt.Printf("Attempt %d: %v\n", i+1, err)
-		time.Sleep(delay)
 	}
-	return fmt.Errorf("failed to connect to WebSocket after %d attempts: %v", maxRetries, err)
+	if o.Title != "" {
+		if err := c.SetTitle(o.Title); err != nil {
+			return err
+		}
+	}
+	if o.AlwaysOnTop && !o.Headless {
+		if err := c.SetAlwaysOnTop(true); err != nil {
+			c.Logger().Warn("failed to keep window on top", "error", err)
+		}
+	}
+	return nil
+}
+
+// Kill stops Chrome and deletes its profile unless it is persistent. For
+// windows opened with OpenWindow it only closes that window, and for a
+// browser connected with Attach it only drops the connection.
+func (c *Chrome) Kill() error {
+	if c.attached {
+		err := c.BaseBrowser.Kill()
+		c.Closed(nil)
+		return err
+	}
+	if c.parent != nil {
+		c.Send("Page.close", nil)
+		err := c.BaseBrowser.Kill()
+		c.Closed(nil)
+		return err
+	}
+
+	c.Lock()
+	containers := make([]*Container, 0, len(c.containers))
+	for _, ct := range c.containers {
+		containers = append(containers, ct)
+	}
+	c.Unlock()
+	for _, ct := range containers {
+		if err := ct.Save(); err != nil {
+			c.Logger().Warn("failed to save container", "container", ct.Name, "error", err)
+		}
+	}
+
+	if err := c.BaseBrowser.Kill(); err != nil {
+		return err
+	}
+
+	if c.keepProfile || c.profile == "" {
+		return nil
+	}
+
+	// Chrome keeps files in the profile open until it has fully exited.
+	select {
+	case <-c.Done():
+	case <-time.After(5 * time.Second):
+	}
+
+	if err := os.RemoveAll(c.profile); err != nil {
+		return fmt.Errorf("failed to delete Chrome profile directory: %w", err)
+	}
+	c.UntrackTemp()
+	return nil
+}
+
+// connectWebSocketWithRetry connects to the WebSocket endpoint, retrying
+// as policy says while the browser starts up.
+func (c *Chrome) connectWebSocketWithRetry(policy browser.RetryPolicy) error {
+	attempt := 0
+	err := policy.RetryWithin(func(remaining time.Duration) error {
+		attempt++
+		err := c.connectWebSocket(remaining)
+		if err != nil {
+			c.Logger().Debug("DevTools connection attempt failed", "attempt", attempt, "error", err)
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to WebSocket: %w", err)
+	}
+	return nil
 }
 
 // connectWebSocket establishes a WebSocket connection to Chrome's DevTools.
-func (c *Chrome) connectWebSocket() error {
+// It waits up to timeout for the debugging port, or portTimeout for zero.
+func (c *Chrome) connectWebSocket(timeout time.Duration) error {
+	if timeout <= 0 || timeout > portTimeout {
+		timeout = portTimeout
+	}
 	// Check if port is open
-	if !waitForPort("localhost", 9222, 10*time.Second) {
+	if !waitForPort("localhost", 9222, timeout) {
 		return fmt.Errorf("Chrome remote debugging port 9222 is not open")
 	}
 
+	wsURL, err := c.pageTarget()
+	if err != nil {
+		return err
+	}
+	return c.dialPage(wsURL)
+}
+
+// pageTarget returns the WebSocket debugger URL of the first page target.
+func (c *Chrome) pageTarget() (string, error) {
 	// Fetch the WebSocket debugger URL
-	resp, err := http.Get("http://localhost:9222/json")
+	resp, err := http.Get("http://" + c.debugAddr + "/json")
 	if err != nil {
-		return fmt.Errorf("failed to get WebSocket debugger URL: %w", err)
+		return "", fmt.Errorf("failed to get WebSocket debugger URL: %w", err)
 	}
 	defer resp.Body.Close()
 
 	var targets []struct {
+		Type                 string `json:"type"`
 		WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&targets); err != nil {
-		return fmt.Errorf("failed to decode JSON response: %w", err)
+		return "", fmt.Errorf("failed to decode JSON response: %w", err)
 	}
 
-	if len(targets) == 0 {
-		return fmt.Errorf("no WebSocket targets found")
+	// Connect to the first available page
+	for _, t := range targets {
+		if (t.Type == "" || t.Type == "page") && t.WebSocketDebuggerURL != "" {
+			return t.WebSocketDebuggerURL, nil
+		}
 	}
+	return "", fmt.Errorf("no WebSocket targets found")
+}
 
-	// Connect to the first available WebSocket
-	wsURL := targets[0].WebSocketDebuggerURL
-	fmt.Printf("Connecting to WebSocket URL: %s\n", wsURL)
-	ws, err := websocket.Dial(wsURL, "", "http://localhost")
+// dialPage connects c to the page target at wsURL.
+func (c *Chrome) dialPage(wsURL string) error {
+	c.Logger().Debug("connecting to DevTools", "url", wsURL)
+	ws, err := c.Dial(wsURL)
 	if err != nil {
 		return fmt.Errorf("failed to dial WebSocket: %w", err)
 	}
 
-	fmt.Println("WebSocket connection established")
+	c.Logger().Debug("DevTools connection established")
 	c.Ws = ws
+	c.wsURL = wsURL
 	return nil
 }
 
+// portTimeout bounds a connection attempt's wait for the debugging port.
+const portTimeout = 10 * time.Second
+
 // waitForPort checks if a TCP port is open within a timeout period.
 func waitForPort(host string, port int, timeout time.Duration) bool {
-	address := fmt.Sprintf("%s:%d", host, port)
+	address := net.JoinHostPort(host, strconv.Itoa(port))
 	deadline := time.Now().Add(timeout)
 	for time.Now().Before(deadline) {
 		conn, err := net.DialTimeout("tcp", address, 500*time.Millisecond)
@@ -149,144 +396,219 @@ func (c *Chrome) handleResponse() {
 	defer c.Wg.Done()
 	for {
 		select {
-		case <-c.Done:
+		case <-c.Stop:
 			return
 		default:
 			var res browser.Result
-			if err := websocket.JSON.Receive(c.Ws, &res); err != nil {
-				fmt.Printf("Error receiving response: %v\n", err)
+			if err := c.Ws.ReadJSON(&res); err != nil {
+				if c.Stopping() {
+					return
+				}
+				if !browser.IsConnError(err) {
+					if c.Gone() {
+						return
+					}
+					c.Logger().Error("failed to receive response", "error", err)
+					continue
+				}
+				if !c.reconnect(err) {
+					// The page target is gone, i.e. the window was closed.
+					c.FailPending(browser.ErrConnectionClosed)
+					c.Closed(nil)
+					return
+				}
 				continue
 			}
 
-			idStr := fmt.Sprintf("%d", res.ID)
-			c.Lock()
-			if ch, ok := c.Pending[idStr]; ok {
-				ch <- res
-				delete(c.Pending, idStr)
+			if res.Method == "Inspector.targetCrashed" {
+				c.Crashed(&browser.CrashReport{Time: time.Now(), Err: "page renderer crashed", Renderer: true})
 			}
-			c.Unlock()
+			if res.Method != "" {
+				c.Trace("event", res.Method, res.Params)
+				c.telemetry.event(res.Method, len(res.Params))
+				e := browser.Event{Method: res.Method, Params: res.Params}
+				if c.middleware.allow(e) {
+					c.Emit(e)
+				}
+				continue
+			}
+
+			c.Deliver(fmt.Sprintf("%d", res.ID), res)
 		}
 	}
 }
 
-// Load navigates Chrome to the specified URL.
+// Load navigates Chrome to the specified URL. With WithWaitUntil it also
+// waits for the new page to reach that stage, see LoadWait.
 func (c *Chrome) Load(url string) error {
-	c.Lock()
-	defer c.Unlock()
+	return c.LoadWait(url, c.waitUntil)
+}
 
-	if c.Ws == nil {
-		return fmt.Errorf("WebSocket connection is not established")
+// Eval evaluates a JavaScript expression in the context of the loaded page.
+// If the expression yields a promise, Eval waits for it and returns the
+// resolved value. If the expression throws or the promise rejects, the error
+// is a *JSError.
+func (c *Chrome) Eval(expr string) (string, string, error) {
+	raw, err := c.Send("Runtime.evaluate", map[string]interface{}{
+		"expression":   expr,
+		"awaitPromise": true,
+	})
+	if err != nil {
+		return "", "", err
 	}
 
-	message := map[string]interface{}{
-		"id":     c.Id,
-		"method": "Page.navigate",
-		"params": map[string]interface{}{
-			"url": url,
-		},
+	// Define a structure to parse the evaluation result
+	var evalRes struct {
+		Result struct {
+			Type  string      `json:"type"`
+			Value interface{} `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *exceptionDetails `json:"exceptionDetails"`
 	}
 
-	idStr := fmt.Sprintf("%d", c.Id)
-	responseChan := make(chan interface{})
-	c.Pending[idStr] = responseChan
-	c.Id++
-
-	fmt.Printf("Sending message: %v\n", message)
-	if err := websocket.JSON.Send(c.Ws, message); err != nil {
-		delete(c.Pending, idStr)
-		return fmt.Errorf("failed to send WebSocket message: %w", err)
+	if err := json.Unmarshal(raw, &evalRes); err != nil {
+		return "", "", fmt.Errorf("failed to unmarshal response: %w", err)
 	}
-	fmt.Println("Page.navigate message sent")
-
-	fmt.Println("Waiting for response")
-	resInterface := <-responseChan
-	fmt.Printf("Received response: %v\n", resInterface)
-
-	// Type assert the interface{} to browser.Result
-	res, ok := resInterface.(browser.Result)
-	if !ok {
-		return fmt.Errorf("unexpected response type")
+	if evalRes.ExceptionDetails != nil {
+		return "", "", evalRes.ExceptionDetails.jsError()
 	}
 
-	if res.Error != nil {
-		return fmt.Errorf("navigation error: %s", res.Error.Message)
+	// Handle different types accordingly
+	switch v := evalRes.Result.Value.(type) {
+	case string:
+		return v, evalRes.Result.Type, nil
+	default:
+		return fmt.Sprintf("%v", v), evalRes.Result.Type, nil
 	}
+}
 
-	return nil
+// Send calls an arbitrary DevTools method and waits for its raw result.
+func (c *Chrome) Send(method string, params interface{}) (json.RawMessage, error) {
+	return c.SendPriority(PriorityInteractive, method, params)
 }
 
-// Eval evaluates a JavaScript expression in the context of the loaded page.
-func (c *Chrome) Eval(expr string) (string, string, error) {
-	c.Lock()
-	if c.Ws == nil {
-		c.Unlock()
-		return "", "", fmt.Errorf("WebSocket connection is not established")
-	}
+// Reply is the outcome of a command sent with SendAsync.
+type Reply struct {
+	Result json.RawMessage
+	Err    error
+}
 
-	message := map[string]interface{}{
-		"id":     c.Id,
-		"method": "Runtime.evaluate",
-		"params": map[string]interface{}{
-			"expression": expr,
-		},
+// SendAsync calls an arbitrary DevTools method without waiting for its
+// response. The command is written before SendAsync returns, so commands
+// reach Chrome in call order. Command middleware runs before the write, so
+// middleware that holds a command back also holds up SendAsync. The
+// returned channel receives exactly one Reply once the response arrives.
+func (c *Chrome) SendAsync(method string, params interface{}) <-chan Reply {
+	ch := make(chan Reply, 1)
+	written := make(chan struct{})
+	var once sync.Once
+	h := c.middleware.wrap(func(cmd Command) (json.RawMessage, error) {
+		await, err := c.start(cmd.Priority, cmd.Method, cmd.Params)
+		once.Do(func() { close(written) })
+		if err != nil {
+			return nil, err
+		}
+		return await()
+	})
+	if h != nil {
+		go func() {
+			res, err := h(Command{Method: method, Params: params, Priority: PriorityInteractive})
+			// Middleware may answer without passing the command on.
+			once.Do(func() { close(written) })
+			ch <- Reply{Result: res, Err: err}
+		}()
+		<-written
+		return ch
 	}
-
-	idStr := fmt.Sprintf("%d", c.Id)
-	responseChan := make(chan interface{})
-	c.Pending[idStr] = responseChan
-	c.Id++
-
-	fmt.Printf("Sending message: %v\n", message)
-	if err := websocket.JSON.Send(c.Ws, message); err != nil {
-		delete(c.Pending, idStr)
-		c.Unlock()
-		return "", "", fmt.Errorf("failed to send WebSocket message: %w", err)
+	await, err := c.start(PriorityInteractive, method, params)
+	if err != nil {
+		ch <- Reply{Err: err}
+		return ch
 	}
-	fmt.Println("Runtime.evaluate message sent")
-	c.Unlock()
+	go func() {
+		res, err := await()
+		ch <- Reply{Result: res, Err: err}
+	}()
+	return ch
+}
 
-	fmt.Println("Waiting for response")
-	resInterface := <-responseChan
-	fmt.Printf("Received response: %v\n", resInterface)
+// SendPriority is Send on the given lane. Background commands yield to
+// interactive ones that are waiting to be sent.
+func (c *Chrome) SendPriority(p Priority, method string, params interface{}) (json.RawMessage, error) {
+	cmd := Command{Method: method, Params: params, Priority: p}
+	if h := c.middleware.wrap(c.send); h != nil {
+		return h(cmd)
+	}
+	return c.send(cmd)
+}
 
-	// Type assert the interface{} to browser.Result
-	res, ok := resInterface.(browser.Result)
-	if !ok {
-		return "", "", fmt.Errorf("unexpected response type")
+// send is the innermost Handler, writing cmd and waiting for its result.
+func (c *Chrome) send(cmd Command) (json.RawMessage, error) {
+	await, err := c.start(cmd.Priority, cmd.Method, cmd.Params)
+	if err != nil {
+		return nil, err
 	}
+	return await()
+}
 
-	if res.Error != nil {
-		return "", "", fmt.Errorf("evaluation error: %s", res.Error.Message)
+// start writes a command on the lane for p and returns a function that
+// waits for its response.
+func (c *Chrome) start(p Priority, method string, params interface{}) (func() (json.RawMessage, error), error) {
+	if params == nil {
+		params = map[string]interface{}{}
 	}
 
-	// Define a structure to parse the evaluation result
-	var evalRes struct {
-		Result struct {
-			Type  string      `json:"type"`
-			Value interface{} `json:"value"`
-		} `json:"result"`
+	c.Lock()
+	if c.Ws == nil {
+		c.Unlock()
+		return nil, fmt.Errorf("WebSocket connection is not established")
 	}
 
-	// Marshal and Unmarshal to convert interface{} to JSON
-	resBytes, err := json.Marshal(res.Result)
+	id := c.NextID()
+	message, err := json.Marshal(map[string]interface{}{
+		"id":     id,
+		"method": method,
+		"params": params,
+	})
 	if err != nil {
-		return "", "", fmt.Errorf("failed to marshal response: %w", err)
+		c.Unlock()
+		return nil, fmt.Errorf("failed to marshal %s params: %w", method, err)
 	}
 
-	if err := json.Unmarshal(resBytes, &evalRes); err != nil {
-		return "", "", fmt.Errorf("failed to unmarshal response: %w", err)
-	}
+	idStr := fmt.Sprintf("%d", id)
+	responseChan := make(chan interface{}, 1)
+	c.Pending[idStr] = responseChan
+	c.Unlock()
 
-	// Handle different types accordingly
-	switch v := evalRes.Result.Value.(type) {
-	case string:
-		return v, evalRes.Result.Type, nil
-	default:
-		return fmt.Sprintf("%v", v), evalRes.Result.Type, nil
+	c.Logger().Debug("sending message", "id", id, "method", method)
+	c.Trace("send", method, params)
+	started := time.Now()
+	if err := c.write(p, message); err != nil {
+		c.Lock()
+		delete(c.Pending, idStr)
+		c.Unlock()
+		return nil, fmt.Errorf("failed to send WebSocket message: %w", err)
 	}
+
+	return func() (json.RawMessage, error) {
+		res, err := c.Await(idStr, responseChan)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", method, err)
+		} else if res.Error != nil {
+			err = fmt.Errorf("%s error: %s", method, res.Error.Message)
+		}
+		c.telemetry.command(c, id, method, time.Since(started), len(message), len(res.Result), err)
+		if err != nil {
+			return nil, err
+		}
+		c.Logger().Debug("received response", "id", id)
+		c.Trace("recv", method, res.Result)
+		return res.Result, nil
+	}, nil
 }
 
-// FindPath locates the Chrome executable path.
+// FindPath locates the first installed browser of Kinds, in order. Use
+// FindKind or NewWithKind to prefer a specific one.
 func FindPath() (string, error) {
 	envPath, _ := os.LookupEnv("MAJORCA_BROWSER")
 	if envPath != "" {
@@ -294,36 +616,27 @@ func FindPath() (string, error) {
 	}
 
 	var paths []string
-
-	if runtime.GOOS == "windows" {
-		username := os.Getenv("USERNAME")
-		paths = []string{
-			`C:\Program Files (x86)\Google\Chrome\Application\chrome.exe`,
-			`C:\Program Files\Google\Chrome\Application\chrome.exe`,
-			filepath.Join("C:\\Users", username, "AppData\\Local\\Google\\Chrome\\Application\\chrome.exe"),
-
-			`C:\Program Files (x86)\Chromium\Application\chrome.exe`,
-			`C:\Program Files\Chromium\Application\chrome.exe`,
-			filepath.Join("C:\\Users", username, "AppData\\Local\\Chromium\\Application\\chrome.exe"),
-
-			`C:\Program Files (x86)\Microsoft\Edge\Application\msedge.exe`,
-			`C:\Program Files\Microsoft\Edge\Application\msedge.exe`,
-			filepath.Join("C:\\Users", username, "AppData\\Local\\Microsoft\\Edge\\Application\\msedge.exe"),
-
-			`C:\Program Files (x86)\BraveSoftware\Brave-Browser\Application\brave.exe`,
-			`C:\Program Files\BraveSoftware\Brave-Browser\Application\brave.exe`,
-			filepath.Join("C:\\Users", username, "AppData\\Local\\BraveSoftware\\Brave-Browser\\Application\\brave.exe"),
+	for _, kind := range Kinds {
+		p, err := candidates(kind)
+		if err != nil {
+			return "", err
 		}
-	} else {
-		return "", fmt.Errorf("unsupported OS: %s", runtime.GOOS)
+		paths = append(paths, p...)
 	}
 
-	for _, p := range paths {
-		p = os.ExpandEnv(p)
-		if _, err := os.Stat(p); err == nil {
-			return p, nil
-		}
+	// Prefer a build native to the host (e.g. ARM64 Edge on Windows-on-ARM)
+	// over one that would run under x86 emulation.
+	if p, ok := browser.SelectBinary(paths); ok {
+		return p, nil
 	}
 
 	return "", fmt.Errorf("could not find Chrome binary")
 }
+
+// binaryNames lists the executable names searched for in portable mode.
+func binaryNames() []string {
+	if runtime.GOOS == "windows" {
+		return []string{"chrome.exe", "msedge.exe", "brave.exe"}
+	}
+	return []string{"chrome", "chromium", "msedge", "brave"}
+}
@@ -0,0 +1,76 @@
+package chrome
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DataType is a kind of site data ClearData can remove.
+type DataType string
+
+const (
+	DataCookies        DataType = "cookies"
+	DataLocalStorage   DataType = "local_storage"
+	DataIndexedDB      DataType = "indexeddb"
+	DataCacheStorage   DataType = "cache_storage"
+	DataServiceWorkers DataType = "service_workers"
+	DataFileSystems    DataType = "file_systems"
+	DataWebSQL         DataType = "websql"
+	DataShaderCache    DataType = "shader_cache"
+	// DataHTTPCache is the network cache. It is not partitioned by origin,
+	// so clearing it affects every site.
+	DataHTTPCache DataType = "http_cache"
+	// DataAll is every type above.
+	DataAll DataType = "all"
+)
+
+// ClearData wipes the given kinds of data stored by the origin of the
+// current page, or all of them if types is empty, e.g. for a "reset app"
+// feature or to isolate tests from each other.
+func (c *Chrome) ClearData(types ...DataType) error {
+	var origin string
+	if err := c.evalJSON(`location.origin`, &origin); err != nil {
+		return err
+	}
+	if origin == "" || origin == "null" {
+		return fmt.Errorf("page has no origin to clear data for")
+	}
+	return c.ClearOriginData(origin, types...)
+}
+
+// ClearOriginData is ClearData for an explicit origin such as
+// "https://example.com".
+func (c *Chrome) ClearOriginData(origin string, types ...DataType) error {
+	if len(types) == 0 {
+		types = []DataType{DataAll}
+	}
+
+	var storage []string
+	httpCache := false
+	for _, t := range types {
+		switch t {
+		case DataHTTPCache:
+			httpCache = true
+		case DataAll:
+			httpCache = true
+			storage = append(storage, string(t))
+		default:
+			storage = append(storage, string(t))
+		}
+	}
+
+	if len(storage) > 0 {
+		if _, err := c.Send("Storage.clearDataForOrigin", map[string]interface{}{
+			"origin":       origin,
+			"storageTypes": strings.Join(storage, ","),
+		}); err != nil {
+			return err
+		}
+	}
+	if httpCache {
+		if _, err := c.Send("Network.clearBrowserCache", nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package chrome
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// grantClipboard allows the page to use the async Clipboard API without a
+// prompt and makes it believe it has focus, which the API also requires.
+func (c *Chrome) grantClipboard() error {
+	if err := c.grantPermissions(grant{permissions: []Permission{PermissionClipboardRead, PermissionClipboardWrite}}); err != nil {
+		return err
+	}
+	_, err := c.Send("Emulation.setFocusEmulationEnabled", map[string]interface{}{"enabled": true})
+	return err
+}
+
+// ReadClipboard returns the text on the system clipboard.
+func (c *Chrome) ReadClipboard() (string, error) {
+	if err := c.grantClipboard(); err != nil {
+		return "", err
+	}
+	var text string
+	err := c.evalJSON(`navigator.clipboard.readText()`, &text)
+	return text, err
+}
+
+// WriteClipboard puts text on the system clipboard.
+func (c *Chrome) WriteClipboard(text string) error {
+	if err := c.grantClipboard(); err != nil {
+		return err
+	}
+	_, _, err := c.Eval(fmt.Sprintf(`navigator.clipboard.writeText(%s)`, quote(text)))
+	return err
+}
+
+// ReadClipboardImage returns the image on the system clipboard as PNG, or
+// nil if the clipboard holds no image.
+func (c *Chrome) ReadClipboardImage() ([]byte, error) {
+	if err := c.grantClipboard(); err != nil {
+		return nil, err
+	}
+	var data string
+	err := c.evalJSON(`(async () => {
+		for (const item of await navigator.clipboard.read()) {
+			if (!item.types.includes("image/png")) continue;
+			const blob = await item.getType("image/png");
+			const bytes = new Uint8Array(await blob.arrayBuffer());
+			let s = "";
+			for (let i = 0; i < bytes.length; i += 0x8000) {
+				s += String.fromCharCode(...bytes.subarray(i, i + 0x8000));
+			}
+			return btoa(s);
+		}
+		return "";
+	})()`, &data)
+	if err != nil || data == "" {
+		return nil, err
+	}
+	png, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode clipboard image: %w", err)
+	}
+	return png, nil
+}
+
+// WriteClipboardImage puts a PNG image on the system clipboard.
+func (c *Chrome) WriteClipboardImage(png []byte) error {
+	if err := c.grantClipboard(); err != nil {
+		return err
+	}
+	_, _, err := c.Eval(fmt.Sprintf(`(async () => {
+		const bytes = Uint8Array.from(atob(%s), (ch) => ch.charCodeAt(0));
+		const blob = new Blob([bytes], {type: "image/png"});
+		await navigator.clipboard.write([new ClipboardItem({"image/png": blob})]);
+	})()`, quote(base64.StdEncoding.EncodeToString(png))))
+	return err
+}
@@ -0,0 +1,164 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// ConsoleMessage is a console API call or a browser log entry of the page.
+type ConsoleMessage struct {
+	Level  string // "debug", "info", "warning" or "error"
+	Text   string
+	Source string // "console" for console.* calls, otherwise the Log domain source, e.g. "network"
+	URL    string
+	Line   int
+	Column int
+	Stack  []StackFrame
+	Time   time.Time
+}
+
+// StackFrame is one frame of a JavaScript stack trace.
+type StackFrame struct {
+	Function string `json:"functionName"`
+	URL      string `json:"url"`
+	Line     int    `json:"lineNumber"`
+	Column   int    `json:"columnNumber"`
+}
+
+type stackTrace struct {
+	CallFrames []StackFrame `json:"callFrames"`
+}
+
+type remoteObject struct {
+	Type                string          `json:"type"`
+	Value               json.RawMessage `json:"value"`
+	UnserializableValue string          `json:"unserializableValue"`
+	Description         string          `json:"description"`
+}
+
+// String renders a console argument roughly the way DevTools does.
+func (o remoteObject) String() string {
+	if o.Type == "string" {
+		var s string
+		json.Unmarshal(o.Value, &s)
+		return s
+	}
+	if o.UnserializableValue != "" {
+		return o.UnserializableValue
+	}
+	if o.Type == "undefined" {
+		return "undefined"
+	}
+	if len(o.Value) > 0 && o.Type != "object" {
+		return string(o.Value)
+	}
+	if o.Description != "" {
+		return o.Description
+	}
+	return string(o.Value)
+}
+
+// OnConsole calls handler for every console message and log entry of the
+// page. The returned function stops delivery.
+func (c *Chrome) OnConsole(handler func(ConsoleMessage)) (func(), error) {
+	offAPI := c.On("Runtime.consoleAPICalled", func(e browser.Event) {
+		var p struct {
+			Type       string         `json:"type"`
+			Args       []remoteObject `json:"args"`
+			Timestamp  float64        `json:"timestamp"`
+			StackTrace *stackTrace    `json:"stackTrace"`
+		}
+		if json.Unmarshal(e.Params, &p) != nil {
+			return
+		}
+		parts := make([]string, len(p.Args))
+		for i, arg := range p.Args {
+			parts[i] = arg.String()
+		}
+		msg := ConsoleMessage{
+			Level:  consoleLevel(p.Type),
+			Text:   strings.Join(parts, " "),
+			Source: "console",
+			Time:   time.UnixMilli(int64(p.Timestamp)),
+		}
+		if p.StackTrace != nil && len(p.StackTrace.CallFrames) > 0 {
+			msg.Stack = p.StackTrace.CallFrames
+			msg.URL, msg.Line, msg.Column = msg.Stack[0].URL, msg.Stack[0].Line, msg.Stack[0].Column
+		}
+		handler(msg)
+	})
+
+	offLog := c.On("Log.entryAdded", func(e browser.Event) {
+		var p struct {
+			Entry struct {
+				Source     string      `json:"source"`
+				Level      string      `json:"level"`
+				Text       string      `json:"text"`
+				URL        string      `json:"url"`
+				LineNumber int         `json:"lineNumber"`
+				Timestamp  float64     `json:"timestamp"`
+				StackTrace *stackTrace `json:"stackTrace"`
+			} `json:"entry"`
+		}
+		if json.Unmarshal(e.Params, &p) != nil {
+			return
+		}
+		msg := ConsoleMessage{
+			Level:  consoleLevel(p.Entry.Level),
+			Text:   p.Entry.Text,
+			Source: p.Entry.Source,
+			URL:    p.Entry.URL,
+			Line:   p.Entry.LineNumber,
+			Time:   time.UnixMilli(int64(p.Entry.Timestamp)),
+		}
+		if p.Entry.StackTrace != nil {
+			msg.Stack = p.Entry.StackTrace.CallFrames
+		}
+		handler(msg)
+	})
+
+	off := func() {
+		offAPI()
+		offLog()
+	}
+	if _, err := c.Send("Log.enable", nil); err != nil {
+		off()
+		return nil, err
+	}
+	return off, nil
+}
+
+// ConsoleTo forwards console messages to ch. Messages are dropped while ch
+// is full rather than stalling other events.
+func (c *Chrome) ConsoleTo(ch chan<- ConsoleMessage) (func(), error) {
+	return c.OnConsole(func(m ConsoleMessage) {
+		select {
+		case ch <- m:
+		default:
+		}
+	})
+}
+
+// consoleLevel folds console API types and Log levels into four levels.
+func consoleLevel(kind string) string {
+	switch kind {
+	case "error", "assert":
+		return "error"
+	case "warning":
+		return "warning"
+	case "debug", "verbose", "trace":
+		return "debug"
+	}
+	return "info"
+}
+
+func (m ConsoleMessage) String() string {
+	if m.URL == "" {
+		return fmt.Sprintf("[%s] %s", m.Level, m.Text)
+	}
+	return fmt.Sprintf("[%s] %s (%s:%d)", m.Level, m.Text, m.URL, m.Line+1)
+}
@@ -0,0 +1,420 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// Container is a named storage partition, like Firefox's Multi-Account
+// Containers: windows opened in different containers share no cookies,
+// storage or cache, so one app can be signed in to several accounts at
+// once. Cookies, localStorage and IndexedDB of a container are kept in the
+// profile and restored the next time a container of that name is opened,
+// which makes containers persistent when the profile is. Caches and service
+// workers are rebuilt by the sites themselves, and sessionStorage ends with
+// the session as usual.
+type Container struct {
+	Name      string
+	chrome    *Chrome
+	contextID string
+
+	mu      sync.Mutex
+	origins map[string]bool // Sites whose storage Save collects
+}
+
+// containerState is what Save writes to the profile.
+type containerState struct {
+	Cookies []map[string]interface{} `json:"cookies"`
+	Origins map[string]originStorage `json:"origins,omitempty"`
+}
+
+// originStorage is the storage of one site. IndexedDB values are encoded
+// by storageScript so that dates, binary data and blobs survive JSON.
+type originStorage struct {
+	LocalStorage map[string]string `json:"localStorage,omitempty"`
+	IndexedDB    json.RawMessage   `json:"indexedDB,omitempty"`
+}
+
+func (s originStorage) empty() bool {
+	return len(s.LocalStorage) == 0 && (len(s.IndexedDB) == 0 || string(s.IndexedDB) == "[]")
+}
+
+// Container returns the container called name, creating it on first use.
+func (c *Chrome) Container(name string) (*Container, error) {
+	root := c.root()
+	root.Lock()
+	if ct, ok := root.containers[name]; ok {
+		root.Unlock()
+		return ct, nil
+	}
+	root.Unlock()
+
+	raw, err := root.Send("Target.createBrowserContext", map[string]interface{}{
+		"disposeOnDetach": false,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		BrowserContextID string `json:"browserContextId"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal browser context: %w", err)
+	}
+	ct := &Container{Name: name, chrome: root, contextID: res.BrowserContextID, origins: make(map[string]bool)}
+
+	if err := ct.restore(); err != nil {
+		root.Logger().Warn("failed to restore container storage", "container", name, "error", err)
+	}
+
+	root.Lock()
+	if root.containers == nil {
+		root.containers = make(map[string]*Container)
+	}
+	if existing, ok := root.containers[name]; ok {
+		// Lost a race with another caller; keep theirs.
+		root.Unlock()
+		root.Send("Target.disposeBrowserContext", map[string]interface{}{"browserContextId": res.BrowserContextID})
+		return existing, nil
+	}
+	root.containers[name] = ct
+	root.Unlock()
+	return ct, nil
+}
+
+// OpenWindow opens url in a new window belonging to the container, see
+// Chrome.OpenWindow. Save collects the storage of every site the window
+// shows.
+func (ct *Container) OpenWindow(url string) (*Chrome, error) {
+	w, err := ct.chrome.openWindow(url, ct.contextID)
+	if err != nil {
+		return nil, err
+	}
+	w.On("Page.frameNavigated", func(e browser.Event) {
+		var p struct {
+			Frame struct {
+				ParentID       string `json:"parentId"`
+				SecurityOrigin string `json:"securityOrigin"`
+			} `json:"frame"`
+		}
+		if json.Unmarshal(e.Params, &p) == nil && p.Frame.ParentID == "" {
+			ct.addOrigin(p.Frame.SecurityOrigin)
+		}
+	})
+	return w, nil
+}
+
+// addOrigin marks origin's storage for saving. Only web origins have
+// storage worth keeping.
+func (ct *Container) addOrigin(origin string) {
+	if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+		return
+	}
+	ct.mu.Lock()
+	ct.origins[origin] = true
+	ct.mu.Unlock()
+}
+
+// Save writes the container's cookies, and the localStorage and IndexedDB
+// of the sites it has shown, to the profile. Collecting the storage briefly
+// opens a hidden window in the container.
+func (ct *Container) Save() error {
+	raw, err := ct.chrome.Send("Storage.getCookies", map[string]interface{}{
+		"browserContextId": ct.contextID,
+	})
+	if err != nil {
+		return err
+	}
+	var state containerState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return fmt.Errorf("failed to unmarshal cookies: %w", err)
+	}
+
+	// Windows opened by the pages themselves are not tracked by OpenWindow.
+	raw, err = ct.chrome.Send("Target.getTargets", nil)
+	if err != nil {
+		return err
+	}
+	var targets struct {
+		TargetInfos []struct {
+			Type             string `json:"type"`
+			URL              string `json:"url"`
+			BrowserContextID string `json:"browserContextId"`
+		} `json:"targetInfos"`
+	}
+	if err := json.Unmarshal(raw, &targets); err != nil {
+		return fmt.Errorf("failed to unmarshal targets: %w", err)
+	}
+	for _, t := range targets.TargetInfos {
+		if t.Type != "page" || t.BrowserContextID != ct.contextID {
+			continue
+		}
+		if u, err := url.Parse(t.URL); err == nil && u.Host != "" {
+			ct.addOrigin(u.Scheme + "://" + u.Host)
+		}
+	}
+
+	err = ct.eachOrigin(func(w *Chrome, origin string) error {
+		var s originStorage
+		if err := w.evalJSON(storageScript+`.dump()`, &s); err != nil {
+			return err
+		}
+		if !s.empty() {
+			if state.Origins == nil {
+				state.Origins = make(map[string]originStorage)
+			}
+			state.Origins[origin] = s
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	path := ct.path()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create container directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// eachOrigin loads each marked origin in a hidden window of the container
+// and calls f there. The window is served an empty document instead of the
+// site, so no request reaches the network and no site script runs.
+func (ct *Container) eachOrigin(f func(w *Chrome, origin string) error) error {
+	ct.mu.Lock()
+	origins := make([]string, 0, len(ct.origins))
+	for origin := range ct.origins {
+		origins = append(origins, origin)
+	}
+	ct.mu.Unlock()
+	if len(origins) == 0 {
+		return nil
+	}
+	sort.Strings(origins)
+
+	w, err := ct.chrome.openWindow("about:blank", ct.contextID)
+	if err != nil {
+		return err
+	}
+	defer w.Kill()
+	if err := w.Hide(); err != nil {
+		ct.chrome.Logger().Debug("failed to hide storage window", "error", err)
+	}
+	_, err = w.Intercept("*", func(Request) Response {
+		return Response{Status: 200, Headers: map[string]string{"Content-Type": "text/html"}, Body: []byte("<!doctype html>")}
+	})
+	if err != nil {
+		return err
+	}
+	for _, origin := range origins {
+		if err := w.LoadWait(origin+"/", browser.WaitDOMContentLoaded); err != nil {
+			return fmt.Errorf("failed to open %s: %w", origin, err)
+		}
+		if err := f(w, origin); err != nil {
+			return fmt.Errorf("storage of %s: %w", origin, err)
+		}
+	}
+	return nil
+}
+
+// Close saves the container and closes all of its windows.
+func (ct *Container) Close() error {
+	if err := ct.Save(); err != nil {
+		return err
+	}
+	root := ct.chrome
+	root.Lock()
+	delete(root.containers, ct.Name)
+	root.Unlock()
+	_, err := root.Send("Target.disposeBrowserContext", map[string]interface{}{
+		"browserContextId": ct.contextID,
+	})
+	return err
+}
+
+// Discard closes the container and its windows without saving, and deletes
+// storage saved earlier, leaving no trace in the profile.
+func (ct *Container) Discard() error {
+	root := ct.chrome
+	root.Lock()
+	delete(root.containers, ct.Name)
+	root.Unlock()
+	if err := os.Remove(ct.path()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	_, err := root.Send("Target.disposeBrowserContext", map[string]interface{}{
+		"browserContextId": ct.contextID,
+	})
+	return err
+}
+
+// restore loads the storage saved by an earlier Save.
+func (ct *Container) restore() error {
+	data, err := os.ReadFile(ct.path())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var state containerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to unmarshal container storage: %w", err)
+	}
+	if len(state.Cookies) > 0 {
+		_, err = ct.chrome.Send("Storage.setCookies", map[string]interface{}{
+			"cookies":          state.Cookies,
+			"browserContextId": ct.contextID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for origin := range state.Origins {
+		ct.addOrigin(origin)
+	}
+	return ct.eachOrigin(func(w *Chrome, origin string) error {
+		s, ok := state.Origins[origin]
+		if !ok {
+			return nil
+		}
+		arg, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		_, _, err = w.Eval(storageScript + `.restore(` + string(arg) + `)`)
+		return err
+	})
+}
+
+func (ct *Container) path() string {
+	return filepath.Join(ct.chrome.profile, "majorca-containers", url.PathEscape(ct.Name)+".json")
+}
+
+// storageScript reads and writes the localStorage and IndexedDB of the
+// current origin. IndexedDB keys and values are encoded so that dates,
+// binary data, blobs, maps and sets round-trip through JSON.
+const storageScript = `(() => {
+	const req = (r) => new Promise((ok, fail) => {
+		r.onsuccess = () => ok(r.result);
+		r.onerror = () => fail(r.error);
+	});
+	const toBase64 = (bytes) => {
+		let s = "";
+		for (let i = 0; i < bytes.length; i += 0x8000) {
+			s += String.fromCharCode(...bytes.subarray(i, i + 0x8000));
+		}
+		return btoa(s);
+	};
+	const fromBase64 = (s) => Uint8Array.from(atob(s), (ch) => ch.charCodeAt(0));
+	const encode = async (v) => {
+		if (v === null || typeof v !== "object") return v;
+		if (v instanceof Date) return {$majorca: "Date", v: v.getTime()};
+		if (v instanceof Blob) {
+			const bytes = toBase64(new Uint8Array(await v.arrayBuffer()));
+			return {$majorca: v instanceof File ? "File" : "Blob", type: v.type, name: v.name, v: bytes};
+		}
+		if (v instanceof ArrayBuffer) return {$majorca: "ArrayBuffer", v: toBase64(new Uint8Array(v))};
+		if (ArrayBuffer.isView(v)) {
+			return {$majorca: v.constructor.name, v: toBase64(new Uint8Array(v.buffer, v.byteOffset, v.byteLength))};
+		}
+		if (v instanceof Map) {
+			return {$majorca: "Map", v: await Promise.all([...v].map(async ([k, x]) => [await encode(k), await encode(x)]))};
+		}
+		if (v instanceof Set) return {$majorca: "Set", v: await Promise.all([...v].map(encode))};
+		if (Array.isArray(v)) return Promise.all(v.map(encode));
+		const o = {};
+		for (const k of Object.keys(v)) o[k] = await encode(v[k]);
+		return o;
+	};
+	const decode = (v) => {
+		if (v === null || typeof v !== "object") return v;
+		if (Array.isArray(v)) return v.map(decode);
+		switch (v.$majorca) {
+		case undefined: {
+			const o = {};
+			for (const k of Object.keys(v)) o[k] = decode(v[k]);
+			return o;
+		}
+		case "Date": return new Date(v.v);
+		case "Blob": return new Blob([fromBase64(v.v)], {type: v.type});
+		case "File": return new File([fromBase64(v.v)], v.name, {type: v.type});
+		case "ArrayBuffer": return fromBase64(v.v).buffer;
+		case "Map": return new Map(v.v.map(([k, x]) => [decode(k), decode(x)]));
+		case "Set": return new Set(v.v.map(decode));
+		default: return new globalThis[v.$majorca](fromBase64(v.v).buffer);
+		}
+	};
+	return {
+		async dump() {
+			const localStorage = {};
+			for (let i = 0; i < window.localStorage.length; i++) {
+				const k = window.localStorage.key(i);
+				localStorage[k] = window.localStorage.getItem(k);
+			}
+			const indexedDB = [];
+			for (const info of await window.indexedDB.databases()) {
+				const db = await req(window.indexedDB.open(info.name));
+				const stores = [];
+				for (const name of db.objectStoreNames) {
+					const store = db.transaction(name).objectStore(name);
+					const indexes = [...store.indexNames].map((n) => {
+						const i = store.index(n);
+						return {name: n, keyPath: i.keyPath, unique: i.unique, multiEntry: i.multiEntry};
+					});
+					const [keys, values] = await Promise.all([req(store.getAllKeys()), req(store.getAll())]);
+					const records = [];
+					for (let i = 0; i < keys.length; i++) records.push([await encode(keys[i]), await encode(values[i])]);
+					stores.push({name, keyPath: store.keyPath, autoIncrement: store.autoIncrement, indexes, records});
+				}
+				indexedDB.push({name: info.name, version: db.version, stores});
+				db.close();
+			}
+			return {localStorage, indexedDB};
+		},
+		async restore(data) {
+			window.localStorage.clear();
+			for (const [k, v] of Object.entries(data.localStorage || {})) window.localStorage.setItem(k, v);
+			for (const d of data.indexedDB || []) {
+				await req(window.indexedDB.deleteDatabase(d.name));
+				const open = window.indexedDB.open(d.name, d.version);
+				open.onupgradeneeded = () => {
+					for (const s of d.stores) {
+						const store = open.result.createObjectStore(s.name, {keyPath: s.keyPath, autoIncrement: s.autoIncrement});
+						for (const i of s.indexes) store.createIndex(i.name, i.keyPath, {unique: i.unique, multiEntry: i.multiEntry});
+					}
+				};
+				const db = await req(open);
+				if (d.stores.length > 0) {
+					const tx = db.transaction(d.stores.map((s) => s.name), "readwrite");
+					for (const s of d.stores) {
+						const store = tx.objectStore(s.name);
+						for (const [k, v] of s.records) {
+							if (s.keyPath === null) store.put(decode(v), decode(k));
+							else store.put(decode(v));
+						}
+					}
+					await new Promise((ok, fail) => {
+						tx.oncomplete = ok;
+						tx.onerror = () => fail(tx.error);
+					});
+				}
+				db.close();
+			}
+		},
+	};
+})()`
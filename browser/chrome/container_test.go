@@ -0,0 +1,105 @@
+package chrome_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grngxd/majorca/browser/chrome"
+	"github.com/grngxd/majorca/internal/cdptest"
+)
+
+func TestContainerSaveRestore(t *testing.T) {
+	// Attached browsers have no profile, so containers are saved relative
+	// to the working directory.
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+
+	var mu sync.Mutex
+	var restored []string
+	var setCookies json.RawMessage
+	d := cdptest.New(t, func(target, method string, params json.RawMessage) interface{} {
+		switch method {
+		case "Target.createBrowserContext":
+			return map[string]string{"browserContextId": "work-ctx"}
+		case "Storage.getCookies":
+			return map[string]interface{}{"cookies": []map[string]interface{}{{"name": "sid", "value": "1", "domain": "example.com"}}}
+		case "Storage.setCookies":
+			mu.Lock()
+			setCookies = params
+			mu.Unlock()
+		case "Target.getTargets":
+			return map[string]interface{}{"targetInfos": []map[string]string{
+				{"type": "page", "url": "https://example.com/app", "browserContextId": "work-ctx"},
+				{"type": "page", "url": "https://other.com/", "browserContextId": ""},
+			}}
+		case "Page.navigate":
+			return map[string]string{"frameId": "frame"}
+		case "Runtime.evaluate":
+			var p struct{ Expression string }
+			json.Unmarshal(params, &p)
+			switch {
+			case strings.HasSuffix(p.Expression, ".dump()"):
+				return map[string]interface{}{"result": map[string]interface{}{
+					"value": map[string]interface{}{"localStorage": map[string]string{"token": "abc"}, "indexedDB": []interface{}{}},
+				}}
+			case strings.Contains(p.Expression, ".restore("):
+				mu.Lock()
+				restored = append(restored, p.Expression)
+				mu.Unlock()
+			}
+		}
+		return nil
+	})
+	c, err := chrome.Attach(d.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Kill()
+
+	ct, err := c.Container("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ct.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join("majorca-containers", "work.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var state struct {
+		Cookies []map[string]interface{}
+		Origins map[string]struct {
+			LocalStorage map[string]string
+		}
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Cookies) != 1 || state.Cookies[0]["name"] != "sid" {
+		t.Errorf("saved cookies = %v", state.Cookies)
+	}
+	if len(state.Origins) != 1 || state.Origins["https://example.com"].LocalStorage["token"] != "abc" {
+		t.Errorf("saved origins = %+v, want the localStorage of https://example.com only", state.Origins)
+	}
+
+	if err := ct.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Container("work"); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.Contains(string(setCookies), `"sid"`) {
+		t.Errorf("restored cookies = %s", setCookies)
+	}
+	if len(restored) != 1 || !strings.Contains(restored[0], `"token":"abc"`) {
+		t.Errorf("restore scripts = %q", restored)
+	}
+}
@@ -0,0 +1,69 @@
+package chrome
+
+// deterministicFlags make rasterization independent of the host's GPU,
+// display and font configuration.
+var deterministicFlags = []string{
+	"--font-render-hinting=none",
+	"--disable-font-subpixel-positioning",
+	"--disable-lcd-text",
+	"--force-color-profile=srgb",
+	"--force-device-scale-factor=1",
+	"--hide-scrollbars",
+	"--lang=en-US",
+	"--run-all-compositor-stages-before-draw",
+}
+
+// deterministicScript freezes the clock and seeds Math.random, and disables
+// animations, transitions and the blinking caret once the document exists.
+const deterministicScript = `(() => {
+	const fixed = Date.UTC(2000, 0, 1);
+	const RealDate = Date;
+	class FrozenDate extends RealDate {
+		constructor(...args) {
+			if (args.length === 0) super(fixed);
+			else super(...args);
+		}
+		static now() { return fixed; }
+	}
+	window.Date = FrozenDate;
+
+	let seed = 0x2f6b6c3d;
+	Math.random = () => {
+		seed = (seed + 0x6d2b79f5) | 0;
+		let t = Math.imul(seed ^ (seed >>> 15), 1 | seed);
+		t = (t + Math.imul(t ^ (t >>> 7), 61 | t)) ^ t;
+		return ((t ^ (t >>> 14)) >>> 0) / 4294967296;
+	};
+
+	const css = "*, *::before, *::after {" +
+		"animation: none !important; transition: none !important;" +
+		"caret-color: transparent !important; scroll-behavior: auto !important; }";
+	const add = () => {
+		const style = document.createElement("style");
+		style.textContent = css;
+		(document.head || document.documentElement).appendChild(style);
+	};
+	if (document.documentElement) add();
+	else document.addEventListener("DOMContentLoaded", add, { once: true });
+})()`
+
+// applyDeterministic pins the timezone and locale of c's page. Emulation
+// overrides belong to the connection, so this runs again after a reconnect.
+func (c *Chrome) applyDeterministic() error {
+	if _, err := c.Send("Emulation.setTimezoneOverride", map[string]interface{}{"timezoneId": "UTC"}); err != nil {
+		return err
+	}
+	if _, err := c.Send("Emulation.setLocaleOverride", map[string]interface{}{"locale": "en-US"}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// setupDeterministic applies the emulation overrides and registers
+// deterministicScript as an init script, which survives navigations.
+func (c *Chrome) setupDeterministic() error {
+	if err := c.applyDeterministic(); err != nil {
+		return err
+	}
+	return c.AddInitScript(deterministicScript)
+}
@@ -0,0 +1,89 @@
+package chrome
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/grngxd/majorca/browser"
+	"github.com/grngxd/majorca/browser/dialog"
+)
+
+// FileChooser describes an <input type="file"> the page tried to open.
+type FileChooser struct {
+	Multiple      bool
+	FrameID       string
+	BackendNodeID int
+}
+
+// OpenFileDialog shows the native open dialog and returns the chosen paths.
+// Unlike a file input it gives Go the full paths. It returns
+// dialog.ErrCanceled if the user closes the dialog.
+func (c *Chrome) OpenFileDialog(opts dialog.Options) ([]string, error) {
+	return dialog.Open(opts)
+}
+
+// SaveFileDialog shows the native save dialog and returns the chosen path.
+func (c *Chrome) SaveFileDialog(opts dialog.Options) (string, error) {
+	return dialog.Save(opts)
+}
+
+// HandleFileChooser stops the browser from showing its own dialog for file
+// inputs and calls handler instead. The returned paths are set on the input
+// as if the user had picked them; returning no paths leaves it unchanged.
+// The returned function restores the browser's dialog.
+func (c *Chrome) HandleFileChooser(handler func(FileChooser) ([]string, error)) (func(), error) {
+	if _, err := c.Send("Page.enable", nil); err != nil {
+		return nil, err
+	}
+	if _, err := c.Send("Page.setInterceptFileChooserDialog", map[string]interface{}{"enabled": true}); err != nil {
+		return nil, err
+	}
+
+	off := c.On("Page.fileChooserOpened", func(e browser.Event) {
+		var p struct {
+			FrameID       string `json:"frameId"`
+			Mode          string `json:"mode"`
+			BackendNodeID int    `json:"backendNodeId"`
+		}
+		if json.Unmarshal(e.Params, &p) != nil {
+			return
+		}
+		fc := FileChooser{
+			Multiple:      p.Mode == "selectMultiple",
+			FrameID:       p.FrameID,
+			BackendNodeID: p.BackendNodeID,
+		}
+		// The handler usually blocks on the user.
+		go func() {
+			paths, err := handler(fc)
+			if err != nil {
+				if !errors.Is(err, dialog.ErrCanceled) {
+					c.Logger().Error("file chooser handler failed", "error", err)
+				}
+				return
+			}
+			if len(paths) == 0 {
+				return
+			}
+			if _, err := c.Send("DOM.setFileInputFiles", map[string]interface{}{
+				"files":         paths,
+				"backendNodeId": fc.BackendNodeID,
+			}); err != nil {
+				c.Logger().Error("failed to set chosen files", "error", err)
+			}
+		}()
+	})
+
+	return func() {
+		off()
+		c.Send("Page.setInterceptFileChooserDialog", map[string]interface{}{"enabled": false})
+	}, nil
+}
+
+// UseNativeFileChooser answers the page's file inputs with the native open
+// dialog, so the app sees the same dialog as the rest of the system.
+func (c *Chrome) UseNativeFileChooser() (func(), error) {
+	return c.HandleFileChooser(func(fc FileChooser) ([]string, error) {
+		return dialog.Open(dialog.Options{Multiple: fc.Multiple})
+	})
+}
@@ -0,0 +1,224 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// selectorPoll is how often WaitForSelector checks the page.
+const selectorPoll = 100 * time.Millisecond
+
+// Node is a handle to a DOM element in the current document. It stays valid
+// until the page navigates or Release is called.
+type Node struct {
+	c        *Chrome
+	objectID string
+}
+
+// QuerySelector returns the first element matching selector, or nil if
+// there is none.
+func (c *Chrome) QuerySelector(selector string) (*Node, error) {
+	raw, err := c.Send("Runtime.evaluate", map[string]interface{}{
+		"expression": fmt.Sprintf("document.querySelector(%s)", quote(selector)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		Result struct {
+			ObjectID string `json:"objectId"`
+		} `json:"result"`
+		ExceptionDetails *exceptionDetails `json:"exceptionDetails"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if res.ExceptionDetails != nil {
+		return nil, res.ExceptionDetails.jsError()
+	}
+	if res.Result.ObjectID == "" {
+		return nil, nil
+	}
+	return &Node{c: c, objectID: res.Result.ObjectID}, nil
+}
+
+// WaitForSelector waits until an element matching selector exists and
+// returns it. Navigations while waiting are tolerated. A zero timeout uses
+// the command timeout, or waits forever without one.
+func (c *Chrome) WaitForSelector(selector string, timeout time.Duration) (*Node, error) {
+	if timeout == 0 {
+		timeout = c.Timeout
+	}
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	ticker := time.NewTicker(selectorPoll)
+	defer ticker.Stop()
+
+	for {
+		n, err := c.QuerySelector(selector)
+		if n != nil {
+			return n, nil
+		}
+		if err != nil && !isContextLost(err) {
+			return nil, err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			return nil, fmt.Errorf("waiting for %s: %w", selector, browser.ErrTimeout)
+		case <-c.Done():
+			return nil, browser.ErrConnectionClosed
+		}
+	}
+}
+
+// GetAttribute returns the named attribute of the first element matching
+// selector. ok is false if the element has no such attribute.
+func (c *Chrome) GetAttribute(selector, name string) (value string, ok bool, err error) {
+	n, err := c.mustQuery(selector)
+	if err != nil {
+		return "", false, err
+	}
+	defer n.Release()
+	return n.Attribute(name)
+}
+
+// InnerText returns the rendered text of the first element matching
+// selector.
+func (c *Chrome) InnerText(selector string) (string, error) {
+	n, err := c.mustQuery(selector)
+	if err != nil {
+		return "", err
+	}
+	defer n.Release()
+	return n.InnerText()
+}
+
+// SetValue sets the value of the first input, textarea or select matching
+// selector and fires input and change events.
+func (c *Chrome) SetValue(selector, value string) error {
+	n, err := c.mustQuery(selector)
+	if err != nil {
+		return err
+	}
+	defer n.Release()
+	return n.SetValue(value)
+}
+
+func (c *Chrome) mustQuery(selector string) (*Node, error) {
+	n, err := c.QuerySelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	if n == nil {
+		return nil, fmt.Errorf("no element matches %s", selector)
+	}
+	return n, nil
+}
+
+// Attribute returns the named attribute. ok is false if the element has no
+// such attribute.
+func (n *Node) Attribute(name string) (value string, ok bool, err error) {
+	var v *string
+	if err := n.call(`function(name) { return this.getAttribute(name); }`, &v, name); err != nil {
+		return "", false, err
+	}
+	if v == nil {
+		return "", false, nil
+	}
+	return *v, true, nil
+}
+
+// InnerText returns the element's rendered text.
+func (n *Node) InnerText() (string, error) {
+	var s string
+	err := n.call(`function() { return this.innerText; }`, &s)
+	return s, err
+}
+
+// SetValue sets the element's value the way typing would, firing input and
+// change events.
+func (n *Node) SetValue(value string) error {
+	return n.call(`function(value) {
+		const desc = Object.getOwnPropertyDescriptor(Object.getPrototypeOf(this), "value");
+		if (desc && desc.set) desc.set.call(this, value); else this.value = value;
+		this.dispatchEvent(new Event("input", {bubbles: true}));
+		this.dispatchEvent(new Event("change", {bubbles: true}));
+	}`, nil, value)
+}
+
+// Click scrolls the element into view and clicks its centre.
+func (n *Node) Click() error {
+	var box *struct {
+		X, Y float64
+	}
+	err := n.call(`function() {
+		this.scrollIntoView({block: "center", inline: "center"});
+		const r = this.getBoundingClientRect();
+		if (r.width === 0 && r.height === 0) return null;
+		return {X: r.left + r.width / 2, Y: r.top + r.height / 2};
+	}`, &box)
+	if err != nil {
+		return err
+	}
+	if box == nil {
+		return fmt.Errorf("element is not visible")
+	}
+	return n.c.Click(box.X, box.Y)
+}
+
+// Release frees the handle. Using the Node afterwards fails.
+func (n *Node) Release() error {
+	_, err := n.c.Send("Runtime.releaseObject", map[string]interface{}{"objectId": n.objectID})
+	return err
+}
+
+// call runs fn with the element as this and unmarshals its result into v,
+// unless v is nil.
+func (n *Node) call(fn string, v interface{}, args ...string) error {
+	arguments := make([]map[string]interface{}, len(args))
+	for i, a := range args {
+		arguments[i] = map[string]interface{}{"value": a}
+	}
+	raw, err := n.c.Send("Runtime.callFunctionOn", map[string]interface{}{
+		"functionDeclaration": fn,
+		"objectId":            n.objectID,
+		"arguments":           arguments,
+		"returnByValue":       true,
+		"awaitPromise":        true,
+	})
+	if err != nil {
+		return err
+	}
+
+	var res struct {
+		Result struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *exceptionDetails `json:"exceptionDetails"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if res.ExceptionDetails != nil {
+		return res.ExceptionDetails.jsError()
+	}
+	if v == nil {
+		return nil
+	}
+	if len(res.Result.Value) == 0 {
+		res.Result.Value = json.RawMessage("null")
+	}
+	if err := json.Unmarshal(res.Result.Value, v); err != nil {
+		return fmt.Errorf("failed to unmarshal value: %w", err)
+	}
+	return nil
+}
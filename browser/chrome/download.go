@@ -0,0 +1,188 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// DownloadState is the progress state reported by Chrome.
+type DownloadState string
+
+const (
+	DownloadInProgress DownloadState = "inProgress"
+	DownloadCompleted  DownloadState = "completed"
+	DownloadCanceled   DownloadState = "canceled"
+)
+
+// Download describes a file download started by the page.
+type Download struct {
+	GUID          string
+	URL           string
+	Filename      string // Suggested by the server or page
+	Path          string // Final location, set once completed
+	ReceivedBytes int64
+	TotalBytes    int64 // Zero when unknown
+	State         DownloadState
+}
+
+// DownloadOptions configures HandleDownloads.
+type DownloadOptions struct {
+	// Dir receives downloaded files. It is created if needed.
+	Dir string
+	// Accept decides whether a download may proceed; nil accepts all.
+	Accept func(Download) bool
+	// Progress is called on every progress update, including the final
+	// completed or canceled one.
+	Progress func(Download)
+}
+
+type downloadTracker struct {
+	mu      sync.Mutex
+	opts    *DownloadOptions
+	active  map[string]*Download
+	enabled bool
+}
+
+// HandleDownloads saves the page's downloads to opts.Dir instead of the
+// browser's default location, which app windows often don't have. Accept
+// and Progress run on the event goroutine and must not block. Calling it
+// again replaces the options.
+func (c *Chrome) HandleDownloads(opts DownloadOptions) error {
+	dir, err := filepath.Abs(opts.Dir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create download directory: %w", err)
+	}
+	opts.Dir = dir
+
+	c.downloads.mu.Lock()
+	c.downloads.opts = &opts
+	if c.downloads.active == nil {
+		c.downloads.active = make(map[string]*Download)
+	}
+	enabled := c.downloads.enabled
+	c.downloads.enabled = true
+	c.downloads.mu.Unlock()
+
+	if !enabled {
+		c.On("Browser.downloadWillBegin", c.onDownloadWillBegin)
+		c.On("Browser.downloadProgress", c.onDownloadProgress)
+	}
+	return c.applyDownloads()
+}
+
+// applyDownloads sets the download behavior of c's connection. Events are
+// only delivered to the connection that enabled them, so this runs again
+// after a reconnect.
+func (c *Chrome) applyDownloads() error {
+	c.downloads.mu.Lock()
+	opts := c.downloads.opts
+	c.downloads.mu.Unlock()
+	if opts == nil {
+		return nil
+	}
+
+	// Files are saved under their GUID and renamed on completion, so the
+	// final path is known and existing files aren't overwritten.
+	_, err := c.Send("Browser.setDownloadBehavior", map[string]interface{}{
+		"behavior":      "allowAndName",
+		"downloadPath":  opts.Dir,
+		"eventsEnabled": true,
+	})
+	return err
+}
+
+func (c *Chrome) onDownloadWillBegin(e browser.Event) {
+	var p struct {
+		GUID              string `json:"guid"`
+		URL               string `json:"url"`
+		SuggestedFilename string `json:"suggestedFilename"`
+	}
+	if json.Unmarshal(e.Params, &p) != nil {
+		return
+	}
+	d := &Download{GUID: p.GUID, URL: p.URL, Filename: p.SuggestedFilename, State: DownloadInProgress}
+
+	c.downloads.mu.Lock()
+	opts := c.downloads.opts
+	c.downloads.active[p.GUID] = d
+	c.downloads.mu.Unlock()
+
+	if opts.Accept != nil && !opts.Accept(*d) {
+		go func() {
+			if _, err := c.Send("Browser.cancelDownload", map[string]interface{}{"guid": p.GUID}); err != nil {
+				c.Logger().Error("failed to cancel download", "url", p.URL, "error", err)
+			}
+		}()
+	}
+}
+
+func (c *Chrome) onDownloadProgress(e browser.Event) {
+	var p struct {
+		GUID          string        `json:"guid"`
+		TotalBytes    float64       `json:"totalBytes"`
+		ReceivedBytes float64       `json:"receivedBytes"`
+		State         DownloadState `json:"state"`
+	}
+	if json.Unmarshal(e.Params, &p) != nil {
+		return
+	}
+
+	c.downloads.mu.Lock()
+	opts := c.downloads.opts
+	d, ok := c.downloads.active[p.GUID]
+	if ok && p.State != DownloadInProgress {
+		delete(c.downloads.active, p.GUID)
+	}
+	c.downloads.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	d.TotalBytes = int64(p.TotalBytes)
+	d.ReceivedBytes = int64(p.ReceivedBytes)
+	d.State = p.State
+	if d.State == DownloadCompleted {
+		path, err := finishDownload(opts.Dir, d)
+		if err != nil {
+			c.Logger().Error("failed to rename download", "url", d.URL, "error", err)
+		}
+		d.Path = path
+	}
+
+	if opts.Progress != nil {
+		opts.Progress(*d)
+	}
+}
+
+// finishDownload moves a completed download from its GUID to its suggested
+// file name, adding a counter if the name is taken.
+func finishDownload(dir string, d *Download) (string, error) {
+	tmp := filepath.Join(dir, d.GUID)
+	name := filepath.Base(d.Filename)
+	if name == "." || name == string(filepath.Separator) || name == "" {
+		name = "download"
+	}
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	path := filepath.Join(dir, name)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", base, i, ext))
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return tmp, err
+	}
+	return path, nil
+}
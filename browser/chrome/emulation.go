@@ -0,0 +1,71 @@
+package chrome
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type deviceMetrics struct {
+	width, height int
+	dpr           float64
+	mobile        bool
+}
+
+// SetZoom scales the page's UI by factor, like the browser's zoom: 1.25
+// makes everything 25% larger and reflows the layout to fit. The zoom is
+// kept across navigations; 1 resets it.
+func (c *Chrome) SetZoom(factor float64) error {
+	if factor <= 0 {
+		return fmt.Errorf("invalid zoom factor %v", factor)
+	}
+	zoom := ""
+	if factor != 1 {
+		zoom = strconv.FormatFloat(factor, 'f', -1, 64)
+	}
+	c.appearance.mu.Lock()
+	c.appearance.zoom = zoom
+	c.appearance.mu.Unlock()
+	return c.applyAppearance()
+}
+
+// SetDeviceMetrics emulates a screen of width x height CSS pixels with the
+// given device pixel ratio, e.g. to preview a responsive layout. mobile
+// also enables touch events and the mobile viewport meta tag. Zero width
+// and height keep the window size.
+func (c *Chrome) SetDeviceMetrics(width, height int, dpr float64, mobile bool) error {
+	m := deviceMetrics{width: width, height: height, dpr: dpr, mobile: mobile}
+	if err := c.applyDeviceMetrics(m); err != nil {
+		return err
+	}
+	c.Lock()
+	c.metrics = &m
+	c.Unlock()
+	return nil
+}
+
+// ClearDeviceMetrics undoes SetDeviceMetrics.
+func (c *Chrome) ClearDeviceMetrics() error {
+	c.Lock()
+	c.metrics = nil
+	c.Unlock()
+	if _, err := c.Send("Emulation.clearDeviceMetricsOverride", nil); err != nil {
+		return err
+	}
+	_, err := c.Send("Emulation.setTouchEmulationEnabled", map[string]interface{}{"enabled": false})
+	return err
+}
+
+// applyDeviceMetrics sends the overrides, which belong to the connection
+// and are re-applied after a reconnect.
+func (c *Chrome) applyDeviceMetrics(m deviceMetrics) error {
+	if _, err := c.Send("Emulation.setDeviceMetricsOverride", map[string]interface{}{
+		"width":             m.width,
+		"height":            m.height,
+		"deviceScaleFactor": m.dpr,
+		"mobile":            m.mobile,
+	}); err != nil {
+		return err
+	}
+	_, err := c.Send("Emulation.setTouchEmulationEnabled", map[string]interface{}{"enabled": m.mobile})
+	return err
+}
@@ -0,0 +1,99 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// Link is a hyperlink collected by ExtractLinks.
+type Link struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// ExtractTable returns the text of every cell of the first table matching
+// selector, row by row. Header cells are included.
+func (c *Chrome) ExtractTable(selector string) ([][]string, error) {
+	var rows [][]string
+	err := c.evalJSON(fmt.Sprintf(`(() => {
+		const table = document.querySelector(%s);
+		if (!table) return null;
+		return Array.from(table.rows, (row) =>
+			Array.from(row.cells, (cell) => cell.innerText.trim()));
+	})()`, quote(selector)), &rows)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		return nil, fmt.Errorf("no table matches %s", selector)
+	}
+	return rows, nil
+}
+
+// ExtractLinks returns all links on the page whose absolute URL matches the
+// regular expression pattern. An empty pattern matches every link.
+func (c *Chrome) ExtractLinks(pattern string) ([]Link, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid link pattern: %w", err)
+	}
+
+	var links []Link
+	err = c.evalJSON(`Array.from(document.links, (a) => ({text: a.innerText.trim(), url: a.href}))`, &links)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := links[:0]
+	for _, l := range links {
+		if re.MatchString(l.URL) {
+			matched = append(matched, l)
+		}
+	}
+	return matched, nil
+}
+
+// ExtractJSON builds one object per element matching selector and
+// unmarshals the list into v, which should point to a slice. Each mapping
+// entry names an output field and how to read it relative to the element:
+//
+//	"h2"        text of the first h2 inside the element
+//	"a@href"    href attribute (property) of the first a inside the element
+//	"@data-id"  attribute of the element itself
+//	""          text of the element itself
+//
+// Missing elements produce null fields.
+func (c *Chrome) ExtractJSON(selector string, mapping map[string]string, v interface{}) error {
+	m, err := json.Marshal(mapping)
+	if err != nil {
+		return fmt.Errorf("failed to marshal mapping: %w", err)
+	}
+
+	var raw json.RawMessage
+	err = c.evalJSON(fmt.Sprintf(`(() => {
+		const mapping = %s;
+		const read = (root, spec) => {
+			const at = spec.lastIndexOf("@");
+			const sel = at < 0 ? spec : spec.slice(0, at);
+			const attr = at < 0 ? "" : spec.slice(at + 1);
+			const el = sel ? root.querySelector(sel) : root;
+			if (!el) return null;
+			if (!attr) return el.innerText.trim();
+			return attr in el ? el[attr] : el.getAttribute(attr);
+		};
+		return Array.from(document.querySelectorAll(%s), (root) => {
+			const out = {};
+			for (const [key, spec] of Object.entries(mapping)) out[key] = read(root, spec);
+			return out;
+		});
+	})()`, m, quote(selector)), &raw)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("failed to unmarshal extracted data: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package chrome
+
+import (
+	"fmt"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// fontScript adds the font style sheet to each document as early as
+// possible.
+const fontScript = `(() => {
+	const add = () => {
+		const style = document.createElement("style");
+		style.textContent = %s;
+		(document.head || document.documentElement).appendChild(style);
+	};
+	if (document.documentElement) add();
+	else document.addEventListener("DOMContentLoaded", add, { once: true });
+})()`
+
+// applyFonts blocks remote fonts for c's connection. Like other Network
+// settings it is lost on reconnect.
+func (c *Chrome) applyFonts() error {
+	if !c.blockFonts {
+		return nil
+	}
+	if _, err := c.Send("Network.enable", nil); err != nil {
+		return err
+	}
+	_, err := c.Send("Network.setBlockedURLs", map[string]interface{}{"urls": browser.RemoteFontPatterns})
+	return err
+}
+
+// setupFonts applies the font options of New to c.
+func (c *Chrome) setupFonts() error {
+	if err := c.applyFonts(); err != nil {
+		return err
+	}
+	if c.fontCSS == "" {
+		return nil
+	}
+	return c.AddInitScript(fmt.Sprintf(fontScript, quote(c.fontCSS)))
+}
@@ -0,0 +1,197 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// submitGrace is how long Submit waits for a navigation to start before it
+// assumes the page handled the submission itself.
+const submitGrace = time.Second
+
+// findField locates a form control by CSS selector, label text, name,
+// placeholder or aria-label, in that order.
+const findField = `(key) => {
+	const controls = "input, textarea, select, [contenteditable]";
+	try {
+		const el = document.querySelector(key);
+		if (el) return el;
+	} catch (e) {}
+	const norm = (s) => (s || "").replace(/\s+/g, " ").trim().replace(/[:*]$/, "").trim().toLowerCase();
+	const want = norm(key);
+	for (const label of document.querySelectorAll("label")) {
+		if (norm(label.innerText) !== want) continue;
+		const el = label.control || label.querySelector(controls);
+		if (el) return el;
+	}
+	for (const el of document.querySelectorAll(controls)) {
+		if (el.name === key || norm(el.placeholder) === want || norm(el.getAttribute("aria-label")) === want) return el;
+	}
+	return null;
+}`
+
+// Fill sets form fields to the given values. Keys are CSS selectors or the
+// text of a field's label, its name, placeholder or aria-label. Values are
+// set the way typing would, firing input and change events, so frameworks
+// that track state through them see the change. Checkboxes and radio buttons
+// take "true" or "false", selects the value or text of an option.
+func (c *Chrome) Fill(fields map[string]string) error {
+	values, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal fields: %w", err)
+	}
+
+	var missing []string
+	err = c.evalJSON(fmt.Sprintf(`(() => {
+		const find = %s;
+		const setValue = (el, value) => {
+			const proto = Object.getPrototypeOf(el);
+			const desc = Object.getOwnPropertyDescriptor(proto, "value");
+			if (desc && desc.set) desc.set.call(el, value); else el.value = value;
+		};
+		const missing = [];
+		for (const [key, value] of Object.entries(%s)) {
+			const el = find(key);
+			if (!el) {
+				missing.push(key);
+				continue;
+			}
+			el.focus();
+			if (el.type === "checkbox" || el.type === "radio") {
+				const want = value === "true" || value === "on" || value === el.value;
+				if (el.checked !== want) el.click();
+				continue;
+			}
+			if (el.tagName === "SELECT") {
+				const option = Array.from(el.options).find((o) => o.value === value || o.text.trim() === value);
+				if (!option) {
+					missing.push(key + " = " + value);
+					continue;
+				}
+				setValue(el, option.value);
+			} else if (el.isContentEditable) {
+				el.textContent = value;
+			} else {
+				setValue(el, value);
+			}
+			el.dispatchEvent(new Event("input", {bubbles: true}));
+			el.dispatchEvent(new Event("change", {bubbles: true}));
+		}
+		return missing;
+	})()`, findField, values), &missing)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("no form field matches %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Submit submits the form matching formSelector as if the user clicked its
+// submit button, running validation and submit handlers. If the submission
+// navigates, Submit waits for the new page like Load does, or for its load
+// event without WithWaitUntil. Submissions handled by the page's own scripts
+// return as soon as the grace period for a navigation to start has passed.
+func (c *Chrome) Submit(formSelector string) error {
+	if _, err := c.Send("Page.enable", nil); err != nil {
+		return err
+	}
+	if _, err := c.Send("Page.setLifecycleEventsEnabled", map[string]interface{}{"enabled": true}); err != nil {
+		return err
+	}
+
+	until := lifecycleNames[c.waitUntil]
+	if until == "" {
+		until = "load"
+	}
+	c.Lock()
+	main := c.mainFrame
+	c.Unlock()
+
+	var mu sync.Mutex
+	started, done := false, false
+	notify := make(chan struct{}, 1)
+	poke := func() {
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+	}
+	offStart := c.On("Page.frameStartedLoading", func(e browser.Event) {
+		var p struct {
+			FrameID string `json:"frameId"`
+		}
+		if json.Unmarshal(e.Params, &p) != nil || p.FrameID != main {
+			return
+		}
+		mu.Lock()
+		started = true
+		mu.Unlock()
+		poke()
+	})
+	defer offStart()
+	offLife := c.On("Page.lifecycleEvent", func(e browser.Event) {
+		var p struct {
+			FrameID string `json:"frameId"`
+			Name    string `json:"name"`
+		}
+		if json.Unmarshal(e.Params, &p) != nil || p.FrameID != main || p.Name != until {
+			return
+		}
+		mu.Lock()
+		done = started
+		mu.Unlock()
+		poke()
+	})
+	defer offLife()
+
+	ok, err := c.evalBool(fmt.Sprintf(`(() => {
+		const form = document.querySelector(%s);
+		if (!(form instanceof HTMLFormElement)) return false;
+		if (form.requestSubmit) form.requestSubmit(); else form.submit();
+		return true;
+	})()`, quote(formSelector)))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("no form matches %s", formSelector)
+	}
+
+	grace := time.NewTimer(submitGrace)
+	defer grace.Stop()
+	var timeout <-chan time.Time
+	if c.Timeout > 0 {
+		timer := time.NewTimer(c.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	for {
+		mu.Lock()
+		s, d := started, done
+		mu.Unlock()
+		if d {
+			return nil
+		}
+
+		select {
+		case <-notify:
+		case <-grace.C:
+			if !s {
+				return nil
+			}
+		case <-timeout:
+			return fmt.Errorf("waiting for %s after submitting %s: %w", until, formSelector, browser.ErrTimeout)
+		case <-c.Done():
+			return browser.ErrConnectionClosed
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package chrome
+
+// SetExtraHeaders sends headers with every request of the page, e.g. an
+// Authorization header for the app's backend. Passing nil removes them.
+func (c *Chrome) SetExtraHeaders(headers map[string]string) error {
+	c.Lock()
+	c.extraHeaders = headers
+	c.Unlock()
+	return c.applyHeaders()
+}
+
+// SetUserAgent replaces the browser's User-Agent header and
+// navigator.userAgent. An empty ua restores the default.
+func (c *Chrome) SetUserAgent(ua string) error {
+	c.Lock()
+	c.userAgent = ua
+	c.Unlock()
+	_, err := c.Send("Emulation.setUserAgentOverride", map[string]interface{}{"userAgent": ua})
+	return err
+}
+
+// applyHeaders sends the extra headers to c's connection. Network settings
+// are lost on reconnect, so rebind calls it again along with the user agent.
+func (c *Chrome) applyHeaders() error {
+	c.Lock()
+	headers := c.extraHeaders
+	c.Unlock()
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	if _, err := c.Send("Network.enable", nil); err != nil {
+		return err
+	}
+	_, err := c.Send("Network.setExtraHTTPHeaders", map[string]interface{}{"headers": headers})
+	return err
+}
@@ -0,0 +1,56 @@
+package chrome
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrNoHistory is returned by GoBack and GoForward at either end of the
+// session history.
+var ErrNoHistory = errors.New("no history entry to go to")
+
+// Reload reloads the current page. ignoreCache bypasses the HTTP cache,
+// like a hard refresh.
+func (c *Chrome) Reload(ignoreCache bool) error {
+	_, err := c.Send("Page.reload", map[string]interface{}{"ignoreCache": ignoreCache})
+	return err
+}
+
+// GoBack navigates to the previous page in the session history.
+func (c *Chrome) GoBack() error {
+	return c.goHistory(-1)
+}
+
+// GoForward navigates to the next page in the session history.
+func (c *Chrome) GoForward() error {
+	return c.goHistory(1)
+}
+
+// StopLoading cancels the navigation or resource loading in progress.
+func (c *Chrome) StopLoading() error {
+	_, err := c.Send("Page.stopLoading", nil)
+	return err
+}
+
+func (c *Chrome) goHistory(delta int) error {
+	raw, err := c.Send("Page.getNavigationHistory", nil)
+	if err != nil {
+		return err
+	}
+	var history struct {
+		CurrentIndex int `json:"currentIndex"`
+		Entries      []struct {
+			ID int `json:"id"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(raw, &history); err != nil {
+		return fmt.Errorf("failed to unmarshal navigation history: %w", err)
+	}
+	i := history.CurrentIndex + delta
+	if i < 0 || i >= len(history.Entries) {
+		return ErrNoHistory
+	}
+	_, err = c.Send("Page.navigateToHistoryEntry", map[string]interface{}{"entryId": history.Entries[i].ID})
+	return err
+}
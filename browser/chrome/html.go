@@ -0,0 +1,60 @@
+package chrome
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// maxDataURL is the largest encoded data URL LoadHTML navigates to. Chrome
+// caps URLs at 2MB, so anything bigger is written into a blank document.
+const maxDataURL = 1 << 20
+
+// blankPage is shown until the app loads its own content. Without a title
+// app windows would show the data URL instead.
+func blankPage(title string) string {
+	if title == "" {
+		title = "about:blank"
+	}
+	return "<!DOCTYPE html><html><head><title>" + html.EscapeString(title) + "</title></head><body></body></html>"
+}
+
+// dataURL encodes html as a UTF-8 data URL. Base64 avoids having to escape
+// '#', '%' and non-ASCII characters.
+func dataURL(html string) string {
+	return "data:text/html;charset=utf-8;base64," + base64.StdEncoding.EncodeToString([]byte(html))
+}
+
+// LoadHTML renders an HTML document directly, without a server.
+func (c *Chrome) LoadHTML(doc string) error {
+	if u := dataURL(doc); len(u) <= maxDataURL {
+		return c.Load(u)
+	}
+
+	if err := c.LoadWait("about:blank", browser.WaitLoad); err != nil {
+		return err
+	}
+	raw, err := c.Send("Page.getFrameTree", nil)
+	if err != nil {
+		return err
+	}
+	var tree struct {
+		FrameTree struct {
+			Frame struct {
+				ID string `json:"id"`
+			} `json:"frame"`
+		} `json:"frameTree"`
+	}
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return fmt.Errorf("failed to unmarshal frame tree: %w", err)
+	}
+
+	_, err = c.Send("Page.setDocumentContent", map[string]interface{}{
+		"frameId": tree.FrameTree.Frame.ID,
+		"html":    doc,
+	})
+	return err
+}
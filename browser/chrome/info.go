@@ -0,0 +1,83 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// ProfileDir returns the profile directory of the browser process c belongs
+// to.
+func (c *Chrome) ProfileDir() string {
+	for c.parent != nil {
+		c = c.parent
+	}
+	return c.profile
+}
+
+// CommandLine returns the path and arguments the browser process was started
+// with.
+func (c *Chrome) CommandLine() []string {
+	for c.parent != nil {
+		c = c.parent
+	}
+	if c.Cmd == nil {
+		return nil
+	}
+	return append([]string(nil), c.Cmd.Args...)
+}
+
+// Product returns the browser name and version, e.g. "Chrome/120.0.6099.109".
+func (c *Chrome) Product() (string, error) {
+	raw, err := c.Send("Browser.getVersion", nil)
+	if err != nil {
+		return "", err
+	}
+	var v struct {
+		Product string `json:"product"`
+	}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return "", fmt.Errorf("failed to unmarshal version: %w", err)
+	}
+	return v.Product, nil
+}
+
+// versionInfo is the answer of the DevTools /json/version endpoint.
+type versionInfo struct {
+	Browser  string `json:"Browser"`          // e.g. "Chrome/120.0.6099.109"
+	Protocol string `json:"Protocol-Version"` // e.g. "1.3"
+}
+
+func (c *Chrome) versionInfo() (*versionInfo, error) {
+	resp, err := http.Get("http://" + c.debugAddr + "/json/version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query browser version: %w", err)
+	}
+	defer resp.Body.Close()
+	var v versionInfo
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return nil, fmt.Errorf("failed to decode browser version: %w", err)
+	}
+	return &v, nil
+}
+
+// BrowserVersion returns the version number of the running browser, e.g.
+// "120.0.6099.109".
+func (c *Chrome) BrowserVersion() (string, error) {
+	v, err := c.versionInfo()
+	if err != nil {
+		return "", err
+	}
+	return browser.ParseVersion(v.Browser), nil
+}
+
+// ProtocolVersion returns the DevTools protocol version, e.g. "1.3".
+func (c *Chrome) ProtocolVersion() (string, error) {
+	v, err := c.versionInfo()
+	if err != nil {
+		return "", err
+	}
+	return v.Protocol, nil
+}
@@ -0,0 +1,190 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// EventReady is emitted after every main-frame document was set up, i.e.
+// bindings and init scripts are in place. Subscribe with On. The page also
+// receives a "majorca:ready" event on window.
+const EventReady = "majorca.ready"
+
+func init() {
+	browser.ReplayMethods[EventReady] = true
+}
+
+// initGuard makes an init script run at most once per document, whether it
+// was injected by Chrome on document creation or re-applied by us.
+const initGuard = `(() => {
+	const done = window.__majorcaInit = window.__majorcaInit || {};
+	if (done[%d]) return;
+	done[%d] = true;
+	%s
+})()`
+
+// AddInitScript runs source in every document loaded from now on, before the
+// page's own scripts, and in the current document. The script survives
+// navigations and reconnects.
+func (c *Chrome) AddInitScript(source string) error {
+	c.Lock()
+	c.initScripts = append(c.initScripts, source)
+	script := fmt.Sprintf(initGuard, len(c.initScripts), len(c.initScripts), source)
+	c.Unlock()
+
+	if _, err := c.Send("Page.addScriptToEvaluateOnNewDocument", map[string]interface{}{"source": script}); err != nil {
+		return err
+	}
+	_, err := c.Send("Runtime.evaluate", map[string]interface{}{"expression": script})
+	return err
+}
+
+// trackNavigations watches for new main-frame documents so bindings and init
+// scripts can be re-applied to documents they did not reach on their own.
+func (c *Chrome) trackNavigations() error {
+	c.trackOnce.Do(func() {
+		c.On("Runtime.executionContextCreated", c.onContextCreated)
+		c.On("Page.frameNavigated", c.onMainFrameNavigated)
+	})
+
+	raw, err := c.Send("Page.getFrameTree", nil)
+	if err != nil {
+		return err
+	}
+	var tree struct {
+		FrameTree struct {
+			Frame struct {
+				ID string `json:"id"`
+			} `json:"frame"`
+		} `json:"frameTree"`
+	}
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return fmt.Errorf("failed to unmarshal frame tree: %w", err)
+	}
+	c.Lock()
+	c.mainFrame = tree.FrameTree.Frame.ID
+	c.Unlock()
+
+	if _, err := c.Send("Page.enable", nil); err != nil {
+		return err
+	}
+	// Inspector reports renderer crashes, see handleResponse.
+	if _, err := c.Send("Inspector.enable", nil); err != nil {
+		return err
+	}
+	_, err = c.Send("Runtime.enable", nil)
+	return err
+}
+
+// onMainFrameNavigated follows the main frame when a navigation replaces
+// it, as prerender activation and back/forward cache restores do, and makes
+// sure the committed document has every binding.
+func (c *Chrome) onMainFrameNavigated(e browser.Event) {
+	var p struct {
+		Frame struct {
+			ID       string `json:"id"`
+			ParentID string `json:"parentId"`
+		} `json:"frame"`
+	}
+	if json.Unmarshal(e.Params, &p) != nil || p.Frame.ParentID != "" {
+		return
+	}
+	c.Lock()
+	c.mainFrame = p.Frame.ID
+	c.Unlock()
+
+	go c.repairBindings()
+}
+
+// missingBindings lists the bindings the current document lacks.
+const missingBindings = `%s.filter((name) => !(typeof window[name] === "function" && window[name].majorca))`
+
+// repairBindings re-adds bindings and init scripts to the current document
+// if any binding is missing from it. Everything it applies is idempotent.
+func (c *Chrome) repairBindings() {
+	c.Lock()
+	names := make([]string, 0, len(c.Bindings))
+	for name := range c.Bindings {
+		names = append(names, name)
+	}
+	scripts := append([]string(nil), c.initScripts...)
+	c.Unlock()
+	if len(names) == 0 {
+		return
+	}
+
+	list, _ := json.Marshal(names)
+	var missing []string
+	if err := c.evalJSON(fmt.Sprintf(missingBindings, list), &missing); err != nil || len(missing) == 0 {
+		return
+	}
+	c.Logger().Debug("re-applying bindings lost in navigation", "names", missing)
+
+	for _, name := range missing {
+		if _, err := c.Send("Runtime.addBinding", map[string]interface{}{"name": name}); err != nil {
+			c.Logger().Error("failed to re-add binding", "name", name, "error", err)
+			continue
+		}
+		c.Send("Runtime.evaluate", map[string]interface{}{"expression": fmt.Sprintf(bindingWrapper, quote(name))})
+	}
+	for i, source := range scripts {
+		c.Send("Runtime.evaluate", map[string]interface{}{"expression": fmt.Sprintf(initGuard, i+1, i+1, source)})
+	}
+}
+
+func (c *Chrome) onContextCreated(e browser.Event) {
+	var p struct {
+		Context struct {
+			ID      int `json:"id"`
+			AuxData struct {
+				IsDefault bool   `json:"isDefault"`
+				FrameID   string `json:"frameId"`
+			} `json:"auxData"`
+		} `json:"context"`
+	}
+	if json.Unmarshal(e.Params, &p) != nil || !p.Context.AuxData.IsDefault {
+		return
+	}
+	c.Lock()
+	main := c.mainFrame
+	c.Unlock()
+	if p.Context.AuxData.FrameID != main {
+		return
+	}
+
+	// Evaluating waits for responses, which must not happen on the event
+	// goroutine that delivers them.
+	go c.reinject(p.Context.ID)
+}
+
+// reinject applies bindings and init scripts to a new document and
+// announces it as ready.
+func (c *Chrome) reinject(contextID int) {
+	c.Lock()
+	names := make([]string, 0, len(c.Bindings))
+	for name := range c.Bindings {
+		names = append(names, name)
+	}
+	scripts := append([]string(nil), c.initScripts...)
+	c.Unlock()
+
+	eval := func(expr string) {
+		if _, err := c.Send("Runtime.evaluate", map[string]interface{}{
+			"expression": expr,
+			"contextId":  contextID,
+		}); err != nil {
+			c.Logger().Debug("failed to re-inject into new document", "error", err)
+		}
+	}
+	for _, name := range names {
+		eval(fmt.Sprintf(bindingWrapper, quote(name)))
+	}
+	for i, source := range scripts {
+		eval(fmt.Sprintf(initGuard, i+1, i+1, source))
+	}
+	eval(`window.dispatchEvent(new Event("majorca:ready"))`)
+
+	c.Emit(browser.Event{Method: EventReady})
+}
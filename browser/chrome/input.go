@@ -0,0 +1,218 @@
+package chrome
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Modifier bits of Input.dispatchKeyEvent and Input.dispatchMouseEvent.
+const (
+	modAlt   = 1
+	modCtrl  = 2
+	modMeta  = 4
+	modShift = 8
+)
+
+// keyDef describes a key for Input.dispatchKeyEvent.
+type keyDef struct {
+	key     string
+	code    string
+	keyCode int
+	text    string
+}
+
+// namedKeys are the keys SendKeys accepts by name, besides single
+// characters.
+var namedKeys = map[string]keyDef{
+	"enter":      {"Enter", "Enter", 13, "\r"},
+	"tab":        {"Tab", "Tab", 9, ""},
+	"backspace":  {"Backspace", "Backspace", 8, ""},
+	"delete":     {"Delete", "Delete", 46, ""},
+	"escape":     {"Escape", "Escape", 27, ""},
+	"esc":        {"Escape", "Escape", 27, ""},
+	"space":      {" ", "Space", 32, " "},
+	"arrowup":    {"ArrowUp", "ArrowUp", 38, ""},
+	"arrowdown":  {"ArrowDown", "ArrowDown", 40, ""},
+	"arrowleft":  {"ArrowLeft", "ArrowLeft", 37, ""},
+	"arrowright": {"ArrowRight", "ArrowRight", 39, ""},
+	"up":         {"ArrowUp", "ArrowUp", 38, ""},
+	"down":       {"ArrowDown", "ArrowDown", 40, ""},
+	"left":       {"ArrowLeft", "ArrowLeft", 37, ""},
+	"right":      {"ArrowRight", "ArrowRight", 39, ""},
+	"home":       {"Home", "Home", 36, ""},
+	"end":        {"End", "End", 35, ""},
+	"pageup":     {"PageUp", "PageUp", 33, ""},
+	"pagedown":   {"PageDown", "PageDown", 34, ""},
+	"insert":     {"Insert", "Insert", 45, ""},
+	"f1":         {"F1", "F1", 112, ""},
+	"f2":         {"F2", "F2", 113, ""},
+	"f3":         {"F3", "F3", 114, ""},
+	"f4":         {"F4", "F4", 115, ""},
+	"f5":         {"F5", "F5", 116, ""},
+	"f6":         {"F6", "F6", 117, ""},
+	"f7":         {"F7", "F7", 118, ""},
+	"f8":         {"F8", "F8", 119, ""},
+	"f9":         {"F9", "F9", 120, ""},
+	"f10":        {"F10", "F10", 121, ""},
+	"f11":        {"F11", "F11", 122, ""},
+	"f12":        {"F12", "F12", 123, ""},
+}
+
+var modifierNames = map[string]int{
+	"alt":     modAlt,
+	"option":  modAlt,
+	"ctrl":    modCtrl,
+	"control": modCtrl,
+	"meta":    modMeta,
+	"cmd":     modMeta,
+	"command": modMeta,
+	"shift":   modShift,
+}
+
+// Click clicks the left mouse button at x, y in CSS pixels relative to the
+// viewport.
+func (c *Chrome) Click(x, y float64) error {
+	for _, typ := range []string{"mouseMoved", "mousePressed", "mouseReleased"} {
+		params := map[string]interface{}{"type": typ, "x": x, "y": y}
+		if typ != "mouseMoved" {
+			params["button"] = "left"
+			params["clickCount"] = 1
+		}
+		if _, err := c.Send("Input.dispatchMouseEvent", params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClickSelector scrolls the first element matching selector into view and
+// clicks its centre.
+func (c *Chrome) ClickSelector(selector string) error {
+	var box *struct {
+		X, Y float64
+	}
+	err := c.evalJSON(fmt.Sprintf(`(() => {
+		const el = document.querySelector(%s);
+		if (!el) return null;
+		el.scrollIntoView({block: "center", inline: "center"});
+		const r = el.getBoundingClientRect();
+		if (r.width === 0 && r.height === 0) return null;
+		return {X: r.left + r.width / 2, Y: r.top + r.height / 2};
+	})()`, quote(selector)), &box)
+	if err != nil {
+		return err
+	}
+	if box == nil {
+		return fmt.Errorf("no visible element matches %s", selector)
+	}
+	return c.Click(box.X, box.Y)
+}
+
+// TypeText types s into the focused element one key press at a time.
+// Newlines press Enter and tabs press Tab.
+func (c *Chrome) TypeText(s string) error {
+	for _, r := range s {
+		var k keyDef
+		switch r {
+		case '\n':
+			k = namedKeys["enter"]
+		case '\t':
+			k = namedKeys["tab"]
+		default:
+			k = charKey(r)
+		}
+		if err := c.pressKey(k, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendKeys presses each key chord in turn. A chord is a key name or single
+// character, optionally preceded by modifiers joined with '+', e.g. "Enter",
+// "Ctrl+A" or "Shift+Tab".
+func (c *Chrome) SendKeys(chords ...string) error {
+	for _, chord := range chords {
+		k, mods, err := parseChord(chord)
+		if err != nil {
+			return err
+		}
+		if err := c.pressKey(k, mods); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pressKey sends a key down and up with the given modifiers held.
+func (c *Chrome) pressKey(k keyDef, mods int) error {
+	down := map[string]interface{}{
+		"type":                  "keyDown",
+		"key":                   k.key,
+		"code":                  k.code,
+		"windowsVirtualKeyCode": k.keyCode,
+		"modifiers":             mods,
+	}
+	// Shortcuts must not insert their character.
+	if k.text != "" && mods&(modCtrl|modAlt|modMeta) == 0 {
+		down["text"] = k.text
+	}
+	if _, err := c.Send("Input.dispatchKeyEvent", down); err != nil {
+		return err
+	}
+	_, err := c.Send("Input.dispatchKeyEvent", map[string]interface{}{
+		"type":                  "keyUp",
+		"key":                   k.key,
+		"code":                  k.code,
+		"windowsVirtualKeyCode": k.keyCode,
+		"modifiers":             mods,
+	})
+	return err
+}
+
+// parseChord splits a chord like "Ctrl+Shift+K" into its key and modifiers.
+func parseChord(chord string) (keyDef, int, error) {
+	parts := strings.Split(chord, "+")
+	// A trailing empty part means the key itself is '+'.
+	if len(parts) > 1 && parts[len(parts)-1] == "" {
+		parts = append(parts[:len(parts)-2], "+")
+	}
+
+	mods := 0
+	for _, p := range parts[:len(parts)-1] {
+		m, ok := modifierNames[strings.ToLower(p)]
+		if !ok {
+			return keyDef{}, 0, fmt.Errorf("unknown modifier %q in %q", p, chord)
+		}
+		mods |= m
+	}
+
+	name := parts[len(parts)-1]
+	if k, ok := namedKeys[strings.ToLower(name)]; ok {
+		return k, mods, nil
+	}
+	if r := []rune(name); len(r) == 1 {
+		if mods&modShift != 0 {
+			r[0] = unicode.ToUpper(r[0])
+		}
+		return charKey(r[0]), mods, nil
+	}
+	return keyDef{}, 0, fmt.Errorf("unknown key %q in %q", name, chord)
+}
+
+// charKey describes the key that types r.
+func charKey(r rune) keyDef {
+	k := keyDef{key: string(r), text: string(r)}
+	switch up := unicode.ToUpper(r); {
+	case up >= 'A' && up <= 'Z':
+		k.code = "Key" + string(up)
+		k.keyCode = int(up)
+	case r >= '0' && r <= '9':
+		k.code = "Digit" + string(r)
+		k.keyCode = int(r)
+	case r == ' ':
+		return namedKeys["space"]
+	}
+	return k
+}
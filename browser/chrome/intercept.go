@@ -0,0 +1,239 @@
+package chrome
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// Request is a network request paused by Intercept.
+type Request struct {
+	URL          string
+	Method       string
+	Headers      map[string]string
+	PostData     string
+	ResourceType string // e.g. "Document", "Script", "XHR", "Fetch"
+}
+
+// Response tells Intercept what to do with a paused request. The zero value
+// lets the request continue unchanged.
+type Response struct {
+	// Block fails the request as if blocked by the client.
+	Block bool
+
+	// Status, when non-zero, answers the request without touching the
+	// network. Use 302 with a Location header to redirect visibly.
+	Status  int
+	Headers map[string]string
+	Body    []byte
+
+	// URL and RequestHeaders modify a request that continues to the network.
+	// Changing the URL is invisible to the page.
+	URL            string
+	RequestHeaders map[string]string
+}
+
+type interceptor struct {
+	id      int
+	pattern string
+	match   *regexp.Regexp
+	handler func(Request) Response
+}
+
+type interceptTracker struct {
+	mu           sync.Mutex
+	nextID       int
+	interceptors []*interceptor
+	downloads    func(DownloadMeta, io.Reader) // Set by OnDownload
+	content      map[string]ContentSettings    // By origin, see SetContentSettings
+	muteScript   string                        // Identifier of the installed muteScript
+	subscribed   bool
+}
+
+// Intercept pauses requests whose URL matches pattern and lets handler
+// block, rewrite or answer them. In pattern '*' matches any number of
+// characters and '?' exactly one. When several patterns match, the one
+// registered first wins. The returned function removes the interceptor.
+func (c *Chrome) Intercept(pattern string, handler func(Request) Response) (func(), error) {
+	c.intercepts.mu.Lock()
+	c.intercepts.nextID++
+	id := c.intercepts.nextID
+	c.intercepts.interceptors = append(c.intercepts.interceptors, &interceptor{
+		id:      id,
+		pattern: pattern,
+		match:   globRegexp(pattern),
+		handler: handler,
+	})
+	subscribed := c.intercepts.subscribed
+	c.intercepts.subscribed = true
+	c.intercepts.mu.Unlock()
+
+	if !subscribed {
+		c.On("Fetch.requestPaused", c.onRequestPaused)
+	}
+	if err := c.applyIntercepts(); err != nil {
+		c.removeInterceptor(id)
+		return nil, err
+	}
+	return func() {
+		c.removeInterceptor(id)
+		c.applyIntercepts()
+	}, nil
+}
+
+func (c *Chrome) removeInterceptor(id int) {
+	c.intercepts.mu.Lock()
+	defer c.intercepts.mu.Unlock()
+	for i, ic := range c.intercepts.interceptors {
+		if ic.id == id {
+			c.intercepts.interceptors = append(c.intercepts.interceptors[:i], c.intercepts.interceptors[i+1:]...)
+			return
+		}
+	}
+}
+
+// applyIntercepts enables the Fetch domain for the current patterns. It is
+// per connection, so this runs again after a reconnect.
+func (c *Chrome) applyIntercepts() error {
+	c.intercepts.mu.Lock()
+	var patterns []map[string]interface{}
+	for _, ic := range c.intercepts.interceptors {
+		patterns = append(patterns, map[string]interface{}{"urlPattern": ic.pattern})
+	}
+	if c.intercepts.downloads != nil {
+		// Downloads are navigations or <a download> fetches; pause them once
+		// their headers say whether they are attachments.
+		for _, typ := range []string{"Document", "Other"} {
+			patterns = append(patterns, map[string]interface{}{
+				"urlPattern":   "*",
+				"resourceType": typ,
+				"requestStage": "Response",
+			})
+		}
+	}
+	for origin := range c.intercepts.content {
+		// Content settings are enforced through headers of the origin's
+		// documents, including iframes.
+		patterns = append(patterns, map[string]interface{}{
+			"urlPattern":   origin + "/*",
+			"resourceType": "Document",
+			"requestStage": "Response",
+		})
+	}
+	subscribed := c.intercepts.subscribed
+	c.intercepts.mu.Unlock()
+
+	if !subscribed {
+		return nil
+	}
+	if len(patterns) == 0 {
+		_, err := c.Send("Fetch.disable", nil)
+		return err
+	}
+	_, err := c.Send("Fetch.enable", map[string]interface{}{"patterns": patterns})
+	return err
+}
+
+func (c *Chrome) onRequestPaused(e browser.Event) {
+	if c.pausedResponse(e) {
+		return
+	}
+	var p struct {
+		RequestID    string `json:"requestId"`
+		ResourceType string `json:"resourceType"`
+		Request      struct {
+			URL      string            `json:"url"`
+			Method   string            `json:"method"`
+			Headers  map[string]string `json:"headers"`
+			PostData string            `json:"postData"`
+		} `json:"request"`
+	}
+	if json.Unmarshal(e.Params, &p) != nil {
+		return
+	}
+
+	c.intercepts.mu.Lock()
+	var handler func(Request) Response
+	for _, ic := range c.intercepts.interceptors {
+		if ic.match.MatchString(p.Request.URL) {
+			handler = ic.handler
+			break
+		}
+	}
+	c.intercepts.mu.Unlock()
+
+	// Handlers may be slow and answering waits for a response, neither of
+	// which may happen on the event goroutine.
+	go func() {
+		var res Response
+		if handler != nil {
+			res = handler(Request{
+				URL:          p.Request.URL,
+				Method:       p.Request.Method,
+				Headers:      p.Request.Headers,
+				PostData:     p.Request.PostData,
+				ResourceType: p.ResourceType,
+			})
+		}
+		if _, err := c.Send(answerRequest(p.RequestID, res)); err != nil {
+			c.Logger().Error("failed to answer intercepted request", "url", p.Request.URL, "error", err)
+		}
+	}()
+}
+
+// answerRequest turns a Response into the Fetch command resuming requestID.
+func answerRequest(requestID string, res Response) (string, map[string]interface{}) {
+	params := map[string]interface{}{"requestId": requestID}
+	switch {
+	case res.Block:
+		params["errorReason"] = "BlockedByClient"
+		return "Fetch.failRequest", params
+	case res.Status != 0:
+		params["responseCode"] = res.Status
+		params["responseHeaders"] = headerEntries(res.Headers)
+		params["body"] = base64.StdEncoding.EncodeToString(res.Body)
+		return "Fetch.fulfillRequest", params
+	}
+	if res.URL != "" {
+		params["url"] = res.URL
+	}
+	if res.RequestHeaders != nil {
+		params["headers"] = headerEntries(res.RequestHeaders)
+	}
+	return "Fetch.continueRequest", params
+}
+
+func headerEntries(headers map[string]string) []map[string]string {
+	entries := make([]map[string]string, 0, len(headers))
+	for name, value := range headers {
+		entries = append(entries, map[string]string{"name": name, "value": value})
+	}
+	return entries
+}
+
+// globRegexp compiles a Fetch URL pattern, where '*' matches any number of
+// characters, '?' exactly one and a backslash escapes the next character.
+func globRegexp(pattern string) *regexp.Regexp {
+	var re strings.Builder
+	re.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch ch := pattern[i]; {
+		case ch == '*':
+			re.WriteString(".*")
+		case ch == '?':
+			re.WriteString(".")
+		case ch == '\\' && i+1 < len(pattern):
+			i++
+			re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	re.WriteString("$")
+	return regexp.MustCompile(re.String())
+}
@@ -0,0 +1,95 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// JSError is returned by Eval and related helpers when the evaluated script
+// throws.
+type JSError struct {
+	Message string
+	URL     string
+	Line    int // Zero-based, as reported by the browser
+	Column  int
+	Stack   []StackFrame
+}
+
+func (e *JSError) Error() string {
+	return "javascript error: " + e.Message
+}
+
+// exceptionDetails mirrors Runtime.ExceptionDetails.
+type exceptionDetails struct {
+	Text       string        `json:"text"`
+	URL        string        `json:"url"`
+	Line       int           `json:"lineNumber"`
+	Column     int           `json:"columnNumber"`
+	StackTrace *stackTrace   `json:"stackTrace"`
+	Exception  *remoteObject `json:"exception"`
+}
+
+// jsError converts the browser's exception report into a JSError. The thrown
+// value's description is preferred over the generic "Uncaught" text.
+func (d *exceptionDetails) jsError() *JSError {
+	e := &JSError{Message: d.Text, URL: d.URL, Line: d.Line, Column: d.Column}
+	if d.Exception != nil {
+		if msg := d.Exception.String(); msg != "" {
+			// Error descriptions carry the stack after the first line.
+			e.Message, _, _ = strings.Cut(msg, "\n")
+		}
+	}
+	if d.StackTrace != nil {
+		e.Stack = d.StackTrace.CallFrames
+	}
+	return e
+}
+
+// quote turns a Go string into a JavaScript string literal.
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// evalBool evaluates expr and reports whether it returned true.
+func (c *Chrome) evalBool(expr string) (bool, error) {
+	v, _, err := c.Eval(expr)
+	if err != nil {
+		return false, err
+	}
+	return v == "true", nil
+}
+
+// evalJSON evaluates expr, awaiting it if it is a promise, and unmarshals
+// its JSON-serializable result into v.
+func (c *Chrome) evalJSON(expr string, v interface{}) error {
+	raw, err := c.Send("Runtime.evaluate", map[string]interface{}{
+		"expression":    expr,
+		"returnByValue": true,
+		"awaitPromise":  true,
+	})
+	if err != nil {
+		return err
+	}
+
+	var res struct {
+		Result struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *exceptionDetails `json:"exceptionDetails"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if res.ExceptionDetails != nil {
+		return res.ExceptionDetails.jsError()
+	}
+	if len(res.Result.Value) == 0 {
+		return fmt.Errorf("expression did not return a value")
+	}
+	if err := json.Unmarshal(res.Result.Value, v); err != nil {
+		return fmt.Errorf("failed to unmarshal value: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package chrome
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// BrowserKind identifies a Chromium-based browser.
+type BrowserKind string
+
+const (
+	KindChrome   BrowserKind = "chrome"
+	KindChromium BrowserKind = "chromium"
+	KindEdge     BrowserKind = "edge"
+	KindBrave    BrowserKind = "brave"
+)
+
+// Kinds lists the supported browsers in the order FindPath prefers them.
+var Kinds = []BrowserKind{KindChrome, KindChromium, KindEdge, KindBrave}
+
+// Installation is a browser found by Detect.
+type Installation struct {
+	Kind    BrowserKind
+	Path    string
+	Version string // Empty if it could not be determined without launching
+}
+
+// candidates returns the locations a browser of the given kind is installed
+// to on this OS.
+func candidates(kind BrowserKind) ([]string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		var dir, exe string
+		switch kind {
+		case KindChrome:
+			dir, exe = `Google\Chrome\Application`, "chrome.exe"
+		case KindChromium:
+			dir, exe = `Chromium\Application`, "chrome.exe"
+		case KindEdge:
+			dir, exe = `Microsoft\Edge\Application`, "msedge.exe"
+		case KindBrave:
+			dir, exe = `BraveSoftware\Brave-Browser\Application`, "brave.exe"
+		default:
+			return nil, fmt.Errorf("unknown browser kind %q", kind)
+		}
+		username := os.Getenv("USERNAME")
+		return []string{
+			filepath.Join(`C:\Program Files (x86)`, dir, exe),
+			filepath.Join(`C:\Program Files`, dir, exe),
+			filepath.Join("C:\\Users", username, "AppData\\Local", dir, exe),
+		}, nil
+	case "darwin":
+		var app string
+		switch kind {
+		case KindChrome:
+			app = "Google Chrome"
+		case KindChromium:
+			app = "Chromium"
+		case KindEdge:
+			app = "Microsoft Edge"
+		case KindBrave:
+			app = "Brave Browser"
+		default:
+			return nil, fmt.Errorf("unknown browser kind %q", kind)
+		}
+		home, _ := os.UserHomeDir()
+		bin := app + ".app/Contents/MacOS/" + app
+		return []string{
+			filepath.Join("/Applications", bin),
+			filepath.Join(home, "Applications", bin),
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported OS: %s", runtime.GOOS)
+}
+
+// FindKind locates an installation of the given browser.
+func FindKind(kind BrowserKind) (string, error) {
+	paths, err := candidates(kind)
+	if err != nil {
+		return "", err
+	}
+	if p, ok := browser.SelectBinary(paths); ok {
+		return p, nil
+	}
+	return "", fmt.Errorf("could not find %s binary", kind)
+}
+
+// NewWithKind launches a specific browser instead of the first one found.
+// WithExecutablePath in opts still takes precedence.
+func NewWithKind(kind BrowserKind, opts ...browser.Option) (*Chrome, error) {
+	path, err := FindKind(kind)
+	if err != nil {
+		return nil, err
+	}
+	return New(append([]browser.Option{browser.WithExecutablePath(path)}, opts...)...)
+}
+
+// Detect lists every supported browser installed on this machine, e.g. to
+// let users pick one.
+func Detect() []Installation {
+	var found []Installation
+	for _, kind := range Kinds {
+		paths, err := candidates(kind)
+		if err != nil {
+			return nil
+		}
+		for _, p := range paths {
+			if info, err := os.Stat(p); err != nil || info.IsDir() {
+				continue
+			}
+			found = append(found, Installation{Kind: kind, Path: p, Version: installedVersion(p)})
+		}
+	}
+	return found
+}
+
+var (
+	versionDir   = regexp.MustCompile(`^\d+(\.\d+){3}$`)
+	plistVersion = regexp.MustCompile(`<key>CFBundleShortVersionString</key>\s*<string>([^<]+)</string>`)
+)
+
+// installedVersion reads the version of the browser at path from its
+// installation layout: Windows installs keep a directory named after the
+// version next to the executable, macOS bundles record it in Info.plist.
+func installedVersion(path string) string {
+	if runtime.GOOS == "darwin" {
+		data, err := os.ReadFile(filepath.Join(filepath.Dir(path), "..", "Info.plist"))
+		if err != nil {
+			return ""
+		}
+		if m := plistVersion.FindSubmatch(data); m != nil {
+			return string(m[1])
+		}
+		return ""
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return ""
+	}
+	var best string
+	for _, e := range entries {
+		if e.IsDir() && versionDir.MatchString(e.Name()) && (best == "" || newerVersion(e.Name(), best)) {
+			best = e.Name()
+		}
+	}
+	return best
+}
+
+// newerVersion reports whether dotted version a is greater than b.
+func newerVersion(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range as {
+		if i >= len(bs) {
+			return true
+		}
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package chrome
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// Priority selects the lane a command is sent on.
+type Priority int
+
+const (
+	// PriorityInteractive is for commands a user is waiting on, such as Eval
+	// from a click handler. It is what Send uses.
+	PriorityInteractive Priority = iota
+	// PriorityBackground is for bulk work like captures, which yields to
+	// interactive commands queued at the same time.
+	PriorityBackground
+)
+
+type outgoing struct {
+	message json.RawMessage
+	sent    chan error
+}
+
+// lanes queues outgoing commands so interactive ones overtake background
+// ones waiting to be written.
+type lanes struct {
+	once        sync.Once
+	interactive chan outgoing
+	background  chan outgoing
+}
+
+// write queues message on the lane for p and waits until it was written.
+func (c *Chrome) write(p Priority, message json.RawMessage) error {
+	c.lanes.once.Do(func() {
+		c.lanes.interactive = make(chan outgoing, 64)
+		c.lanes.background = make(chan outgoing, 64)
+		go c.writeLoop()
+	})
+
+	lane := c.lanes.interactive
+	if p == PriorityBackground {
+		lane = c.lanes.background
+	}
+	out := outgoing{message: message, sent: make(chan error, 1)}
+	select {
+	case lane <- out:
+	case <-c.Stop:
+		return browser.ErrConnectionClosed
+	}
+	select {
+	case err := <-out.sent:
+		return err
+	case <-c.Stop:
+		return browser.ErrConnectionClosed
+	}
+}
+
+// writeLoop is the only writer to the connection. It drains the interactive
+// lane before taking anything from the background lane.
+func (c *Chrome) writeLoop() {
+	for {
+		var out outgoing
+		select {
+		case out = <-c.lanes.interactive:
+		case <-c.Stop:
+			return
+		default:
+			select {
+			case out = <-c.lanes.interactive:
+			case out = <-c.lanes.background:
+			case <-c.Stop:
+				return
+			}
+		}
+
+		c.Lock()
+		ws := c.Ws
+		c.Unlock()
+		out.sent <- ws.WriteJSON(out.message)
+	}
+}
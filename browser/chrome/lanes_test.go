@@ -0,0 +1,65 @@
+package chrome_test
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grngxd/majorca/browser/chrome"
+	"github.com/grngxd/majorca/internal/cdptest"
+)
+
+func TestInteractiveOvertakesBackground(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	gate := make(chan struct{})
+	d := cdptest.New(t, func(target, method string, params json.RawMessage) interface{} {
+		if !strings.HasPrefix(method, "Test.") {
+			return nil
+		}
+		mu.Lock()
+		order = append(order, method)
+		mu.Unlock()
+		if method == "Test.block" {
+			<-gate
+		}
+		return nil
+	})
+	c, err := chrome.Attach(d.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Kill()
+
+	// While the server is stuck answering Test.block it reads nothing, so a
+	// message too large for the socket buffers keeps the writer busy and
+	// later commands queue up on their lanes.
+	block := c.SendAsync("Test.block", nil)
+	big := strings.Repeat("x", 32<<20)
+	var wg sync.WaitGroup
+	send := func(p chrome.Priority, method string, params interface{}) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.SendPriority(p, method, params); err != nil {
+				t.Errorf("%s: %v", method, err)
+			}
+		}()
+		time.Sleep(50 * time.Millisecond)
+	}
+	send(chrome.PriorityBackground, "Test.big", map[string]string{"data": big})
+	send(chrome.PriorityBackground, "Test.background", nil)
+	send(chrome.PriorityInteractive, "Test.interactive", nil)
+	close(gate)
+	wg.Wait()
+	<-block
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := "Test.block,Test.big,Test.interactive,Test.background"
+	if got := strings.Join(order, ","); got != want {
+		t.Errorf("commands written in order %s, want %s", got, want)
+	}
+}
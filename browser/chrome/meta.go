@@ -0,0 +1,135 @@
+package chrome
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// PageMeta describes the loaded page for recents lists, link previews and
+// native window icons.
+type PageMeta struct {
+	URL         string `json:"url"`
+	Canonical   string `json:"canonical"` // From <link rel=canonical>, else URL
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// Meta holds every <meta> tag with a name or property, such as
+	// "og:image" or "twitter:card". Open Graph tags keep their prefix.
+	Meta map[string]string `json:"meta"`
+
+	IconURL  string `json:"iconURL"` // Empty when the page has no usable icon
+	Icon     []byte `json:"-"`
+	IconType string `json:"-"` // MIME type of Icon
+}
+
+// OpenGraph returns the value of the Open Graph property name, e.g. "title"
+// for og:title.
+func (m *PageMeta) OpenGraph(name string) string {
+	return m.Meta["og:"+name]
+}
+
+// metaScript collects the page's metadata and picks its largest icon,
+// falling back to /favicon.ico for http(s) pages.
+const metaScript = `(() => {
+	const meta = {};
+	for (const el of document.querySelectorAll("meta[name], meta[property]")) {
+		const key = (el.getAttribute("property") || el.getAttribute("name")).trim().toLowerCase();
+		if (key && !(key in meta)) meta[key] = el.content || "";
+	}
+	const size = (el) => {
+		const sizes = (el.getAttribute("sizes") || "").toLowerCase();
+		if (sizes === "any") return 1 << 16;
+		return Math.max(0, ...sizes.split(/\s+/).map((s) => parseInt(s, 10) || 0));
+	};
+	const icons = [...document.querySelectorAll('link[rel~="icon"], link[rel~="apple-touch-icon"]')]
+		.filter((el) => el.href)
+		.sort((a, b) => size(b) - size(a));
+	let icon = icons.length ? icons[0].href : "";
+	if (!icon && /^https?:$/.test(location.protocol)) icon = location.origin + "/favicon.ico";
+	const canonical = document.querySelector('link[rel="canonical"]');
+	return {
+		url: location.href,
+		canonical: canonical && canonical.href ? canonical.href : location.href,
+		title: meta["og:title"] || document.title.trim(),
+		description: meta["description"] || meta["og:description"] || "",
+		meta: meta,
+		iconURL: icon,
+	};
+})()`
+
+// PageMeta returns the current page's title, description, meta tags,
+// canonical URL and favicon. The icon is loaded through the browser's
+// network stack with the page's cookies, so it also works for icons on
+// other origins. A missing or broken icon is not an error; Icon is nil then.
+func (c *Chrome) PageMeta() (*PageMeta, error) {
+	var m PageMeta
+	if err := c.evalJSON(metaScript, &m); err != nil {
+		return nil, err
+	}
+	if m.IconURL != "" {
+		icon, typ, err := c.loadResource(m.IconURL)
+		if err != nil {
+			c.Logger().Debug("failed to load page icon", "url", m.IconURL, "error", err)
+		} else {
+			m.Icon, m.IconType = icon, typ
+		}
+	}
+	return &m, nil
+}
+
+// loadResource fetches rawURL as the main frame would, without CORS
+// restrictions, and returns its body and MIME type.
+func (c *Chrome) loadResource(rawURL string) ([]byte, string, error) {
+	c.Lock()
+	frame := c.mainFrame
+	c.Unlock()
+
+	raw, err := c.Send("Network.loadNetworkResource", map[string]interface{}{
+		"frameId": frame,
+		"url":     rawURL,
+		"options": map[string]interface{}{"disableCache": false, "includeCredentials": true},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	var res struct {
+		Resource struct {
+			Success        bool              `json:"success"`
+			NetError       string            `json:"netErrorName"`
+			HTTPStatusCode int               `json:"httpStatusCode"`
+			Stream         string            `json:"stream"`
+			Headers        map[string]string `json:"headers"`
+		} `json:"resource"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal resource: %w", err)
+	}
+	r := res.Resource
+	if r.Stream != "" {
+		defer c.Send("IO.close", map[string]interface{}{"handle": r.Stream})
+	}
+	if !r.Success {
+		if r.NetError != "" {
+			return nil, "", fmt.Errorf("failed to load %s: %s", rawURL, r.NetError)
+		}
+		return nil, "", fmt.Errorf("failed to load %s: status %d", rawURL, r.HTTPStatusCode)
+	}
+
+	var body bytes.Buffer
+	if err := c.readStream(r.Stream, &body); err != nil {
+		return nil, "", err
+	}
+	typ := ""
+	for name, value := range r.Headers {
+		if strings.EqualFold(name, "Content-Type") {
+			typ, _, _ = mime.ParseMediaType(value)
+		}
+	}
+	if typ == "" {
+		typ, _, _ = mime.ParseMediaType(http.DetectContentType(body.Bytes()))
+	}
+	return body.Bytes(), typ, nil
+}
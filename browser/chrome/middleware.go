@@ -0,0 +1,101 @@
+package chrome
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// Command is a DevTools command passing through middleware.
+type Command struct {
+	Method   string
+	Params   interface{}
+	Priority Priority
+}
+
+// Handler sends a command and returns its result.
+type Handler func(cmd Command) (json.RawMessage, error)
+
+// Middleware wraps the sending of every command, e.g. to log, time, alter
+// or fail it. It calls next to pass the command on.
+type Middleware func(next Handler) Handler
+
+// EventMiddleware sees every event before subscribers do. Returning false
+// drops the event.
+type EventMiddleware func(e browser.Event) bool
+
+type commandEntry struct{ m Middleware }
+type eventEntry struct{ m EventMiddleware }
+
+type middlewares struct {
+	mu       sync.Mutex
+	commands []*commandEntry
+	events   []*eventEntry
+}
+
+// Use adds command middleware. Middleware added first runs outermost. The
+// returned function removes it again.
+func (c *Chrome) Use(m Middleware) func() {
+	e := &commandEntry{m}
+	c.middleware.mu.Lock()
+	c.middleware.commands = append(c.middleware.commands, e)
+	c.middleware.mu.Unlock()
+	return func() {
+		c.middleware.mu.Lock()
+		defer c.middleware.mu.Unlock()
+		for i, x := range c.middleware.commands {
+			if x == e {
+				c.middleware.commands = append(c.middleware.commands[:i:i], c.middleware.commands[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// UseEvents adds event middleware, run in the order added. The returned
+// function removes it again.
+func (c *Chrome) UseEvents(m EventMiddleware) func() {
+	e := &eventEntry{m}
+	c.middleware.mu.Lock()
+	c.middleware.events = append(c.middleware.events, e)
+	c.middleware.mu.Unlock()
+	return func() {
+		c.middleware.mu.Lock()
+		defer c.middleware.mu.Unlock()
+		for i, x := range c.middleware.events {
+			if x == e {
+				c.middleware.events = append(c.middleware.events[:i:i], c.middleware.events[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// wrap returns h wrapped in the installed command middleware, or nil if
+// there is none.
+func (m *middlewares) wrap(h Handler) Handler {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.commands) == 0 {
+		return nil
+	}
+	for i := len(m.commands) - 1; i >= 0; i-- {
+		h = m.commands[i].m(h)
+	}
+	return h
+}
+
+// allow runs the event middleware and reports whether e should be emitted.
+func (m *middlewares) allow(e browser.Event) bool {
+	m.mu.Lock()
+	events := make([]*eventEntry, len(m.events))
+	copy(events, m.events)
+	m.mu.Unlock()
+	for _, x := range events {
+		if !x.m(e) {
+			return false
+		}
+	}
+	return true
+}
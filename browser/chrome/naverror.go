@@ -0,0 +1,86 @@
+package chrome
+
+import "strings"
+
+// NavCategory groups navigation failures by the layer that failed.
+type NavCategory string
+
+const (
+	NavDNS     NavCategory = "dns"     // Host name could not be resolved
+	NavTCP     NavCategory = "tcp"     // Connection refused, reset, timed out or offline
+	NavTLS     NavCategory = "tls"     // Certificate or handshake problems
+	NavHTTP    NavCategory = "http"    // Invalid or failed HTTP response
+	NavAborted NavCategory = "aborted" // Canceled or blocked before completing
+	NavOther   NavCategory = "other"
+)
+
+// NavError is returned by Load when the browser reports that a navigation
+// failed.
+type NavError struct {
+	URL      string
+	Code     string // Chrome's error text, e.g. "net::ERR_NAME_NOT_RESOLVED"
+	Category NavCategory
+}
+
+// NewNavError classifies Chrome's errorText for a navigation to url.
+func NewNavError(url, errorText string) *NavError {
+	return &NavError{URL: url, Code: errorText, Category: classifyNavError(errorText)}
+}
+
+func (e *NavError) Error() string {
+	return "navigation to " + e.URL + " failed: " + e.Code
+}
+
+// Retryable reports whether trying again later may succeed, i.e. the failure
+// was in name resolution or the connection rather than the response.
+func (e *NavError) Retryable() bool {
+	return e.Category == NavDNS || e.Category == NavTCP
+}
+
+// navErrorPrefixes map net error names, without the "net::ERR_" prefix, to
+// categories. Exact names are listed before the prefixes they share.
+var navErrorPrefixes = []struct {
+	prefix   string
+	category NavCategory
+}{
+	{"NAME_NOT_RESOLVED", NavDNS},
+	{"NAME_RESOLUTION_FAILED", NavDNS},
+	{"DNS_", NavDNS},
+	{"ICANN_NAME_COLLISION", NavDNS},
+
+	{"CONNECTION_", NavTCP},
+	{"ADDRESS_", NavTCP},
+	{"INTERNET_DISCONNECTED", NavTCP},
+	{"NETWORK_", NavTCP},
+	{"TIMED_OUT", NavTCP},
+	{"PROXY_", NavTCP},
+	{"SOCKET_", NavTCP},
+	{"TUNNEL_CONNECTION_FAILED", NavTCP},
+
+	{"CERT_", NavTLS},
+	{"SSL_", NavTLS},
+	{"BAD_SSL_", NavTLS},
+
+	{"HTTP_", NavHTTP},
+	{"INVALID_HTTP_RESPONSE", NavHTTP},
+	{"INVALID_RESPONSE", NavHTTP},
+	{"EMPTY_RESPONSE", NavHTTP},
+	{"TOO_MANY_REDIRECTS", NavHTTP},
+	{"RESPONSE_HEADERS_", NavHTTP},
+	{"CONTENT_", NavHTTP},
+	{"INVALID_REDIRECT", NavHTTP},
+	{"UNSAFE_REDIRECT", NavHTTP},
+
+	{"ABORTED", NavAborted},
+	{"BLOCKED_", NavAborted},
+}
+
+func classifyNavError(text string) NavCategory {
+	name := strings.TrimPrefix(strings.TrimPrefix(text, "net::"), "ERR_")
+	for _, p := range navErrorPrefixes {
+		if strings.HasPrefix(name, p.prefix) {
+			return p.category
+		}
+	}
+	return NavOther
+}
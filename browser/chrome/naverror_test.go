@@ -0,0 +1,35 @@
+package chrome_test
+
+import (
+	"testing"
+
+	"github.com/grngxd/majorca/browser/chrome"
+)
+
+func TestNavErrorCategory(t *testing.T) {
+	tests := map[string]chrome.NavCategory{
+		"net::ERR_NAME_NOT_RESOLVED":          chrome.NavDNS,
+		"net::ERR_CONNECTION_REFUSED":         chrome.NavTCP,
+		"net::ERR_INTERNET_DISCONNECTED":      chrome.NavTCP,
+		"net::ERR_CERT_AUTHORITY_INVALID":     chrome.NavTLS,
+		"net::ERR_SSL_PROTOCOL_ERROR":         chrome.NavTLS,
+		"net::ERR_HTTP_RESPONSE_CODE_FAILURE": chrome.NavHTTP,
+		"net::ERR_TOO_MANY_REDIRECTS":         chrome.NavHTTP,
+		"net::ERR_ABORTED":                    chrome.NavAborted,
+		"net::ERR_BLOCKED_BY_CLIENT":          chrome.NavAborted,
+		"net::ERR_FAILED":                     chrome.NavOther,
+	}
+	for text, want := range tests {
+		err := chrome.NewNavError("https://example.com", text)
+		if err.Category != want {
+			t.Errorf("%s categorized as %s, want %s", text, err.Category, want)
+		}
+	}
+
+	if !chrome.NewNavError("", "net::ERR_CONNECTION_RESET").Retryable() {
+		t.Error("Connection resets should be retryable")
+	}
+	if chrome.NewNavError("", "net::ERR_CERT_DATE_INVALID").Retryable() {
+		t.Error("Certificate errors should not be retryable")
+	}
+}
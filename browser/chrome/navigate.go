@@ -0,0 +1,122 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// lifecycleNames maps WaitUntil values to Page.lifecycleEvent names.
+var lifecycleNames = map[browser.WaitUntil]string{
+	browser.WaitLoad:             "load",
+	browser.WaitDOMContentLoaded: "DOMContentLoaded",
+	browser.WaitNetworkIdle:      "networkIdle",
+}
+
+// LoadWait navigates to url and blocks until the page reaches the given
+// lifecycle stage. An empty until returns as soon as navigation starts. If
+// the browser cannot load the page the error is a *NavError.
+func (c *Chrome) LoadWait(url string, until browser.WaitUntil) error {
+	if until == "" {
+		_, err := c.navigate(url)
+		return err
+	}
+	name, ok := lifecycleNames[until]
+	if !ok {
+		return fmt.Errorf("unknown wait condition %q", until)
+	}
+
+	if _, err := c.Send("Page.enable", nil); err != nil {
+		return err
+	}
+	if _, err := c.Send("Page.setLifecycleEventsEnabled", map[string]interface{}{"enabled": true}); err != nil {
+		return err
+	}
+
+	// Events can arrive before Page.navigate returns the loader to wait for,
+	// so collect them from the start.
+	type lifecycle struct {
+		FrameID  string `json:"frameId"`
+		LoaderID string `json:"loaderId"`
+		Name     string `json:"name"`
+	}
+	var mu sync.Mutex
+	var seen []lifecycle
+	notify := make(chan struct{}, 1)
+	off := c.On("Page.lifecycleEvent", func(e browser.Event) {
+		var l lifecycle
+		if json.Unmarshal(e.Params, &l) != nil || l.Name != name {
+			return
+		}
+		mu.Lock()
+		seen = append(seen, l)
+		mu.Unlock()
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+	})
+	defer off()
+
+	nav, err := c.navigate(url)
+	if err != nil {
+		return err
+	}
+	if nav.LoaderID == "" {
+		// Same-document navigation, e.g. a fragment change.
+		return nil
+	}
+
+	var timeout <-chan time.Time
+	if c.Timeout > 0 {
+		timer := time.NewTimer(c.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	for {
+		mu.Lock()
+		for _, l := range seen {
+			if l.FrameID == nav.FrameID && l.LoaderID == nav.LoaderID {
+				mu.Unlock()
+				return nil
+			}
+		}
+		mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-timeout:
+			return fmt.Errorf("waiting for %s of %s: %w", until, url, browser.ErrTimeout)
+		case <-c.Done():
+			return browser.ErrConnectionClosed
+		}
+	}
+}
+
+type navigation struct {
+	FrameID   string `json:"frameId"`
+	LoaderID  string `json:"loaderId"`
+	ErrorText string `json:"errorText"`
+}
+
+// navigate issues Page.navigate and reports navigation failures as
+// *NavError.
+func (c *Chrome) navigate(url string) (navigation, error) {
+	var nav navigation
+	raw, err := c.Send("Page.navigate", map[string]interface{}{
+		"url": url,
+	})
+	if err != nil {
+		return nav, err
+	}
+	if err := json.Unmarshal(raw, &nav); err != nil {
+		return nav, fmt.Errorf("failed to unmarshal navigation: %w", err)
+	}
+	if nav.ErrorText != "" {
+		return nav, NewNavError(url, nav.ErrorText)
+	}
+	return nav, nil
+}
@@ -0,0 +1,72 @@
+package chrome_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+	"github.com/grngxd/majorca/browser/chrome"
+	"github.com/grngxd/majorca/internal/cdptest"
+)
+
+func TestLoadWaitLifecycle(t *testing.T) {
+	var d *cdptest.Server
+	lifecycle := func(frame, loader, name string) {
+		d.Emit("main", "Page.lifecycleEvent", map[string]string{"frameId": frame, "loaderId": loader, "name": name})
+	}
+	d = cdptest.New(t, func(target, method string, params json.RawMessage) interface{} {
+		if method == "Page.navigate" {
+			// Events may overtake the response. None of these finish the
+			// new navigation: a stale loader, another stage, another frame.
+			lifecycle("main-frame", "old-loader", "load")
+			lifecycle("main-frame", "new-loader", "DOMContentLoaded")
+			lifecycle("child-frame", "new-loader", "load")
+			return map[string]string{"frameId": "main-frame", "loaderId": "new-loader"}
+		}
+		return nil
+	})
+	c, err := chrome.Attach(d.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Kill()
+
+	done := make(chan error, 1)
+	go func() { done <- c.LoadWait("https://example.com", browser.WaitLoad) }()
+	select {
+	case err := <-done:
+		t.Fatalf("LoadWait returned %v before the page loaded", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	lifecycle("main-frame", "new-loader", "load")
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("LoadWait: %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("LoadWait did not return after the load event")
+	}
+}
+
+func TestLoadWaitEarlyEvent(t *testing.T) {
+	var d *cdptest.Server
+	d = cdptest.New(t, func(target, method string, params json.RawMessage) interface{} {
+		if method == "Page.navigate" {
+			d.Emit("main", "Page.lifecycleEvent", map[string]string{"frameId": "main-frame", "loaderId": "new-loader", "name": "DOMContentLoaded"})
+			return map[string]string{"frameId": "main-frame", "loaderId": "new-loader"}
+		}
+		return nil
+	})
+	c, err := chrome.Attach(d.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Kill()
+
+	within(t, 3*time.Second, "LoadWait", func() error {
+		return c.LoadWait("https://example.com", browser.WaitDOMContentLoaded)
+	})
+}
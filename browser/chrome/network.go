@@ -0,0 +1,123 @@
+package chrome
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// NetworkStats summarizes the traffic of the current page since its last
+// main-frame navigation.
+type NetworkStats struct {
+	URL           string // Main frame URL
+	Requests      int    // Requests issued
+	Failed        int    // Requests that failed or were blocked
+	BytesReceived int64  // Encoded bytes received over the wire
+	OverBudget    bool   // Set once the NetworkBudget was exceeded
+}
+
+// NetworkBudget limits the traffic of a single navigation. Zero limits are
+// ignored.
+type NetworkBudget struct {
+	MaxBytes    int64
+	MaxRequests int
+	// Abort stops loading the page once the budget is exceeded; otherwise the
+	// navigation is only flagged via NetworkStats.OverBudget.
+	Abort bool
+	// OnExceeded is called once per navigation when the budget is exceeded.
+	OnExceeded func(NetworkStats)
+}
+
+type networkTracker struct {
+	mu      sync.Mutex
+	stats   NetworkStats
+	budget  *NetworkBudget
+	enabled bool
+}
+
+// TrackNetwork starts accounting for network traffic per navigation. budget
+// may be nil to only collect statistics. Calling it again replaces the
+// budget.
+func (c *Chrome) TrackNetwork(budget *NetworkBudget) error {
+	c.network.mu.Lock()
+	c.network.budget = budget
+	enabled := c.network.enabled
+	c.network.enabled = true
+	c.network.mu.Unlock()
+	if enabled {
+		return nil
+	}
+
+	c.On("Page.frameNavigated", c.onFrameNavigated)
+	c.On("Network.requestWillBeSent", func(browser.Event) {
+		c.updateNetwork(func(s *NetworkStats) { s.Requests++ })
+	})
+	c.On("Network.loadingFinished", func(e browser.Event) {
+		var p struct {
+			EncodedDataLength float64 `json:"encodedDataLength"`
+		}
+		json.Unmarshal(e.Params, &p)
+		c.updateNetwork(func(s *NetworkStats) { s.BytesReceived += int64(p.EncodedDataLength) })
+	})
+	c.On("Network.loadingFailed", func(browser.Event) {
+		c.updateNetwork(func(s *NetworkStats) { s.Failed++ })
+	})
+
+	if _, err := c.Send("Page.enable", nil); err != nil {
+		return err
+	}
+	_, err := c.Send("Network.enable", nil)
+	return err
+}
+
+// NetworkStats returns the traffic recorded for the current page.
+func (c *Chrome) NetworkStats() NetworkStats {
+	c.network.mu.Lock()
+	defer c.network.mu.Unlock()
+	return c.network.stats
+}
+
+func (c *Chrome) onFrameNavigated(e browser.Event) {
+	var p struct {
+		Frame struct {
+			ParentID string `json:"parentId"`
+			URL      string `json:"url"`
+		} `json:"frame"`
+	}
+	if json.Unmarshal(e.Params, &p) != nil || p.Frame.ParentID != "" {
+		return
+	}
+
+	// The document request itself was counted before the frame committed.
+	c.network.mu.Lock()
+	prev := c.network.stats
+	c.network.stats = NetworkStats{URL: p.Frame.URL, Requests: min(prev.Requests, 1)}
+	c.network.mu.Unlock()
+}
+
+func (c *Chrome) updateNetwork(update func(*NetworkStats)) {
+	c.network.mu.Lock()
+	update(&c.network.stats)
+	stats := c.network.stats
+	b := c.network.budget
+	exceeded := b != nil && !stats.OverBudget &&
+		((b.MaxBytes > 0 && stats.BytesReceived > b.MaxBytes) ||
+			(b.MaxRequests > 0 && stats.Requests > b.MaxRequests))
+	if exceeded {
+		c.network.stats.OverBudget = true
+		stats.OverBudget = true
+	}
+	c.network.mu.Unlock()
+
+	if !exceeded {
+		return
+	}
+	c.Logger().Warn("network budget exceeded", "url", stats.URL, "bytes", stats.BytesReceived, "requests", stats.Requests)
+	if b.Abort {
+		go c.Send("Page.stopLoading", nil)
+	}
+	if b.OnExceeded != nil {
+		b.OnExceeded(stats)
+	}
+}
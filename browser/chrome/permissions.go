@@ -0,0 +1,72 @@
+package chrome
+
+// Permission is a browser permission that can be granted up front.
+type Permission string
+
+const (
+	PermissionClipboardRead    Permission = "clipboardReadWrite"
+	PermissionClipboardWrite   Permission = "clipboardSanitizedWrite"
+	PermissionNotifications    Permission = "notifications"
+	PermissionGeolocation      Permission = "geolocation"
+	PermissionCamera           Permission = "videoCapture"
+	PermissionMicrophone       Permission = "audioCapture"
+	PermissionMIDI             Permission = "midi"
+	PermissionSensors          Permission = "sensors"
+	PermissionIdleDetection    Permission = "idleDetection"
+	PermissionBackgroundSync   Permission = "backgroundSync"
+	PermissionDisplayCapture   Permission = "displayCapture"
+	PermissionLocalFonts       Permission = "localFonts"
+	PermissionStorageAccess    Permission = "storageAccess"
+	PermissionWindowManagement Permission = "windowManagement"
+)
+
+type grant struct {
+	origin      string
+	permissions []Permission
+}
+
+// GrantPermissions allows origin to use perms without prompting, which
+// matters in app windows where prompts render poorly. An empty origin
+// grants them to every page. Grants add up until ResetPermissions.
+func (c *Chrome) GrantPermissions(origin string, perms ...Permission) error {
+	if err := c.grantPermissions(grant{origin, perms}); err != nil {
+		return err
+	}
+	c.Lock()
+	c.grants = append(c.grants, grant{origin, perms})
+	c.Unlock()
+	return nil
+}
+
+// ResetPermissions revokes everything granted with GrantPermissions, so
+// pages prompt again.
+func (c *Chrome) ResetPermissions() error {
+	c.Lock()
+	c.grants = nil
+	c.Unlock()
+	_, err := c.Send("Browser.resetPermissions", nil)
+	return err
+}
+
+func (c *Chrome) grantPermissions(g grant) error {
+	params := map[string]interface{}{"permissions": g.permissions}
+	if g.origin != "" {
+		params["origin"] = g.origin
+	}
+	_, err := c.Send("Browser.grantPermissions", params)
+	return err
+}
+
+// applyPermissions restores grants on a fresh connection; Chrome drops
+// them together with the session that made them.
+func (c *Chrome) applyPermissions() error {
+	c.Lock()
+	grants := append([]grant(nil), c.grants...)
+	c.Unlock()
+	for _, g := range grants {
+		if err := c.grantPermissions(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
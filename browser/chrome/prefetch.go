@@ -0,0 +1,88 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// prefetchScript fetches every URL into the HTTP cache. HTML documents that
+// are readable (same-origin or CORS-enabled) are parsed and their scripts,
+// stylesheets and images fetched as well, so the page renders from cache
+// too. It resolves to the URLs that failed, with the reason.
+const prefetchScript = `(async (urls) => {
+	const get = async (url) => {
+		try {
+			return await fetch(url, {credentials: "include"});
+		} catch (e) {
+			return fetch(url, {mode: "no-cors", credentials: "include"});
+		}
+	};
+	const failed = [];
+	await Promise.all(urls.map(async (url) => {
+		try {
+			const res = await get(url);
+			if (res.type !== "opaque" && !res.ok) {
+				throw new Error(res.status + " " + res.statusText);
+			}
+			const type = res.headers.get("content-type") || "";
+			if (res.type === "opaque" || !type.includes("text/html")) {
+				await res.arrayBuffer();
+				return;
+			}
+			const doc = new DOMParser().parseFromString(await res.text(), "text/html");
+			const subresources = [...doc.querySelectorAll(
+				"script[src], img[src], link[rel~=stylesheet][href], link[rel~=preload][href], link[rel~=icon][href]"
+			)].map((el) => new URL(el.getAttribute("src") || el.getAttribute("href"), res.url).href);
+			await Promise.allSettled(subresources.map(async (u) => (await get(u)).arrayBuffer()));
+		} catch (e) {
+			failed.push(url + ": " + e.message);
+		}
+	}));
+	return failed;
+})(%s)`
+
+// Prefetch downloads urls into the browser's HTTP cache so that navigating
+// to them later, e.g. the next item of a kiosk playlist, doesn't wait on
+// the network. For HTML pages the scripts, stylesheets and images they
+// reference are fetched too. Responses are cached as their Cache-Control
+// headers allow, so pages served with no-store are not sped up.
+//
+// The requests run in the current page and count against its origin, and
+// Chrome partitions its cache by top-level site, so prefetching pays off
+// for URLs on the same site as the page doing it. Prefetch runs at
+// background priority and returns an error listing the URLs that failed.
+func (c *Chrome) Prefetch(urls ...string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+	arg, err := json.Marshal(urls)
+	if err != nil {
+		return err
+	}
+	raw, err := c.SendPriority(PriorityBackground, "Runtime.evaluate", map[string]interface{}{
+		"expression":    fmt.Sprintf(prefetchScript, arg),
+		"returnByValue": true,
+		"awaitPromise":  true,
+	})
+	if err != nil {
+		return err
+	}
+
+	var res struct {
+		Result struct {
+			Value []string `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *exceptionDetails `json:"exceptionDetails"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if res.ExceptionDetails != nil {
+		return res.ExceptionDetails.jsError()
+	}
+	if len(res.Result.Value) > 0 {
+		return fmt.Errorf("failed to prefetch %s", strings.Join(res.Result.Value, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package chrome_test
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grngxd/majorca/browser/chrome"
+	"github.com/grngxd/majorca/internal/cdptest"
+)
+
+// within fails the test if fn does not return within d.
+func within(t *testing.T, d time.Duration, what string, fn func() error) {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("%s: %v", what, err)
+		}
+	case <-time.After(d):
+		t.Fatalf("%s did not return within %v", what, d)
+	}
+}
+
+func TestAttachEvalKill(t *testing.T) {
+	d := cdptest.New(t, func(target, method string, params json.RawMessage) interface{} {
+		if method == "Runtime.evaluate" {
+			return map[string]interface{}{"result": map[string]interface{}{"type": "number", "value": 2}}
+		}
+		return nil
+	})
+
+	c, err := chrome.Attach(d.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, typ, err := c.Eval("1 + 1")
+	if err != nil || value != "2" || typ != "number" {
+		t.Errorf("Eval = %q, %q, %v; want 2, number", value, typ, err)
+	}
+	if v, err := c.BrowserVersion(); err != nil || v != "120.0.6099.109" {
+		t.Errorf("BrowserVersion = %q, %v", v, err)
+	}
+
+	within(t, 3*time.Second, "Kill", c.Kill)
+	select {
+	case <-c.Done():
+	default:
+		t.Error("Done not closed after Kill")
+	}
+	if _, _, err := c.Eval("1"); err == nil {
+		t.Error("Eval succeeded after Kill")
+	}
+}
+
+func TestKillWindow(t *testing.T) {
+	d := cdptest.New(t, nil)
+
+	c, err := chrome.Attach(d.PageURL("main"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := c.OpenWindow("about:blank")
+	if err != nil {
+		t.Fatal(err)
+	}
+	within(t, 3*time.Second, "window Kill", w.Kill)
+	if !d.Called("Page.close") {
+		t.Error("window Kill did not close the page")
+	}
+	select {
+	case <-c.Done():
+		t.Error("closing a window ended the browser")
+	default:
+	}
+	within(t, 3*time.Second, "Kill", c.Kill)
+}
+
+func TestSendAsyncOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	d := cdptest.New(t, func(target, method string, params json.RawMessage) interface{} {
+		if strings.HasPrefix(method, "Test.") {
+			mu.Lock()
+			order = append(order, method)
+			mu.Unlock()
+		}
+		return nil
+	})
+	c, err := chrome.Attach(d.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Kill()
+
+	// Commands are written before SendAsync returns.
+	var replies []<-chan chrome.Reply
+	for _, method := range []string{"Test.a", "Test.b", "Test.c"} {
+		replies = append(replies, c.SendAsync(method, nil))
+	}
+	for _, ch := range replies {
+		if r := <-ch; r.Err != nil {
+			t.Fatal(r.Err)
+		}
+	}
+
+	// Middleware that holds the first command back must not let the second
+	// overtake it.
+	c.Use(func(next chrome.Handler) chrome.Handler {
+		return func(cmd chrome.Command) (json.RawMessage, error) {
+			if cmd.Method == "Test.first" {
+				time.Sleep(50 * time.Millisecond)
+			}
+			return next(cmd)
+		}
+	})
+	first := c.SendAsync("Test.first", nil)
+	second := c.SendAsync("Test.second", nil)
+	for _, ch := range []<-chan chrome.Reply{first, second} {
+		if r := <-ch; r.Err != nil {
+			t.Fatal(r.Err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(order, ",") != "Test.a,Test.b,Test.c,Test.first,Test.second" {
+		t.Errorf("commands written in order %v", order)
+	}
+}
@@ -0,0 +1,113 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// publishBinding carries messages published by the page to Go.
+const publishBinding = "__majorcaPublish"
+
+// pubsubScript adds the page side of the message bus to window.majorca:
+//
+//	majorca.subscribe(topic, fn)   fn(payload) for messages on topic; returns an unsubscribe function
+//	majorca.publish(topic, data)   deliver to Go handlers and local subscribers
+const pubsubScript = `(() => {
+	const m = window.majorca = window.majorca || {};
+	if (m.publish) return;
+	const topics = new Map();
+	m.subscribe = (topic, fn) => {
+		if (!topics.has(topic)) topics.set(topic, new Set());
+		topics.get(topic).add(fn);
+		return () => topics.get(topic).delete(fn);
+	};
+	m.__receive = (topic, data) => {
+		for (const fn of topics.get(topic) || []) {
+			try { fn(data); } catch (e) { console.error(e); }
+		}
+	};
+	m.publish = (topic, data) => {
+		m.__receive(topic, data);
+		return window.%s(topic, data === undefined ? null : data);
+	};
+})()`
+
+type subscriber struct {
+	handler func(payload json.RawMessage)
+}
+
+type pubsub struct {
+	once sync.Once
+	err  error
+	mu   sync.Mutex
+	subs map[string][]*subscriber
+}
+
+// setupPubsub installs the binding and page script on first use.
+func (c *Chrome) setupPubsub() error {
+	ps := &c.pubsub
+	ps.once.Do(func() {
+		ps.err = c.Bind(publishBinding, func(args []json.RawMessage) (interface{}, error) {
+			var topic string
+			if len(args) != 2 || json.Unmarshal(args[0], &topic) != nil {
+				return nil, fmt.Errorf("malformed publish call")
+			}
+			ps.mu.Lock()
+			subs := append([]*subscriber(nil), ps.subs[topic]...)
+			ps.mu.Unlock()
+			for _, s := range subs {
+				s.handler(args[1])
+			}
+			return nil, nil
+		})
+		if ps.err == nil {
+			ps.err = c.AddInitScript(fmt.Sprintf(pubsubScript, publishBinding))
+		}
+	})
+	return ps.err
+}
+
+// Publish marshals payload to JSON and delivers it to the page's
+// majorca.subscribe handlers for topic. Messages published while a page is
+// loading may be lost.
+func (c *Chrome) Publish(topic string, payload interface{}) error {
+	if err := c.setupPubsub(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	_, _, err = c.Eval(fmt.Sprintf(`window.majorca && window.majorca.__receive(%s, %s)`, quote(topic), data))
+	return err
+}
+
+// Subscribe calls handler with the JSON payload of every message the page
+// publishes on topic with majorca.publish. The returned function
+// unsubscribes.
+func (c *Chrome) Subscribe(topic string, handler func(payload json.RawMessage)) (func(), error) {
+	if err := c.setupPubsub(); err != nil {
+		return nil, err
+	}
+	s := &subscriber{handler}
+	ps := &c.pubsub
+	ps.mu.Lock()
+	if ps.subs == nil {
+		ps.subs = make(map[string][]*subscriber)
+	}
+	ps.subs[topic] = append(ps.subs[topic], s)
+	ps.mu.Unlock()
+
+	return func() {
+		ps.mu.Lock()
+		defer ps.mu.Unlock()
+		list := ps.subs[topic]
+		for i, x := range list {
+			if x == s {
+				ps.subs[topic] = append(list[:i:i], list[i+1:]...)
+				return
+			}
+		}
+	}, nil
+}
@@ -0,0 +1,148 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+const (
+	reconnectAttempts = 3
+	reconnectDelay    = 500 * time.Millisecond
+)
+
+// EventReconnected is emitted after the DevTools connection dropped and was
+// re-established. Its params are {"cause": "<why it dropped>"}.
+const EventReconnected = "majorca.reconnected"
+
+// reconnect re-dials the page target after the DevTools socket dropped.
+// Commands that were in flight are failed with ErrConnectionLost because
+// their responses were lost with the old socket. It reports false when the
+// browser is shutting down or the target no longer exists.
+func (c *Chrome) reconnect(cause error) bool {
+	for i := 0; i < reconnectAttempts; i++ {
+		select {
+		case <-c.Stop:
+			return false
+		case <-c.Done():
+			return false
+		case <-time.After(reconnectDelay):
+		}
+
+		ws, err := c.Dial(c.wsURL)
+		if err != nil {
+			c.Logger().Debug("DevTools reconnect attempt failed", "attempt", i+1, "error", err)
+			continue
+		}
+
+		c.Lock()
+		if c.Stopping() {
+			// Kill ran while dialing and already closed the old socket.
+			c.Unlock()
+			ws.Close()
+			return false
+		}
+		old := c.Ws
+		c.Ws = ws
+		c.Unlock()
+		old.Close()
+
+		c.Logger().Warn("DevTools connection re-established", "cause", cause)
+		c.FailPending(browser.ErrConnectionLost)
+		go c.rebind()
+		params, _ := json.Marshal(map[string]string{"cause": fmt.Sprint(cause)})
+		c.Emit(browser.Event{Method: EventReconnected, Params: params})
+		return true
+	}
+	return false
+}
+
+// rebind restores per-session state on a fresh connection: navigation
+// tracking, bindings and init scripts.
+func (c *Chrome) rebind() {
+	if err := c.trackNavigations(); err != nil {
+		c.Logger().Error("failed to re-enable navigation tracking", "error", err)
+		return
+	}
+
+	if c.deterministic {
+		if err := c.applyDeterministic(); err != nil {
+			c.Logger().Error("failed to re-apply deterministic rendering", "error", err)
+		}
+	}
+	if err := c.applyFonts(); err != nil {
+		c.Logger().Error("failed to re-apply font settings", "error", err)
+	}
+	if err := c.applyDownloads(); err != nil {
+		c.Logger().Error("failed to re-apply download behavior", "error", err)
+	}
+	if err := c.applyIntercepts(); err != nil {
+		c.Logger().Error("failed to re-enable request interception", "error", err)
+	}
+	if err := c.applyMute(); err != nil {
+		c.Logger().Error("failed to re-apply content settings", "error", err)
+	}
+	c.visibility.mu.Lock()
+	visibility := c.visibility.enabled
+	c.visibility.mu.Unlock()
+	if visibility {
+		if err := c.applyVisibility(); err != nil {
+			c.Logger().Error("failed to re-add visibility binding", "error", err)
+		}
+	}
+	if err := c.applyPermissions(); err != nil {
+		c.Logger().Error("failed to re-grant permissions", "error", err)
+	}
+	if err := c.applyWatches(); err != nil {
+		c.Logger().Error("failed to re-install watch expressions", "error", err)
+	}
+	c.appearance.mu.Lock()
+	c.appearance.identifier = "" // Init scripts died with the old session
+	pinned := c.appearance.pinned()
+	c.appearance.mu.Unlock()
+	if pinned {
+		if err := c.applyAppearance(); err != nil {
+			c.Logger().Error("failed to re-apply window title, icon and zoom", "error", err)
+		}
+	}
+	c.Lock()
+	headers, ua, metrics := c.extraHeaders, c.userAgent, c.metrics
+	c.Unlock()
+	if metrics != nil {
+		if err := c.applyDeviceMetrics(*metrics); err != nil {
+			c.Logger().Error("failed to re-apply device metrics", "error", err)
+		}
+	}
+	if headers != nil {
+		if err := c.applyHeaders(); err != nil {
+			c.Logger().Error("failed to re-apply extra headers", "error", err)
+		}
+	}
+	if ua != "" {
+		if err := c.SetUserAgent(ua); err != nil {
+			c.Logger().Error("failed to re-apply user agent", "error", err)
+		}
+	}
+
+	c.Lock()
+	names := make([]string, 0, len(c.Bindings))
+	for name := range c.Bindings {
+		names = append(names, name)
+	}
+	scripts := append([]string(nil), c.initScripts...)
+	c.Unlock()
+
+	for _, name := range names {
+		if err := c.installBinding(name); err != nil {
+			c.Logger().Error("failed to re-register binding", "name", name, "error", err)
+		}
+	}
+	for i, source := range scripts {
+		script := fmt.Sprintf(initGuard, i+1, i+1, source)
+		if _, err := c.Send("Page.addScriptToEvaluateOnNewDocument", map[string]interface{}{"source": script}); err != nil {
+			c.Logger().Error("failed to re-register init script", "error", err)
+		}
+	}
+}
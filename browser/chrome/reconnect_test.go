@@ -0,0 +1,100 @@
+package chrome_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+	"github.com/grngxd/majorca/browser/chrome"
+	"github.com/grngxd/majorca/internal/cdptest"
+)
+
+func TestReconnectRebinds(t *testing.T) {
+	var mu sync.Mutex
+	dropped := false
+	var rebound []string
+	hang := make(chan struct{})
+	defer close(hang)
+	d := cdptest.New(t, func(target, method string, params json.RawMessage) interface{} {
+		var p struct {
+			Name   string `json:"name"`
+			Source string `json:"source"`
+		}
+		json.Unmarshal(params, &p)
+		mu.Lock()
+		after := dropped
+		mu.Unlock()
+		switch {
+		case method == "Test.hang":
+			<-hang
+		case after && method == "Runtime.addBinding":
+			mu.Lock()
+			rebound = append(rebound, "binding "+p.Name)
+			mu.Unlock()
+		case after && method == "Page.addScriptToEvaluateOnNewDocument" && strings.Contains(p.Source, "initMarker"):
+			mu.Lock()
+			rebound = append(rebound, "init script")
+			mu.Unlock()
+		}
+		return nil
+	})
+	c, err := chrome.Attach(d.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Kill()
+
+	if err := c.Bind("hello", func([]json.RawMessage) (interface{}, error) { return nil, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddInitScript("window.initMarker = 1"); err != nil {
+		t.Fatal(err)
+	}
+	reconnected := make(chan struct{}, 1)
+	c.On(chrome.EventReconnected, func(browser.Event) {
+		reconnected <- struct{}{}
+	})
+
+	inflight := c.SendAsync("Test.hang", nil)
+	mu.Lock()
+	dropped = true
+	mu.Unlock()
+	d.Drop("main")
+
+	select {
+	case r := <-inflight:
+		if !errors.Is(r.Err, browser.ErrConnectionLost) {
+			t.Errorf("in-flight command failed with %v, want ErrConnectionLost", r.Err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("in-flight command not failed after reconnect")
+	}
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reconnect event")
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		got := strings.Join(rebound, ",")
+		mu.Unlock()
+		if strings.Contains(got, "binding hello") && strings.Contains(got, "init script") {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if _, _, err := c.Eval("1"); err != nil {
+		t.Errorf("Eval after reconnect: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if got := strings.Join(rebound, ","); !strings.Contains(got, "binding hello") || !strings.Contains(got, "init script") {
+		t.Errorf("re-applied on the new connection: %s; want the binding and the init script", got)
+	}
+}
@@ -0,0 +1,17 @@
+package chrome
+
+import "github.com/grngxd/majorca/browser"
+
+func init() {
+	browser.Register("chrome", browser.Factory{
+		New: func(opts ...browser.Option) (browser.Browser, error) {
+			return New(opts...)
+		},
+		Available: func() bool {
+			_, err := FindPath()
+			return err == nil
+		},
+		Priority:    100,
+		Description: "Chrome, Chromium or Edge via the DevTools Protocol",
+	})
+}
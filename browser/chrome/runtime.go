@@ -0,0 +1,36 @@
+package chrome
+
+import (
+	"fmt"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// runtimeScript is the majorca.js bootstrap every page gets, so the
+// frontend can rely on window.majorca after reloads and navigations:
+//
+//	majorca.version              version of the Go module
+//	majorca.backend              "chrome"
+//	majorca.call(name, ...args)  call a Go binding, resolves to its result
+//	majorca.has(name)            whether a Go binding is installed
+//	majorca.publish / subscribe  the message bus, see Chrome.Publish
+//
+// Bindings can be called directly as window[name] too; majorca.call only
+// adds a clear error for names that are not bound.
+const runtimeScript = `(() => {
+	const m = window.majorca = window.majorca || {};
+	m.version = %s;
+	m.backend = "chrome";
+	m.has = (name) => typeof window[name] === "function" && !!window[name].majorca;
+	m.call = (name, ...args) => m.has(name)
+		? window[name](...args)
+		: Promise.reject(new Error("majorca: no Go binding named " + name));
+})()`
+
+// installRuntime injects the majorca.js bootstrap and message bus.
+func (c *Chrome) installRuntime() error {
+	if err := c.AddInitScript(fmt.Sprintf(runtimeScript, quote(browser.ModuleVersion()))); err != nil {
+		return err
+	}
+	return c.setupPubsub()
+}
@@ -0,0 +1,102 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// scrollSettle is how long ScrollUntil waits for lazy content after each step.
+const scrollSettle = 300 * time.Millisecond
+
+// ScrollTo scrolls the first element matching selector into view.
+func (c *Chrome) ScrollTo(selector string) error {
+	ok, err := c.evalBool(fmt.Sprintf(`(() => {
+		const el = document.querySelector(%s);
+		if (!el) return false;
+		el.scrollIntoView({block: "center"});
+		return true;
+	})()`, quote(selector)))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("no element matches %s", selector)
+	}
+	return nil
+}
+
+// ScrollBy scrolls the page by dx, dy pixels using a synthetic mouse wheel
+// event at the centre of the viewport, so wheel listeners fire as they would
+// for a real user.
+func (c *Chrome) ScrollBy(dx, dy int) error {
+	raw, err := c.Send("Runtime.evaluate", map[string]interface{}{
+		"expression":    "[window.innerWidth / 2, window.innerHeight / 2]",
+		"returnByValue": true,
+	})
+	if err != nil {
+		return err
+	}
+	var res struct {
+		Result struct {
+			Value []float64 `json:"value"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil || len(res.Result.Value) != 2 {
+		return fmt.Errorf("failed to read viewport size")
+	}
+
+	_, err = c.Send("Input.dispatchMouseEvent", map[string]interface{}{
+		"type":   "mouseWheel",
+		"x":      res.Result.Value[0],
+		"y":      res.Result.Value[1],
+		"deltaX": dx,
+		"deltaY": dy,
+	})
+	return err
+}
+
+// ScrollUntil scrolls down one viewport at a time until the JavaScript
+// predicate evaluates to true, harvesting lazily loaded content on the way.
+// It gives up after maxSteps scrolls, or earlier once the end of the page is
+// reached and no new content arrives. maxSteps must be positive.
+func (c *Chrome) ScrollUntil(predicate string, maxSteps int) error {
+	if maxSteps <= 0 {
+		return fmt.Errorf("ScrollUntil needs a positive step limit, got %d", maxSteps)
+	}
+	for step := 0; ; step++ {
+		ok, err := c.evalBool(fmt.Sprintf("!!(%s)", predicate))
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if step >= maxSteps {
+			return fmt.Errorf("predicate not met after %d scroll steps", maxSteps)
+		}
+
+		height, _, err := c.Eval("document.documentElement.scrollHeight")
+		if err != nil {
+			return err
+		}
+		if _, _, err := c.Eval("window.scrollBy(0, window.innerHeight)"); err != nil {
+			return err
+		}
+		time.Sleep(scrollSettle)
+
+		atEnd, err := c.evalBool(fmt.Sprintf(
+			"window.innerHeight + window.scrollY >= document.documentElement.scrollHeight && document.documentElement.scrollHeight == %s",
+			height))
+		if err != nil {
+			return err
+		}
+		if atEnd {
+			ok, err := c.evalBool(fmt.Sprintf("!!(%s)", predicate))
+			if err != nil || ok {
+				return err
+			}
+			return fmt.Errorf("reached the end of the page after %d scroll steps", step+1)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package chrome_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grngxd/majorca/browser/chrome"
+	"github.com/grngxd/majorca/internal/cdptest"
+)
+
+func TestScrollUntilStepLimit(t *testing.T) {
+	d := cdptest.New(t, func(target, method string, params json.RawMessage) interface{} {
+		if method == "Runtime.evaluate" {
+			return map[string]interface{}{"result": map[string]interface{}{"type": "boolean", "value": false}}
+		}
+		return nil
+	})
+	c, err := chrome.Attach(d.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Kill()
+
+	for _, steps := range []int{0, -1} {
+		if err := c.ScrollUntil("false", steps); err == nil {
+			t.Errorf("ScrollUntil with %d steps succeeded", steps)
+		}
+	}
+}
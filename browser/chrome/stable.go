@@ -0,0 +1,75 @@
+package chrome
+
+import (
+	"strings"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// contextLostErrors are DevTools error messages for an evaluation whose
+// document went away, usually because the page navigated meanwhile.
+var contextLostErrors = []string{
+	"Execution context was destroyed",
+	"Cannot find context with specified id",
+	"Inspected target navigated or closed",
+}
+
+// EvalOptions controls EvalStable.
+type EvalOptions struct {
+	// Retries is how often the expression is re-run after its document was
+	// replaced. Zero means 3; a negative value disables retries.
+	Retries int
+	// Wait bounds how long each retry waits for the next document to be
+	// ready. Zero means 5 seconds.
+	Wait time.Duration
+}
+
+// EvalStable is Eval for pages that may navigate at any moment. When the
+// document the expression runs in is destroyed mid-call, it waits for the
+// next document to be ready and evaluates expr again there instead of
+// returning the error. The expression may therefore run more than once.
+func (c *Chrome) EvalStable(expr string, opts EvalOptions) (string, string, error) {
+	if opts.Retries == 0 {
+		opts.Retries = 3
+	}
+	if opts.Wait == 0 {
+		opts.Wait = 5 * time.Second
+	}
+
+	ready := make(chan struct{}, 1)
+	off := c.On(EventReady, func(browser.Event) {
+		select {
+		case ready <- struct{}{}:
+		default:
+		}
+	})
+	defer off()
+
+	for attempt := 0; ; attempt++ {
+		value, typ, err := c.Eval(expr)
+		if err == nil || !isContextLost(err) || attempt >= opts.Retries {
+			return value, typ, err
+		}
+		c.Logger().Debug("execution context lost, retrying evaluation", "attempt", attempt+1, "error", err)
+
+		select {
+		case <-ready:
+		case <-time.After(opts.Wait):
+		case <-c.Done():
+			return "", "", browser.ErrConnectionClosed
+		}
+	}
+}
+
+// isContextLost reports whether err means the evaluation's document was
+// replaced before it finished.
+func isContextLost(err error) bool {
+	msg := err.Error()
+	for _, s := range contextLostErrors {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package chrome_test
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grngxd/majorca/browser/chrome"
+	"github.com/grngxd/majorca/internal/cdptest"
+)
+
+func TestEvalStableRetries(t *testing.T) {
+	var mu sync.Mutex
+	evals := 0
+	d := cdptest.New(t, func(target, method string, params json.RawMessage) interface{} {
+		if method != "Runtime.evaluate" {
+			return nil
+		}
+		var p struct {
+			Expression string `json:"expression"`
+		}
+		json.Unmarshal(params, &p)
+		if p.Expression != "probe()" {
+			return nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		evals++
+		return errors.New("Execution context was destroyed.")
+	})
+	c, err := chrome.Attach(d.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Kill()
+
+	for _, tc := range []struct{ retries, want int }{{-1, 1}, {2, 3}} {
+		mu.Lock()
+		evals = 0
+		mu.Unlock()
+		within(t, 3*time.Second, "EvalStable", func() error {
+			_, _, err := c.EvalStable("probe()", chrome.EvalOptions{Retries: tc.retries, Wait: time.Millisecond})
+			if err == nil {
+				return errors.New("succeeded on a destroyed context")
+			}
+			return nil
+		})
+		mu.Lock()
+		if evals != tc.want {
+			t.Errorf("Retries %d: evaluated %d times, want %d", tc.retries, evals, tc.want)
+		}
+		mu.Unlock()
+	}
+}
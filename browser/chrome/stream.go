@@ -0,0 +1,199 @@
+package chrome
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// streamChunk is how many bytes OnDownload reads from Chrome at a time.
+const streamChunk = 1 << 20
+
+// DownloadMeta describes a download handed to an OnDownload handler.
+type DownloadMeta struct {
+	URL      string
+	Filename string // From Content-Disposition, else the last path segment
+	MIMEType string
+	Size     int64 // -1 when the server sent no Content-Length
+	Headers  map[string]string
+}
+
+// OnDownload hands downloads to handler as a stream instead of saving them
+// to disk. Responses served as attachments are taken over before Chrome
+// writes anything; handler runs on its own goroutine and reads the body from
+// r as it arrives. Returning early cancels the rest of the transfer. The
+// page sees the download as aborted. The returned function restores normal
+// downloads.
+func (c *Chrome) OnDownload(handler func(meta DownloadMeta, r io.Reader)) (func(), error) {
+	c.intercepts.mu.Lock()
+	c.intercepts.downloads = handler
+	subscribed := c.intercepts.subscribed
+	c.intercepts.subscribed = true
+	c.intercepts.mu.Unlock()
+
+	if !subscribed {
+		c.On("Fetch.requestPaused", c.onRequestPaused)
+	}
+	if err := c.applyIntercepts(); err != nil {
+		return nil, err
+	}
+	return func() {
+		c.intercepts.mu.Lock()
+		c.intercepts.downloads = nil
+		c.intercepts.mu.Unlock()
+		c.applyIntercepts()
+	}, nil
+}
+
+// pausedResponse hands a Fetch.requestPaused event at the response stage to
+// onResponsePaused and reports whether it was one.
+func (c *Chrome) pausedResponse(e browser.Event) bool {
+	var p struct {
+		RequestID          string        `json:"requestId"`
+		ResponseStatusCode int           `json:"responseStatusCode"`
+		ResponseError      string        `json:"responseErrorReason"`
+		ResponseHeaders    []headerEntry `json:"responseHeaders"`
+		Request            struct {
+			URL string `json:"url"`
+		} `json:"request"`
+	}
+	if json.Unmarshal(e.Params, &p) != nil || (p.ResponseStatusCode == 0 && p.ResponseError == "") {
+		return false
+	}
+	go c.onResponsePaused(p.RequestID, p.Request.URL, p.ResponseStatusCode, p.ResponseHeaders)
+	return true
+}
+
+// onResponsePaused decides whether a response paused at the headers stage
+// is a download and streams it to the OnDownload handler if so.
+func (c *Chrome) onResponsePaused(requestID, rawURL string, status int, headers []headerEntry) {
+	c.intercepts.mu.Lock()
+	handler := c.intercepts.downloads
+	c.intercepts.mu.Unlock()
+
+	h := make(map[string]string, len(headers))
+	for _, e := range headers {
+		h[strings.ToLower(e.Name)] = e.Value
+	}
+	name, ok := attachmentName(h["content-disposition"], rawURL)
+	if handler == nil || !ok || status < 200 || status >= 300 {
+		c.continueResponse(requestID, rawURL, status, headers)
+		return
+	}
+
+	raw, err := c.Send("Fetch.takeResponseBodyAsStream", map[string]interface{}{"requestId": requestID})
+	if err != nil {
+		c.Logger().Error("failed to take download stream", "url", rawURL, "error", err)
+		c.Send("Fetch.continueRequest", map[string]interface{}{"requestId": requestID})
+		return
+	}
+	// The response can't be continued once its body was taken.
+	defer c.Send("Fetch.failRequest", map[string]interface{}{"requestId": requestID, "errorReason": "Aborted"})
+
+	var res struct {
+		Stream string `json:"stream"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		c.Logger().Error("failed to unmarshal download stream", "error", err)
+		return
+	}
+	defer c.Send("IO.close", map[string]interface{}{"handle": res.Stream})
+
+	meta := DownloadMeta{URL: rawURL, Filename: name, Size: -1, Headers: h}
+	meta.MIMEType, _, _ = mime.ParseMediaType(h["content-type"])
+	if n, err := strconv.ParseInt(h["content-length"], 10, 64); err == nil {
+		meta.Size = n
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler(meta, pr)
+		pr.Close()
+	}()
+	pw.CloseWithError(c.readStream(res.Stream, pw))
+	<-done
+}
+
+// continueResponse lets a paused response through, adding the
+// Content-Security-Policy of any SetContentSettings for its origin.
+func (c *Chrome) continueResponse(requestID, rawURL string, status int, headers []headerEntry) {
+	method, params := "Fetch.continueRequest", map[string]interface{}{"requestId": requestID}
+	if policy := c.contentPolicy(rawURL); policy != "" {
+		method = "Fetch.continueResponse"
+		params["responseCode"] = status
+		params["responseHeaders"] = append(headers[:len(headers):len(headers)], headerEntry{
+			Name:  "Content-Security-Policy",
+			Value: policy,
+		})
+	}
+	if _, err := c.Send(method, params); err != nil {
+		c.Logger().Error("failed to continue response", "url", rawURL, "error", err)
+	}
+}
+
+// readStream copies an IO stream handle to w until it ends or w fails.
+func (c *Chrome) readStream(handle string, w io.Writer) error {
+	for {
+		raw, err := c.SendPriority(PriorityBackground, "IO.read", map[string]interface{}{
+			"handle": handle,
+			"size":   streamChunk,
+		})
+		if err != nil {
+			return err
+		}
+		var chunk struct {
+			Base64Encoded bool   `json:"base64Encoded"`
+			Data          string `json:"data"`
+			EOF           bool   `json:"eof"`
+		}
+		if err := json.Unmarshal(raw, &chunk); err != nil {
+			return fmt.Errorf("failed to unmarshal stream chunk: %w", err)
+		}
+		data := []byte(chunk.Data)
+		if chunk.Base64Encoded {
+			if data, err = base64.StdEncoding.DecodeString(chunk.Data); err != nil {
+				return fmt.Errorf("failed to decode stream chunk: %w", err)
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		if chunk.EOF {
+			return nil
+		}
+	}
+}
+
+// attachmentName reports whether a Content-Disposition header marks a
+// download and the file name to use for it.
+func attachmentName(disposition, rawURL string) (string, bool) {
+	kind, params, err := mime.ParseMediaType(disposition)
+	if err != nil || kind != "attachment" {
+		return "", false
+	}
+	if name := path.Base(params["filename"]); params["filename"] != "" && name != "/" && name != "." {
+		return name, true
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		if name := path.Base(u.Path); name != "/" && name != "." {
+			return name, true
+		}
+	}
+	return "download", true
+}
+
+// headerEntry is a header as listed by the Fetch domain.
+type headerEntry struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
@@ -0,0 +1,71 @@
+package chrome
+
+// suspendScript tells the page it is about to be frozen and pauses media
+// that is playing, remembering it for resumeScript.
+const suspendScript = `(() => {
+	window.dispatchEvent(new Event("majorca:suspend"));
+	const paused = window.__majorcaPaused = [];
+	for (const m of document.querySelectorAll("audio, video")) {
+		if (!m.paused) {
+			m.pause();
+			paused.push(m);
+		}
+	}
+})()`
+
+const resumeScript = `(() => {
+	for (const m of window.__majorcaPaused || []) m.play().catch(() => {});
+	window.__majorcaPaused = [];
+	window.dispatchEvent(new Event("majorca:resume"));
+})()`
+
+// Suspend freezes the page to save power, e.g. while the window is hidden:
+// media is paused, timers and scripts stop and the page receives no events.
+// Before that the page gets a "majorca:suspend" event on window so it can
+// save state. Evaluating scripts fails until Resume is called.
+func (c *Chrome) Suspend() error {
+	c.Lock()
+	if c.suspended {
+		c.Unlock()
+		return nil
+	}
+	c.suspended = true
+	c.Unlock()
+
+	if _, _, err := c.Eval(suspendScript); err != nil {
+		c.Logger().Debug("failed to notify page of suspend", "error", err)
+	}
+	if _, err := c.Send("Emulation.setScriptExecutionDisabled", map[string]interface{}{"value": true}); err != nil {
+		return err
+	}
+	_, err := c.Send("Page.setWebLifecycleState", map[string]interface{}{"state": "frozen"})
+	return err
+}
+
+// Resume undoes Suspend, restarts the media it paused and sends the page a
+// "majorca:resume" event.
+func (c *Chrome) Resume() error {
+	c.Lock()
+	if !c.suspended {
+		c.Unlock()
+		return nil
+	}
+	c.suspended = false
+	c.Unlock()
+
+	if _, err := c.Send("Page.setWebLifecycleState", map[string]interface{}{"state": "active"}); err != nil {
+		return err
+	}
+	if _, err := c.Send("Emulation.setScriptExecutionDisabled", map[string]interface{}{"value": false}); err != nil {
+		return err
+	}
+	_, _, err := c.Eval(resumeScript)
+	return err
+}
+
+// Suspended reports whether the page is frozen by Suspend.
+func (c *Chrome) Suspended() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.suspended
+}
@@ -0,0 +1,118 @@
+package chrome
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// MethodStats aggregates the traffic of one DevTools method.
+type MethodStats struct {
+	Method        string
+	Calls         int // Commands sent
+	Errors        int // Commands that failed or timed out
+	TotalLatency  time.Duration
+	MaxLatency    time.Duration
+	BytesSent     int64
+	BytesReceived int64
+	Events        int // Events received under this name
+	EventBytes    int64
+}
+
+// AvgLatency is the mean round trip of the method's commands.
+func (s MethodStats) AvgLatency() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Calls)
+}
+
+// Telemetry is a snapshot of the protocol traffic since Since.
+type Telemetry struct {
+	Since   time.Time
+	Methods []MethodStats // Most total latency first
+}
+
+type telemetry struct {
+	mu      sync.Mutex
+	since   time.Time
+	methods map[string]*MethodStats
+	slow    time.Duration // Log commands slower than this; zero disables
+	large   int           // Log commands with bigger payloads; zero disables
+}
+
+// Telemetry returns per-method latency, payload size and event counts,
+// to find the interactions that make an app feel sluggish.
+func (c *Chrome) Telemetry() Telemetry {
+	t := &c.telemetry
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	snap := Telemetry{Since: t.since, Methods: make([]MethodStats, 0, len(t.methods))}
+	for _, s := range t.methods {
+		snap.Methods = append(snap.Methods, *s)
+	}
+	sort.Slice(snap.Methods, func(i, j int) bool {
+		a, b := snap.Methods[i], snap.Methods[j]
+		if a.TotalLatency != b.TotalLatency {
+			return a.TotalLatency > b.TotalLatency
+		}
+		return a.Method < b.Method
+	})
+	return snap
+}
+
+// ResetTelemetry clears the collected statistics.
+func (c *Chrome) ResetTelemetry() {
+	t := &c.telemetry
+	t.mu.Lock()
+	t.methods = nil
+	t.since = time.Now()
+	t.mu.Unlock()
+}
+
+// stats returns the entry for method; t.mu must be held.
+func (t *telemetry) stats(method string) *MethodStats {
+	if t.methods == nil {
+		t.methods = make(map[string]*MethodStats)
+		if t.since.IsZero() {
+			t.since = time.Now()
+		}
+	}
+	s, ok := t.methods[method]
+	if !ok {
+		s = &MethodStats{Method: method}
+		t.methods[method] = s
+	}
+	return s
+}
+
+// command records a finished command and logs it if it crossed a
+// threshold.
+func (t *telemetry) command(c *Chrome, id int32, method string, latency time.Duration, sent, received int, err error) {
+	t.mu.Lock()
+	s := t.stats(method)
+	s.Calls++
+	if err != nil {
+		s.Errors++
+	}
+	s.TotalLatency += latency
+	s.MaxLatency = max(s.MaxLatency, latency)
+	s.BytesSent += int64(sent)
+	s.BytesReceived += int64(received)
+	slow := t.slow > 0 && latency > t.slow
+	large := t.large > 0 && (sent > t.large || received > t.large)
+	t.mu.Unlock()
+
+	if slow || large {
+		c.Logger().Warn("slow DevTools command", "id", id, "method", method,
+			"latency", latency, "sent", sent, "received", received)
+	}
+}
+
+func (t *telemetry) event(method string, size int) {
+	t.mu.Lock()
+	s := t.stats(method)
+	s.Events++
+	s.EventBytes += int64(size)
+	t.mu.Unlock()
+}
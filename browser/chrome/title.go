@@ -0,0 +1,125 @@
+package chrome
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// appearanceScript pins the document title, favicon and zoom: pages that
+// set their own are overridden as soon as the DOM changes.
+const appearanceScript = `(() => {
+	const title = %s, icon = %s, zoom = %s;
+	if (window.__majorcaAppearance) window.__majorcaAppearance.disconnect();
+	document.querySelectorAll("link[data-majorca]").forEach((l) => l.remove());
+	if (window.__majorcaZoom && document.documentElement) document.documentElement.style.removeProperty("zoom");
+	window.__majorcaZoom = zoom;
+	if (!title && !icon && !zoom) return;
+	const pin = () => {
+		if (title && document.title !== title) document.title = title;
+		const root = document.documentElement;
+		if (zoom && root && root.style.zoom !== zoom) root.style.zoom = zoom;
+		if (!icon || !document.head) return;
+		document.head.querySelectorAll('link[rel~="icon"]').forEach((l) => {
+			if (!l.hasAttribute("data-majorca")) l.remove();
+		});
+		if (!document.head.querySelector("link[data-majorca]")) {
+			const link = document.createElement("link");
+			link.rel = "icon";
+			link.href = icon;
+			link.setAttribute("data-majorca", "");
+			document.head.appendChild(link);
+		}
+	};
+	const observer = new MutationObserver(pin);
+	observer.observe(document, {subtree: true, childList: true, characterData: true});
+	window.__majorcaAppearance = observer;
+	pin();
+})()`
+
+type appearance struct {
+	mu         sync.Mutex
+	title      string
+	icon       string // data URL
+	zoom       string // CSS zoom factor, empty for none
+	identifier string // Page.addScriptToEvaluateOnNewDocument handle
+}
+
+// SetTitle sets the window title and keeps it across navigations, even if
+// pages set their own. An empty title stops pinning it.
+func (c *Chrome) SetTitle(title string) error {
+	c.appearance.mu.Lock()
+	c.appearance.title = title
+	c.appearance.mu.Unlock()
+	return c.applyAppearance()
+}
+
+// SetIcon sets the window icon from PNG, ICO, GIF, JPEG or SVG data by
+// replacing the page favicon, across navigations. Chrome uses the favicon of
+// app windows as taskbar icon on Windows and Linux; macOS always shows the
+// Chrome icon in the Dock. Nil data stops pinning the icon.
+func (c *Chrome) SetIcon(data []byte) error {
+	var icon string
+	if data != nil {
+		icon = "data:" + iconType(data) + ";base64," + base64.StdEncoding.EncodeToString(data)
+	}
+	c.appearance.mu.Lock()
+	c.appearance.icon = icon
+	c.appearance.mu.Unlock()
+	return c.applyAppearance()
+}
+
+// SetIconFile is SetIcon with the icon read from path.
+func (c *Chrome) SetIconFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read icon: %w", err)
+	}
+	return c.SetIcon(data)
+}
+
+// iconType sniffs the MIME type of icon data.
+func iconType(data []byte) string {
+	if bytes.Contains(data[:min(len(data), 512)], []byte("<svg")) {
+		return "image/svg+xml"
+	}
+	return http.DetectContentType(data)
+}
+
+func (a *appearance) pinned() bool {
+	return a.title != "" || a.icon != "" || a.zoom != ""
+}
+
+// applyAppearance replaces the init script pinning title and icon and
+// applies it to the current document. It also restores them after a
+// reconnect.
+func (c *Chrome) applyAppearance() error {
+	a := &c.appearance
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.identifier != "" {
+		c.Send("Page.removeScriptToEvaluateOnNewDocument", map[string]interface{}{"identifier": a.identifier})
+		a.identifier = ""
+	}
+	script := fmt.Sprintf(appearanceScript, quote(a.title), quote(a.icon), quote(a.zoom))
+	if a.pinned() {
+		raw, err := c.Send("Page.addScriptToEvaluateOnNewDocument", map[string]interface{}{"source": script})
+		if err != nil {
+			return err
+		}
+		var res struct {
+			Identifier string `json:"identifier"`
+		}
+		if err := json.Unmarshal(raw, &res); err != nil {
+			return fmt.Errorf("failed to unmarshal script identifier: %w", err)
+		}
+		a.identifier = res.Identifier
+	}
+	_, err := c.Send("Runtime.evaluate", map[string]interface{}{"expression": script})
+	return err
+}
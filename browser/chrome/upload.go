@@ -0,0 +1,207 @@
+package chrome
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// UploadState is the stage of an upload reported to OnUpload handlers.
+type UploadState string
+
+const (
+	UploadStarted  UploadState = "started"  // Request body is being sent
+	UploadSent     UploadState = "sent"     // Server started responding
+	UploadFinished UploadState = "finished" // Response fully received
+	UploadFailed   UploadState = "failed"
+)
+
+// Upload describes a request with a body, as seen on the network.
+type Upload struct {
+	RequestID string
+	URL       string
+	Method    string
+	Size      int64 // Body size in bytes, -1 when Chrome does not report it
+	State     UploadState
+	Error     string // Set for UploadFailed
+}
+
+// DropFiles drops the given files onto the first element matching selector,
+// firing dragenter, dragover and drop with a DataTransfer holding them, as
+// if the user dragged them in from a file manager.
+func (c *Chrome) DropFiles(selector string, paths ...string) error {
+	type file struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+		Data string `json:"data"`
+	}
+	files := make([]file, 0, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		typ, _, _ := strings.Cut(mime.TypeByExtension(filepath.Ext(p)), ";")
+		files = append(files, file{
+			Name: filepath.Base(p),
+			Type: typ,
+			Data: base64.StdEncoding.EncodeToString(data),
+		})
+	}
+	payload, err := json.Marshal(files)
+	if err != nil {
+		return fmt.Errorf("failed to marshal files: %w", err)
+	}
+
+	ok, err := c.evalBool(fmt.Sprintf(`(() => {
+		const el = document.querySelector(%s);
+		if (!el) return false;
+		const dt = new DataTransfer();
+		for (const f of %s) {
+			const bytes = Uint8Array.from(atob(f.data), (ch) => ch.charCodeAt(0));
+			dt.items.add(new File([bytes], f.name, {type: f.type}));
+		}
+		const r = el.getBoundingClientRect();
+		const at = {clientX: r.left + r.width / 2, clientY: r.top + r.height / 2};
+		for (const type of ["dragenter", "dragover", "drop"]) {
+			el.dispatchEvent(new DragEvent(type, {bubbles: true, cancelable: true, dataTransfer: dt, ...at}));
+		}
+		return true;
+	})()`, quote(selector), payload))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("no element matches %s", selector)
+	}
+	return nil
+}
+
+type uploadTracker struct {
+	mu       sync.Mutex
+	handlers map[int]func(Upload)
+	nextID   int
+	active   map[string]*Upload
+	enabled  bool
+}
+
+// OnUpload calls handler as requests with a body, such as form posts and
+// file uploads, are sent and answered. Chrome reports no byte-level upload
+// progress, so handler sees each upload start, finish sending and complete
+// or fail. The returned function stops delivery.
+func (c *Chrome) OnUpload(handler func(Upload)) (func(), error) {
+	c.uploads.mu.Lock()
+	if c.uploads.handlers == nil {
+		c.uploads.handlers = make(map[int]func(Upload))
+		c.uploads.active = make(map[string]*Upload)
+	}
+	c.uploads.nextID++
+	id := c.uploads.nextID
+	c.uploads.handlers[id] = handler
+	enabled := c.uploads.enabled
+	c.uploads.enabled = true
+	c.uploads.mu.Unlock()
+
+	off := func() {
+		c.uploads.mu.Lock()
+		delete(c.uploads.handlers, id)
+		c.uploads.mu.Unlock()
+	}
+	if enabled {
+		return off, nil
+	}
+
+	c.On("Network.requestWillBeSent", c.onUploadStarted)
+	c.On("Network.responseReceived", func(e browser.Event) { c.updateUpload(e, UploadSent) })
+	c.On("Network.loadingFinished", func(e browser.Event) { c.updateUpload(e, UploadFinished) })
+	c.On("Network.loadingFailed", func(e browser.Event) { c.updateUpload(e, UploadFailed) })
+	if _, err := c.Send("Network.enable", nil); err != nil {
+		off()
+		return nil, err
+	}
+	return off, nil
+}
+
+func (c *Chrome) onUploadStarted(e browser.Event) {
+	var p struct {
+		RequestID string `json:"requestId"`
+		Request   struct {
+			URL             string            `json:"url"`
+			Method          string            `json:"method"`
+			Headers         map[string]string `json:"headers"`
+			HasPostData     bool              `json:"hasPostData"`
+			PostDataEntries []struct {
+				Bytes string `json:"bytes"`
+			} `json:"postDataEntries"`
+		} `json:"request"`
+	}
+	if json.Unmarshal(e.Params, &p) != nil || !p.Request.HasPostData {
+		return
+	}
+
+	u := &Upload{RequestID: p.RequestID, URL: p.Request.URL, Method: p.Request.Method, Size: -1, State: UploadStarted}
+	for name, value := range p.Request.Headers {
+		if strings.EqualFold(name, "Content-Length") {
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				u.Size = n
+			}
+		}
+	}
+	if u.Size < 0 && len(p.Request.PostDataEntries) > 0 {
+		u.Size = 0
+		for _, entry := range p.Request.PostDataEntries {
+			u.Size += int64(base64.StdEncoding.DecodedLen(len(entry.Bytes)) - strings.Count(entry.Bytes, "="))
+		}
+	}
+
+	snapshot := *u
+	c.uploads.mu.Lock()
+	c.uploads.active[p.RequestID] = u
+	c.uploads.mu.Unlock()
+	c.notifyUpload(snapshot)
+}
+
+func (c *Chrome) updateUpload(e browser.Event, state UploadState) {
+	var p struct {
+		RequestID string `json:"requestId"`
+		ErrorText string `json:"errorText"`
+	}
+	if json.Unmarshal(e.Params, &p) != nil {
+		return
+	}
+
+	c.uploads.mu.Lock()
+	u, ok := c.uploads.active[p.RequestID]
+	var snapshot Upload
+	if ok {
+		u.State, u.Error = state, p.ErrorText
+		snapshot = *u
+		if state == UploadFinished || state == UploadFailed {
+			delete(c.uploads.active, p.RequestID)
+		}
+	}
+	c.uploads.mu.Unlock()
+	if ok {
+		c.notifyUpload(snapshot)
+	}
+}
+
+func (c *Chrome) notifyUpload(u Upload) {
+	c.uploads.mu.Lock()
+	handlers := make([]func(Upload), 0, len(c.uploads.handlers))
+	for _, h := range c.uploads.handlers {
+		handlers = append(handlers, h)
+	}
+	c.uploads.mu.Unlock()
+	for _, h := range handlers {
+		h(u)
+	}
+}
@@ -0,0 +1,104 @@
+package chrome
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// EventVisibility is emitted with a VisibilityState whenever the page is
+// shown, hidden, focused or blurred. Subscribe with On or OnVisibility.
+const EventVisibility = "majorca.visibility"
+
+// stateBinding is the raw binding the visibility listeners report through.
+const stateBinding = "__majorcaState"
+
+const visibilityScript = `(() => {
+	const report = () => window.__majorcaState && window.__majorcaState(JSON.stringify({
+		visible: document.visibilityState === "visible",
+		focused: document.hasFocus(),
+	}));
+	document.addEventListener("visibilitychange", report);
+	window.addEventListener("focus", report);
+	window.addEventListener("blur", report);
+	report();
+})()`
+
+// VisibilityState says whether the page can be seen and has input focus.
+type VisibilityState struct {
+	Visible bool `json:"visible"`
+	Focused bool `json:"focused"`
+}
+
+type visibilityTracker struct {
+	once    sync.Once
+	mu      sync.Mutex
+	state   VisibilityState
+	known   bool
+	enabled bool
+}
+
+// OnVisibility calls handler whenever the page's visibility or focus
+// changes, e.g. to pause background work while the window is minimized. The
+// returned function stops delivery.
+func (c *Chrome) OnVisibility(handler func(VisibilityState)) (func(), error) {
+	var err error
+	c.visibility.once.Do(func() {
+		c.On("Runtime.bindingCalled", c.onStateBinding)
+		if err = c.applyVisibility(); err == nil {
+			err = c.AddInitScript(visibilityScript)
+		}
+		c.visibility.mu.Lock()
+		c.visibility.enabled = err == nil
+		c.visibility.mu.Unlock()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.On(EventVisibility, func(e browser.Event) {
+		var s VisibilityState
+		if json.Unmarshal(e.Params, &s) == nil {
+			handler(s)
+		}
+	}), nil
+}
+
+// Visibility returns the last reported visibility state. ok is false until
+// OnVisibility was called and the page reported its state.
+func (c *Chrome) Visibility() (state VisibilityState, ok bool) {
+	c.visibility.mu.Lock()
+	defer c.visibility.mu.Unlock()
+	return c.visibility.state, c.visibility.known
+}
+
+// applyVisibility adds the reporting binding to c's connection, which loses
+// it on reconnect.
+func (c *Chrome) applyVisibility() error {
+	_, err := c.Send("Runtime.addBinding", map[string]interface{}{"name": stateBinding})
+	return err
+}
+
+func (c *Chrome) onStateBinding(e browser.Event) {
+	var p struct {
+		Name    string `json:"name"`
+		Payload string `json:"payload"`
+	}
+	if json.Unmarshal(e.Params, &p) != nil || p.Name != stateBinding {
+		return
+	}
+	var s VisibilityState
+	if json.Unmarshal([]byte(p.Payload), &s) != nil {
+		return
+	}
+
+	c.visibility.mu.Lock()
+	changed := !c.visibility.known || c.visibility.state != s
+	c.visibility.state, c.visibility.known = s, true
+	c.visibility.mu.Unlock()
+
+	if changed {
+		c.Emit(browser.Event{Method: EventVisibility, Params: json.RawMessage(p.Payload)})
+	}
+}
@@ -0,0 +1,214 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// watchBinding is the raw binding watch expressions report changes through.
+const watchBinding = "__majorcaWatch"
+
+// watchScript evaluates an expression on a timer, or on DOM mutations when
+// the interval is zero, and reports its value whenever it changes.
+const watchScript = `(() => {
+	const id = %d, interval = %d;
+	const watches = window.__majorcaWatches = window.__majorcaWatches || {};
+	if (watches[id]) return;
+	let last;
+	const check = () => {
+		let value;
+		try {
+			value = JSON.stringify((() => (%s))() ?? null);
+		} catch (e) {
+			return;
+		}
+		if (value === last) return;
+		last = value;
+		window.__majorcaWatch && window.__majorcaWatch(JSON.stringify({id, value}));
+	};
+	if (interval > 0) {
+		const timer = setInterval(check, interval);
+		watches[id] = () => clearInterval(timer);
+	} else {
+		const observer = new MutationObserver(check);
+		observer.observe(document, {subtree: true, childList: true, attributes: true, characterData: true});
+		watches[id] = () => observer.disconnect();
+	}
+	check();
+})()`
+
+// watchBuffer is how many undelivered values a watch keeps before dropping
+// the oldest.
+const watchBuffer = 16
+
+type watch struct {
+	script     string
+	identifier string // Page.addScriptToEvaluateOnNewDocument handle
+	values     chan json.RawMessage
+}
+
+type watchTracker struct {
+	once    sync.Once
+	mu      sync.Mutex
+	next    int
+	watches map[int]*watch
+	enabled bool
+}
+
+// Watch evaluates the JavaScript expression expr inside the page every
+// interval and sends its JSON-encoded value on the returned channel whenever
+// it changes, starting with the current value. With a zero interval the
+// expression is re-evaluated on DOM mutations instead of a timer. Watches
+// carry over to pages loaded later; each new page reports its first value
+// again. If the receiver falls behind, older values are dropped. The
+// returned function stops the watch and closes the channel, which is also
+// closed when the browser goes away.
+func (c *Chrome) Watch(expr string, interval time.Duration) (<-chan json.RawMessage, func(), error) {
+	var err error
+	c.watches.once.Do(func() {
+		c.On("Runtime.bindingCalled", c.onWatchBinding)
+		_, err = c.Send("Runtime.addBinding", map[string]interface{}{"name": watchBinding})
+		c.watches.mu.Lock()
+		c.watches.watches = make(map[int]*watch)
+		c.watches.enabled = err == nil
+		c.watches.mu.Unlock()
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.watches.mu.Lock()
+	if !c.watches.enabled {
+		c.watches.mu.Unlock()
+		return nil, nil, fmt.Errorf("watch binding is not available")
+	}
+	c.watches.next++
+	id := c.watches.next
+	w := &watch{
+		script: fmt.Sprintf(watchScript, id, interval.Milliseconds(), expr),
+		values: make(chan json.RawMessage, watchBuffer),
+	}
+	c.watches.watches[id] = w
+	c.watches.mu.Unlock()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			c.watches.mu.Lock()
+			delete(c.watches.watches, id)
+			identifier := w.identifier
+			close(w.values)
+			c.watches.mu.Unlock()
+
+			select {
+			case <-c.Done():
+				return
+			default:
+			}
+			if identifier != "" {
+				c.Send("Page.removeScriptToEvaluateOnNewDocument", map[string]interface{}{"identifier": identifier})
+			}
+			c.Send("Runtime.evaluate", map[string]interface{}{
+				"expression": fmt.Sprintf(`(() => {
+					const watches = window.__majorcaWatches || {};
+					if (watches[%d]) watches[%d]();
+					delete watches[%d];
+				})()`, id, id, id),
+			})
+		})
+	}
+
+	if err := c.addWatchScript(w); err != nil {
+		stop()
+		return nil, nil, err
+	}
+	if _, err := c.Send("Runtime.evaluate", map[string]interface{}{"expression": w.script}); err != nil {
+		stop()
+		return nil, nil, err
+	}
+
+	go func() {
+		<-c.Done()
+		stop()
+	}()
+	return w.values, stop, nil
+}
+
+// addWatchScript registers w's script for new documents on the current
+// connection.
+func (c *Chrome) addWatchScript(w *watch) error {
+	raw, err := c.Send("Page.addScriptToEvaluateOnNewDocument", map[string]interface{}{"source": w.script})
+	if err != nil {
+		return err
+	}
+	var res struct {
+		Identifier string `json:"identifier"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return fmt.Errorf("failed to unmarshal script identifier: %w", err)
+	}
+	c.watches.mu.Lock()
+	w.identifier = res.Identifier
+	c.watches.mu.Unlock()
+	return nil
+}
+
+// applyWatches restores the watch binding and scripts on a fresh connection.
+func (c *Chrome) applyWatches() error {
+	c.watches.mu.Lock()
+	enabled := c.watches.enabled
+	watches := make([]*watch, 0, len(c.watches.watches))
+	for _, w := range c.watches.watches {
+		watches = append(watches, w)
+	}
+	c.watches.mu.Unlock()
+	if !enabled {
+		return nil
+	}
+
+	if _, err := c.Send("Runtime.addBinding", map[string]interface{}{"name": watchBinding}); err != nil {
+		return err
+	}
+	for _, w := range watches {
+		if err := c.addWatchScript(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Chrome) onWatchBinding(e browser.Event) {
+	var p struct {
+		Name    string `json:"name"`
+		Payload string `json:"payload"`
+	}
+	if json.Unmarshal(e.Params, &p) != nil || p.Name != watchBinding {
+		return
+	}
+	var change struct {
+		ID    int    `json:"id"`
+		Value string `json:"value"`
+	}
+	if json.Unmarshal([]byte(p.Payload), &change) != nil {
+		return
+	}
+
+	c.watches.mu.Lock()
+	defer c.watches.mu.Unlock()
+	w, ok := c.watches.watches[change.ID]
+	if !ok {
+		return
+	}
+	value := json.RawMessage(change.Value)
+	select {
+	case w.values <- value:
+	default:
+		// Keep the newest value rather than blocking the event goroutine.
+		<-w.values
+		w.values <- value
+	}
+}
@@ -0,0 +1,67 @@
+package chrome_test
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grngxd/majorca/browser/chrome"
+	"github.com/grngxd/majorca/internal/cdptest"
+)
+
+func TestWatch(t *testing.T) {
+	var mu sync.Mutex
+	var removed string
+	d := cdptest.New(t, func(target, method string, params json.RawMessage) interface{} {
+		switch method {
+		case "Page.addScriptToEvaluateOnNewDocument":
+			return map[string]string{"identifier": "watch-script"}
+		case "Page.removeScriptToEvaluateOnNewDocument":
+			var p struct{ Identifier string }
+			json.Unmarshal(params, &p)
+			mu.Lock()
+			removed = p.Identifier
+			mu.Unlock()
+		}
+		return nil
+	})
+	c, err := chrome.Attach(d.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Kill()
+
+	values, stop, err := c.Watch("document.title", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := func(id int, value string) {
+		payload, _ := json.Marshal(map[string]interface{}{"id": id, "value": value})
+		d.Emit("main", "Runtime.bindingCalled", map[string]string{"name": "__majorcaWatch", "payload": string(payload)})
+	}
+	report(2, `"other watch"`)
+	report(1, `"first"`)
+	report(1, `"second"`)
+	for _, want := range []string{`"first"`, `"second"`} {
+		select {
+		case v := <-values:
+			if string(v) != want {
+				t.Errorf("watch value = %s, want %s", v, want)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("no watch value %s", want)
+		}
+	}
+
+	stop()
+	if _, ok := <-values; ok {
+		t.Error("channel not closed after stop")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if removed != "watch-script" {
+		t.Errorf("stop removed script %q, want the watch's", removed)
+	}
+}
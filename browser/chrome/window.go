@@ -0,0 +1,289 @@
+package chrome
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// windowID looks up the browser window hosting the connected page.
+func (c *Chrome) windowID() (int, error) {
+	raw, err := c.Send("Browser.getWindowForTarget", nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var res struct {
+		WindowID int `json:"windowId"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal window: %w", err)
+	}
+	return res.WindowID, nil
+}
+
+// GetBounds returns the current position, size and state of the app window.
+func (c *Chrome) GetBounds() (browser.Bounds, error) {
+	raw, err := c.Send("Browser.getWindowForTarget", nil)
+	if err != nil {
+		return browser.Bounds{}, err
+	}
+
+	var res struct {
+		Bounds browser.Bounds `json:"bounds"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return browser.Bounds{}, fmt.Errorf("failed to unmarshal window bounds: %w", err)
+	}
+	return res.Bounds, nil
+}
+
+// SetBounds moves and resizes the app window, or changes its state if
+// b.WindowState is set. Chrome refuses geometry changes for minimized,
+// maximized or fullscreen windows, so the window is restored first whenever
+// b carries a size or position.
+func (c *Chrome) SetBounds(b browser.Bounds) error {
+	id, err := c.windowID()
+	if err != nil {
+		return err
+	}
+
+	geometry := b.WindowState == "" || b.Left != 0 || b.Top != 0 || b.Width != 0 || b.Height != 0
+	if geometry && b.WindowState != "" && b.WindowState != browser.WindowNormal {
+		return fmt.Errorf("window state %q cannot be combined with a size or position", b.WindowState)
+	}
+	if geometry {
+		b.WindowState = browser.WindowNormal
+	}
+
+	if b.WindowState == browser.WindowNormal {
+		// Leaving minimized/maximized/fullscreen has to happen on its own.
+		if err := c.setWindowBounds(id, browser.Bounds{WindowState: browser.WindowNormal}); err != nil {
+			return err
+		}
+		b.WindowState = ""
+		if !geometry {
+			return nil
+		}
+	}
+
+	return c.setWindowBounds(id, b)
+}
+
+func (c *Chrome) setWindowBounds(id int, b browser.Bounds) error {
+	_, err := c.Send("Browser.setWindowBounds", map[string]interface{}{
+		"windowId": id,
+		"bounds":   b,
+	})
+	return err
+}
+
+// Maximize maximizes the app window.
+func (c *Chrome) Maximize() error {
+	return c.SetBounds(browser.Bounds{WindowState: browser.WindowMaximized})
+}
+
+// Minimize minimizes the app window.
+func (c *Chrome) Minimize() error {
+	return c.SetBounds(browser.Bounds{WindowState: browser.WindowMinimized})
+}
+
+// Fullscreen switches the app window to fullscreen.
+func (c *Chrome) Fullscreen() error {
+	return c.SetBounds(browser.Bounds{WindowState: browser.WindowFullscreen})
+}
+
+// Restore returns the app window to its normal state.
+func (c *Chrome) Restore() error {
+	return c.SetBounds(browser.Bounds{WindowState: browser.WindowNormal})
+}
+
+// offscreen is where Hide parks the window, beyond any real display.
+const offscreen = -32000
+
+// Hide takes the app window off screen without closing it, e.g. when a tray
+// app's window is closed to the tray. The browser, the page and its state
+// stay alive, so Show brings the window back instantly. The window is moved
+// off screen and minimized; it may still appear in the taskbar.
+func (c *Chrome) Hide() error {
+	c.Lock()
+	hidden := c.hidden != nil
+	c.Unlock()
+	if hidden {
+		return nil
+	}
+
+	id, err := c.windowID()
+	if err != nil {
+		return err
+	}
+	b, err := c.GetBounds()
+	if err != nil {
+		return err
+	}
+	state := b.WindowState
+	if state != browser.WindowNormal {
+		// Only a normal window reports the geometry to come back to.
+		if err := c.setWindowBounds(id, browser.Bounds{WindowState: browser.WindowNormal}); err != nil {
+			return err
+		}
+		if b, err = c.GetBounds(); err != nil {
+			return err
+		}
+		b.WindowState = state
+	}
+	if err := c.setWindowBounds(id, browser.Bounds{Left: offscreen, Top: offscreen}); err != nil {
+		return err
+	}
+	if err := c.setWindowBounds(id, browser.Bounds{WindowState: browser.WindowMinimized}); err != nil {
+		return err
+	}
+
+	c.Lock()
+	c.hidden = &b
+	c.Unlock()
+	return nil
+}
+
+// Show brings back a window hidden with Hide at its previous position and
+// size, maximized or fullscreen again if it was, and focuses it. A window
+// that was minimized when hidden comes back in its normal state. For a
+// window that is not hidden Show only restores and focuses it.
+func (c *Chrome) Show() error {
+	c.Lock()
+	b := c.hidden
+	c.Unlock()
+
+	if b == nil {
+		if err := c.Restore(); err != nil {
+			return err
+		}
+	} else {
+		if err := c.SetBounds(browser.Bounds{Left: b.Left, Top: b.Top, Width: b.Width, Height: b.Height}); err != nil {
+			return err
+		}
+		if b.WindowState == browser.WindowMaximized || b.WindowState == browser.WindowFullscreen {
+			if err := c.SetBounds(browser.Bounds{WindowState: b.WindowState}); err != nil {
+				return err
+			}
+		}
+		c.Lock()
+		c.hidden = nil
+		c.Unlock()
+	}
+	_, err := c.Send("Page.bringToFront", nil)
+	return err
+}
+
+// Hidden reports whether the window is hidden with Hide.
+func (c *Chrome) Hidden() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.hidden != nil
+}
+
+// OpenWindow opens url in a new window of the same Chrome process and returns
+// a Chrome connected to it. The window shares the process and profile with c;
+// killing it only closes the window.
+func (c *Chrome) OpenWindow(url string) (*Chrome, error) {
+	return c.openWindow(url, "")
+}
+
+// openWindow is OpenWindow in the given browser context, or the default one
+// if contextID is empty.
+func (c *Chrome) openWindow(url, contextID string) (*Chrome, error) {
+	root := c.root()
+
+	params := map[string]interface{}{
+		"url":       url,
+		"newWindow": true,
+	}
+	if contextID != "" {
+		params["browserContextId"] = contextID
+	}
+	raw, err := c.Send("Target.createTarget", params)
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		TargetID string `json:"targetId"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal target: %w", err)
+	}
+
+	w := &Chrome{
+		BaseBrowser: browser.BaseBrowser{
+			Pending:      make(map[string]chan interface{}),
+			Bindings:     make(map[string]browser.BindingFunc),
+			Path:         root.Path,
+			Log:          root.Log,
+			Timeout:      root.Timeout,
+			TraceEnabled: root.TraceEnabled,
+			Redact:       root.Redact,
+			ReadLimit:    root.ReadLimit,
+			Compression:  root.Compression,
+			Stop:         make(chan struct{}),
+		},
+		parent:        root,
+		telemetry:     telemetry{since: time.Now(), slow: root.telemetry.slow, large: root.telemetry.large},
+		waitUntil:     root.waitUntil,
+		deterministic: root.deterministic,
+		blockFonts:    root.blockFonts,
+		fontCSS:       root.fontCSS,
+		debugAddr:     root.debugAddr,
+		wsURL:         fmt.Sprintf("ws://%s/devtools/page/%s", root.debugAddr, res.TargetID),
+	}
+
+	ws, err := w.Dial(w.wsURL)
+	if err != nil {
+		c.Send("Target.closeTarget", map[string]interface{}{"targetId": res.TargetID})
+		return nil, fmt.Errorf("failed to dial window WebSocket: %w", err)
+	}
+	w.Ws = ws
+
+	w.Wg.Add(1)
+	go w.handleResponse()
+
+	if err := w.trackNavigations(); err != nil {
+		w.Kill()
+		return nil, err
+	}
+	if w.deterministic {
+		if err := w.setupDeterministic(); err != nil {
+			w.Kill()
+			return nil, err
+		}
+	}
+	if err := w.setupFonts(); err != nil {
+		w.Kill()
+		return nil, err
+	}
+	if err := w.installRuntime(); err != nil {
+		w.Kill()
+		return nil, err
+	}
+
+	// A window is also done once the whole browser is gone.
+	go func() {
+		select {
+		case <-root.Done():
+			w.FailPending(browser.ErrBrowserGone)
+			w.Closed(root.Wait())
+		case <-w.Done():
+		}
+	}()
+
+	return w, nil
+}
+
+// root returns the Chrome that launched the browser process.
+func (c *Chrome) root() *Chrome {
+	root := c
+	for root.parent != nil {
+		root = root.parent
+	}
+	return root
+}
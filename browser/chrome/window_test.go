@@ -0,0 +1,91 @@
+package chrome_test
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/grngxd/majorca/browser"
+	"github.com/grngxd/majorca/browser/chrome"
+	"github.com/grngxd/majorca/internal/cdptest"
+)
+
+// fakeWindow follows Browser.setWindowBounds like Chrome does: geometry
+// changes need a normal window and states keep the geometry.
+type fakeWindow struct {
+	mu sync.Mutex
+	b  browser.Bounds
+}
+
+func (w *fakeWindow) reply(target, method string, params json.RawMessage) interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch method {
+	case "Browser.getWindowForTarget":
+		return map[string]interface{}{"windowId": 1, "bounds": w.b}
+	case "Browser.setWindowBounds":
+		var p struct {
+			Bounds map[string]json.RawMessage `json:"bounds"`
+		}
+		json.Unmarshal(params, &p)
+		for key, v := range p.Bounds {
+			switch key {
+			case "left":
+				json.Unmarshal(v, &w.b.Left)
+			case "top":
+				json.Unmarshal(v, &w.b.Top)
+			case "width":
+				json.Unmarshal(v, &w.b.Width)
+			case "height":
+				json.Unmarshal(v, &w.b.Height)
+			case "windowState":
+				json.Unmarshal(v, &w.b.WindowState)
+			}
+		}
+	}
+	return nil
+}
+
+func (w *fakeWindow) bounds() browser.Bounds {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.b
+}
+
+func TestHideShow(t *testing.T) {
+	tests := []browser.Bounds{
+		{Left: 0, Top: 0, Width: 800, Height: 600, WindowState: browser.WindowNormal},
+		{Left: 100, Top: 50, Width: 800, Height: 600, WindowState: browser.WindowMinimized},
+		{Left: 100, Top: 50, Width: 800, Height: 600, WindowState: browser.WindowMaximized},
+	}
+	for _, start := range tests {
+		w := &fakeWindow{b: start}
+		d := cdptest.New(t, w.reply)
+		c, err := chrome.Attach(d.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := c.Hide(); err != nil {
+			t.Fatalf("Hide: %v", err)
+		}
+		if b := w.bounds(); b.Left > -10000 || b.WindowState != browser.WindowMinimized || !c.Hidden() {
+			t.Errorf("%s window: bounds after Hide = %+v, want off screen and minimized", start.WindowState, b)
+		}
+
+		if err := c.Show(); err != nil {
+			t.Fatalf("Show: %v", err)
+		}
+		want := start
+		if want.WindowState == browser.WindowMinimized {
+			want.WindowState = browser.WindowNormal
+		}
+		if b := w.bounds(); b != want || c.Hidden() {
+			t.Errorf("%s window: bounds after Show = %+v, want %+v", start.WindowState, b, want)
+		}
+		if !d.Called("Page.bringToFront") {
+			t.Errorf("%s window: Show did not focus the window", start.WindowState)
+		}
+		c.Kill()
+	}
+}
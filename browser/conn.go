@@ -0,0 +1,145 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// DefaultReadLimit bounds the size of a single protocol message. It leaves
+// room for full-page screenshots and PDFs, which arrive base64 encoded.
+const DefaultReadLimit = 256 << 20
+
+// ErrMessageTooLarge is returned by ReadJSON for a message over the read
+// limit. The message is dropped and the connection stays usable.
+var ErrMessageTooLarge = errors.New("message exceeds read limit")
+
+// Conn is a message-oriented connection to a browser's debugging endpoint.
+// ReadJSON must only be called from one goroutine; WriteJSON and Close are
+// safe for concurrent use.
+type Conn interface {
+	ReadJSON(v interface{}) error
+	WriteJSON(v interface{}) error
+	Close() error
+}
+
+// WebSocket is a Conn to any WebSocket server, see DialWebSocket.
+type WebSocket interface {
+	Conn
+	// Ping sends a ping frame and waits for the pong. The pong is read by
+	// ReadJSON, so a reader must be running.
+	Ping() error
+}
+
+// WebSocketOptions configures DialWebSocket.
+type WebSocketOptions struct {
+	Header    http.Header // Extra handshake headers, e.g. Authorization
+	ReadLimit int64       // Largest accepted message in bytes; zero means DefaultReadLimit
+	Timeout   time.Duration
+	// IdleTimeout bounds how long Ping waits for the pong, so pinging
+	// detects half-open connections. Zero waits forever.
+	IdleTimeout time.Duration
+}
+
+// DialWebSocket opens a connection to a WebSocket server that is not a
+// browser, such as a management server.
+func DialWebSocket(url string, o WebSocketOptions) (WebSocket, error) {
+	return dial(url, &websocket.DialOptions{HTTPHeader: o.Header}, o.ReadLimit, o.Timeout, o.IdleTimeout)
+}
+
+// Dial opens a WebSocket connection to a debugging endpoint using the
+// browser's read limit and compression settings.
+func (b *BaseBrowser) Dial(url string) (Conn, error) {
+	opts := &websocket.DialOptions{
+		HTTPHeader:      http.Header{"Origin": {"http://localhost"}},
+		CompressionMode: websocket.CompressionDisabled,
+	}
+	if b.Compression {
+		opts.CompressionMode = websocket.CompressionContextTakeover
+	}
+	return dial(url, opts, b.ReadLimit, 10*time.Second, 0)
+}
+
+func dial(url string, opts *websocket.DialOptions, limit int64, timeout, idle time.Duration) (*wsConn, error) {
+	if limit == 0 {
+		limit = DefaultReadLimit
+	}
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	c, _, err := websocket.Dial(ctx, url, opts)
+	if err != nil {
+		return nil, err
+	}
+	// ReadJSON enforces the limit itself so that an oversized message is
+	// skipped rather than closing the connection.
+	c.SetReadLimit(-1)
+	return &wsConn{c: c, limit: limit, idle: idle}, nil
+}
+
+// wsConn adapts a coder/websocket connection to WebSocket.
+type wsConn struct {
+	c     *websocket.Conn
+	limit int64
+	idle  time.Duration
+}
+
+func (w *wsConn) ReadJSON(v interface{}) error {
+	_, r, err := w.c.Reader(context.Background())
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(r, w.limit+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > w.limit {
+		if _, err := io.Copy(io.Discard, r); err != nil {
+			return err
+		}
+		return ErrMessageTooLarge
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (w *wsConn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return w.c.Write(context.Background(), websocket.MessageText, data)
+}
+
+func (w *wsConn) Close() error {
+	return w.c.Close(websocket.StatusNormalClosure, "")
+}
+
+func (w *wsConn) Ping() error {
+	ctx := context.Background()
+	if w.idle > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.idle)
+		defer cancel()
+	}
+	return w.c.Ping(ctx)
+}
+
+// IsConnError reports whether an error from Conn means the connection itself
+// is unusable, as opposed to a single undecodable message.
+func IsConnError(err error) bool {
+	return err != nil && !errors.Is(err, ErrMessageTooLarge) && !isDecodeError(err)
+}
+
+func isDecodeError(err error) bool {
+	var syntax *json.SyntaxError
+	var typ *json.UnmarshalTypeError
+	return errors.As(err, &syntax) || errors.As(err, &typ)
+}
@@ -0,0 +1,72 @@
+package browser_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/grngxd/majorca/browser"
+)
+
+// serveWebSocket runs handler for every WebSocket client and returns the
+// server's ws:// URL.
+func serveWebSocket(t *testing.T, handler func(c *websocket.Conn)) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer c.CloseNow()
+		handler(c)
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestWebSocketReadLimit(t *testing.T) {
+	url := serveWebSocket(t, func(c *websocket.Conn) {
+		ctx := context.Background()
+		c.Write(ctx, websocket.MessageText, []byte(`{"data":"`+strings.Repeat("x", 100)+`"}`))
+		c.Write(ctx, websocket.MessageText, []byte(`{"data":"ok"}`))
+		c.Read(ctx)
+	})
+	conn, err := browser.DialWebSocket(url, browser.WebSocketOptions{ReadLimit: 64, Timeout: 3 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var msg struct{ Data string }
+	if err := conn.ReadJSON(&msg); !errors.Is(err, browser.ErrMessageTooLarge) {
+		t.Fatalf("oversized message: err = %v, want ErrMessageTooLarge", err)
+	}
+	if browser.IsConnError(browser.ErrMessageTooLarge) {
+		t.Error("ErrMessageTooLarge reported as a connection error")
+	}
+	if err := conn.ReadJSON(&msg); err != nil || msg.Data != "ok" {
+		t.Errorf("message after oversized one = %q, %v", msg.Data, err)
+	}
+}
+
+func TestWebSocketPingTimeout(t *testing.T) {
+	// The server never reads, so it never answers pings.
+	url := serveWebSocket(t, func(c *websocket.Conn) {
+		time.Sleep(time.Second)
+	})
+	conn, err := browser.DialWebSocket(url, browser.WebSocketOptions{IdleTimeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	go conn.ReadJSON(new(interface{}))
+
+	if err := conn.Ping(); err == nil {
+		t.Error("Ping succeeded without a pong")
+	}
+}
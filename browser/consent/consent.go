@@ -0,0 +1,132 @@
+// Package consent automatically dismisses cookie-consent and similar
+// banners after every navigation. It is opt-in: nothing happens until Enable
+// is called on a browser.
+package consent
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// Config controls what gets clicked or hidden.
+type Config struct {
+	// Selectors of accept/close buttons that are clicked directly.
+	Selectors []string
+	// Button labels matched case-insensitively, but only inside elements
+	// whose id or class looks like a consent banner.
+	Texts []string
+	// Selectors of banners that are hidden when nothing could be clicked.
+	Hide []string
+	// How long to keep watching the page for late banners after it loads.
+	Timeout time.Duration
+}
+
+// DefaultConfig covers the most common consent management platforms.
+func DefaultConfig() Config {
+	return Config{
+		Selectors: []string{
+			"#onetrust-accept-btn-handler",
+			"#didomi-notice-agree-button",
+			"#CybotCookiebotDialogBodyLevelButtonLevelOptinAllowAll",
+			"#CybotCookiebotDialogBodyButtonAccept",
+			"#truste-consent-button",
+			"#L2AGLb",
+			".fc-cta-consent",
+			".qc-cmp2-summary-buttons button[mode=primary]",
+			".cc-allow",
+			".cc-dismiss",
+			"[aria-label='Accept all']",
+		},
+		Texts: []string{"accept all", "accept", "allow all", "i agree", "agree", "got it", "ok"},
+		Hide: []string{
+			"#onetrust-consent-sdk",
+			"#CybotCookiebotDialog",
+			".cc-window",
+		},
+		Timeout: 10 * time.Second,
+	}
+}
+
+// Script returns the in-page dismisser for cfg.
+func Script(cfg Config) string {
+	data, _ := json.Marshal(struct {
+		Selectors []string `json:"selectors"`
+		Texts     []string `json:"texts"`
+		Hide      []string `json:"hide"`
+		Timeout   int64    `json:"timeout"`
+	}{cfg.Selectors, cfg.Texts, cfg.Hide, cfg.Timeout.Milliseconds()})
+
+	return fmt.Sprintf(`(() => {
+	const cfg = %s;
+	const banner = /cookie|consent|gdpr|cmp|privacy/i;
+	const visible = (el) => !!(el.offsetWidth || el.offsetHeight || el.getClientRects().length);
+	const inBanner = (el) => {
+		for (; el && el !== document.body; el = el.parentElement) {
+			if (banner.test(el.id) || banner.test(el.className)) return true;
+		}
+		return false;
+	};
+	const dismiss = () => {
+		for (const sel of cfg.selectors) {
+			const el = document.querySelector(sel);
+			if (el && visible(el)) { el.click(); return true; }
+		}
+		for (const el of document.querySelectorAll("button, [role=button], a")) {
+			const text = (el.innerText || "").trim().toLowerCase();
+			if (cfg.texts.includes(text) && visible(el) && inBanner(el)) { el.click(); return true; }
+		}
+		return false;
+	};
+	const run = () => {
+		if (dismiss()) return;
+		const observer = new MutationObserver(() => { if (dismiss()) observer.disconnect(); });
+		observer.observe(document.documentElement, {childList: true, subtree: true});
+		setTimeout(() => {
+			observer.disconnect();
+			for (const sel of cfg.hide) {
+				document.querySelectorAll(sel).forEach((el) => el.style.setProperty("display", "none", "important"));
+			}
+		}, cfg.timeout);
+	};
+	if (document.readyState === "loading") {
+		document.addEventListener("DOMContentLoaded", run, {once: true});
+	} else {
+		run();
+	}
+})()`, data)
+}
+
+// Enable installs the dismisser on every future document and runs it on the
+// current one. The returned identifier can be passed to Disable.
+func Enable(s browser.Sender, cfg Config) (string, error) {
+	source := Script(cfg)
+
+	raw, err := s.Send("Page.addScriptToEvaluateOnNewDocument", map[string]interface{}{
+		"source": source,
+	})
+	if err != nil {
+		return "", err
+	}
+	var res struct {
+		Identifier string `json:"identifier"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return "", fmt.Errorf("failed to unmarshal script identifier: %w", err)
+	}
+
+	if _, err := s.Send("Runtime.evaluate", map[string]interface{}{"expression": source}); err != nil {
+		return res.Identifier, err
+	}
+	return res.Identifier, nil
+}
+
+// Disable stops the dismisser from running on future documents.
+func Disable(s browser.Sender, identifier string) error {
+	_, err := s.Send("Page.removeScriptToEvaluateOnNewDocument", map[string]interface{}{
+		"identifier": identifier,
+	})
+	return err
+}
@@ -0,0 +1,225 @@
+package browser
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stderrTailSize is how much browser stderr is kept for crash reports.
+const stderrTailSize = 16 << 10
+
+// CrashReport describes an unexpected exit of the browser process.
+type CrashReport struct {
+	Time     time.Time
+	ExitCode int    // -1 when the process was killed by a signal
+	Err      string // Exit error as reported by the OS
+	Stderr   string // Last lines the browser wrote to stderr
+	// Dumps are the minidumps written for this crash, already moved to the
+	// directory given to WithCrashReports. Empty without crash reporting or
+	// when the browser died before writing one.
+	Dumps []string
+	// Renderer is set when only the page's renderer crashed. The browser
+	// keeps running and the page can be reloaded.
+	Renderer bool
+}
+
+// OnCrash registers handler to run when the browser process exits
+// unexpectedly or, for backends that report it, the page's renderer
+// crashes. Handlers run on their own goroutine. The returned function
+// removes the handler.
+func (b *BaseBrowser) OnCrash(handler func(report *CrashReport)) func() {
+	h := &b.handlers
+	f := &handler
+	h.mu.Lock()
+	h.crash = append(h.crash, f)
+	h.mu.Unlock()
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		for i, g := range h.crash {
+			if g == f {
+				h.crash = append(h.crash[:i:i], h.crash[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Crashed records report as the latest crash, fails every command in flight
+// with ErrBrowserGone and runs the OnCrash handlers. Backends call it when
+// the browser tells them the page crashed; process crashes are detected by
+// Start.
+func (b *BaseBrowser) Crashed(report *CrashReport) {
+	b.crashMu.Lock()
+	b.crash = report
+	b.crashMu.Unlock()
+
+	b.FailPending(ErrBrowserGone)
+	for _, h := range b.handlers.crashHandlers() {
+		go h(report)
+	}
+}
+
+// LastCrashReport returns the report of the latest crash, or nil if the
+// browser never exited unexpectedly. Exits caused by Kill or a clean exit
+// status are not crashes.
+func (b *BaseBrowser) LastCrashReport() *CrashReport {
+	b.crashMu.Lock()
+	defer b.crashMu.Unlock()
+	return b.crash
+}
+
+// captureStderr keeps the tail of the browser's stderr for crash reports,
+// still forwarding everything to the configured writer.
+func (b *BaseBrowser) captureStderr() {
+	b.stderr = &tailWriter{max: stderrTailSize}
+	if b.Cmd.Stderr != nil {
+		b.Cmd.Stderr = io.MultiWriter(b.Cmd.Stderr, b.stderr)
+	} else {
+		b.Cmd.Stderr = b.stderr
+	}
+}
+
+// recordCrash builds a CrashReport when the process exited with err while
+// nobody asked it to stop, and returns nil otherwise.
+func (b *BaseBrowser) recordCrash(err error, started time.Time) *CrashReport {
+	if err == nil {
+		return nil
+	}
+	select {
+	case <-b.Stop:
+		return nil
+	default:
+	}
+
+	report := &CrashReport{Time: time.Now(), ExitCode: -1, Err: err.Error()}
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		report.ExitCode = exit.ExitCode()
+	}
+	if b.stderr != nil {
+		report.Stderr = b.stderr.String()
+	}
+	if b.CrashDir != "" {
+		report.Dumps = b.collectDumps(started)
+	}
+	b.Logger().Error("browser crashed", "exit", report.ExitCode, "dumps", len(report.Dumps))
+	return report
+}
+
+// collectDumps finds minidumps written since started. Dumps in DumpDir are
+// moved into CrashDir, where they survive temporary profile cleanup.
+func (b *BaseBrowser) collectDumps(started time.Time) []string {
+	var dumps []string
+	for _, src := range []string{b.CrashDir, b.DumpDir} {
+		if src == "" {
+			continue
+		}
+		filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".dmp") {
+				return nil
+			}
+			if info, err := d.Info(); err != nil || info.ModTime().Before(started) {
+				return nil
+			}
+			if src != b.CrashDir {
+				dest := filepath.Join(b.CrashDir, filepath.Base(path))
+				if err := moveFile(path, dest); err != nil {
+					b.Logger().Warn("failed to collect minidump", "path", path, "error", err)
+					return nil
+				}
+				path = dest
+			}
+			dumps = append(dumps, path)
+			return nil
+		})
+	}
+	return dumps
+}
+
+// moveFile renames src to dest, copying across file systems.
+func moveFile(src, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	if os.Rename(src, dest) == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	in.Close()
+	return os.Remove(src)
+}
+
+// handlers holds the OnExit and OnCrash callbacks. They are stored by
+// pointer so the function returned on registration can find its own.
+type handlers struct {
+	mu    sync.Mutex
+	exit  []*func(error)
+	crash []*func(*CrashReport)
+}
+
+func (h *handlers) exitHandlers() []func(error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fs := make([]func(error), len(h.exit))
+	for i, f := range h.exit {
+		fs[i] = *f
+	}
+	return fs
+}
+
+func (h *handlers) crashHandlers() []func(*CrashReport) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fs := make([]func(*CrashReport), len(h.crash))
+	for i, f := range h.crash {
+		fs[i] = *f
+	}
+	return fs
+}
+
+// tailWriter keeps the last max bytes written to it.
+type tailWriter struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	if over := len(w.buf) - w.max; over > 0 {
+		w.buf = append(w.buf[:0], w.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return string(w.buf)
+}
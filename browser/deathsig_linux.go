@@ -0,0 +1,9 @@
+package browser
+
+import "syscall"
+
+// setDeathSignal kills the browser if the app dies without cleaning up.
+// Its own process group no longer receives the terminal's Ctrl+C.
+func setDeathSignal(attr *syscall.SysProcAttr) {
+	attr.Pdeathsig = syscall.SIGKILL
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !windows
+
+package browser
+
+import "syscall"
+
+// setDeathSignal is a no-op; only Linux can signal children when their
+// parent dies.
+func setDeathSignal(attr *syscall.SysProcAttr) {}
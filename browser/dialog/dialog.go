@@ -0,0 +1,39 @@
+// Package dialog shows the operating system's native file dialogs and
+// returns the chosen paths, which pages cannot see for security reasons.
+package dialog
+
+import "errors"
+
+// ErrCanceled is returned when the user closes a dialog without choosing.
+var ErrCanceled = errors.New("dialog canceled")
+
+// Filter restricts the files offered by a dialog, e.g.
+// Filter{Name: "Images", Patterns: []string{"*.png", "*.jpg"}}.
+type Filter struct {
+	Name     string
+	Patterns []string
+}
+
+// Options configures a file dialog. Zero values use the platform defaults.
+type Options struct {
+	Title    string
+	Dir      string   // Initial directory
+	Name     string   // Suggested file name, for Save
+	Filters  []Filter // Not every platform supports more than one
+	Multiple bool     // Allow choosing several files, for Open
+}
+
+// Open asks the user for one or more existing files.
+func Open(opts Options) ([]string, error) {
+	return open(opts)
+}
+
+// Save asks the user for a file path to write to.
+func Save(opts Options) (string, error) {
+	return save(opts)
+}
+
+// Message shows an error message box and waits until the user closes it.
+func Message(title, text string) error {
+	return message(title, text)
+}
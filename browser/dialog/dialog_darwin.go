@@ -0,0 +1,91 @@
+package dialog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func open(opts Options) ([]string, error) {
+	script := "choose file" + prompt(opts)
+	if opts.Multiple {
+		script += " with multiple selections allowed"
+	}
+	if types := fileTypes(opts.Filters); types != "" {
+		script += " of type " + types
+	}
+	// Lists of aliases need converting one by one.
+	script = "set chosen to " + script + "\n" +
+		"if class of chosen is not list then set chosen to {chosen}\n" +
+		"set out to \"\"\n" +
+		"repeat with f in chosen\n" +
+		"set out to out & POSIX path of f & linefeed\n" +
+		"end repeat\n" +
+		"return out"
+
+	out, err := osascript(script)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+func save(opts Options) (string, error) {
+	script := "choose file name" + prompt(opts)
+	if opts.Name != "" {
+		script += " default name " + quote(opts.Name)
+	}
+	return osascript("POSIX path of (" + script + ")")
+}
+
+func message(title, text string) error {
+	_, err := osascript("display alert " + quote(title) + " message " + quote(text) + " as critical")
+	return err
+}
+
+func prompt(opts Options) string {
+	var s string
+	if opts.Title != "" {
+		s += " with prompt " + quote(opts.Title)
+	}
+	if opts.Dir != "" {
+		s += " default location POSIX file " + quote(opts.Dir)
+	}
+	return s
+}
+
+// fileTypes turns filter patterns into the extension list of "of type".
+func fileTypes(filters []Filter) string {
+	var exts []string
+	for _, f := range filters {
+		for _, p := range f.Patterns {
+			if ext := strings.TrimPrefix(p, "*."); ext != p && ext != "*" {
+				exts = append(exts, quote(ext))
+			}
+		}
+	}
+	if len(exts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(exts, ", ") + "}"
+}
+
+// quote makes an AppleScript string literal; its escaping matches JSON's for
+// quotes and backslashes.
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func osascript(script string) (string, error) {
+	out, err := exec.Command("osascript", "-e", script).Output()
+	if err != nil {
+		// Error -128 is "User canceled".
+		if exit, ok := err.(*exec.ExitError); ok && strings.Contains(string(exit.Stderr), "-128") {
+			return "", ErrCanceled
+		}
+		return "", fmt.Errorf("failed to show file dialog: %w", err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
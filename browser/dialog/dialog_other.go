@@ -0,0 +1,121 @@
+//go:build !windows && !darwin
+
+package dialog
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Linux and the BSDs have no dialog API of their own, so we use zenity or,
+// on KDE systems without it, kdialog.
+
+func open(opts Options) ([]string, error) {
+	out, err := run(opts, false)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+func save(opts Options) (string, error) {
+	return run(opts, true)
+}
+
+func message(title, text string) error {
+	var cmd *exec.Cmd
+	if path, err := exec.LookPath("zenity"); err == nil {
+		cmd = exec.Command(path, "--error", "--title="+title, "--text="+text)
+	} else if path, err := exec.LookPath("kdialog"); err == nil {
+		cmd = exec.Command(path, "--title", title, "--error", text)
+	} else {
+		return fmt.Errorf("no dialog tool found, install zenity or kdialog")
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to show message: %w", err)
+	}
+	return nil
+}
+
+func run(opts Options, save bool) (string, error) {
+	var cmd *exec.Cmd
+	if path, err := exec.LookPath("zenity"); err == nil {
+		cmd = exec.Command(path, zenityArgs(opts, save)...)
+	} else if path, err := exec.LookPath("kdialog"); err == nil {
+		cmd = exec.Command(path, kdialogArgs(opts, save)...)
+	} else {
+		return "", fmt.Errorf("no dialog tool found, install zenity or kdialog")
+	}
+
+	out, err := cmd.Output()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == 1 {
+		return "", ErrCanceled
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to show file dialog: %w", err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func zenityArgs(opts Options, save bool) []string {
+	args := []string{"--file-selection"}
+	if opts.Title != "" {
+		args = append(args, "--title="+opts.Title)
+	}
+	if start := startPath(opts); start != "" {
+		args = append(args, "--filename="+start)
+	}
+	if save {
+		args = append(args, "--save", "--confirm-overwrite")
+	} else if opts.Multiple {
+		args = append(args, "--multiple", "--separator=\n")
+	}
+	for _, f := range opts.Filters {
+		args = append(args, "--file-filter="+f.Name+" | "+strings.Join(f.Patterns, " "))
+	}
+	return args
+}
+
+func kdialogArgs(opts Options, save bool) []string {
+	var args []string
+	if opts.Title != "" {
+		args = append(args, "--title", opts.Title)
+	}
+	if save {
+		args = append(args, "--getsavefilename")
+	} else {
+		args = append(args, "--getopenfilename")
+		if opts.Multiple {
+			args = append(args, "--multiple", "--separate-output")
+		}
+	}
+	start := startPath(opts)
+	if start == "" {
+		start = "."
+	}
+	args = append(args, start)
+	if len(opts.Filters) > 0 {
+		filters := make([]string, len(opts.Filters))
+		for i, f := range opts.Filters {
+			filters[i] = f.Name + " (" + strings.Join(f.Patterns, " ") + ")"
+		}
+		args = append(args, strings.Join(filters, "\n"))
+	}
+	return args
+}
+
+// startPath combines the initial directory and suggested name into the
+// single path both tools expect.
+func startPath(opts Options) string {
+	switch {
+	case opts.Dir != "" && opts.Name != "":
+		return filepath.Join(opts.Dir, opts.Name)
+	case opts.Dir != "":
+		return opts.Dir + string(filepath.Separator)
+	}
+	return opts.Name
+}
@@ -0,0 +1,76 @@
+package dialog
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+func open(opts Options) ([]string, error) {
+	out, err := powershell("OpenFileDialog", opts, fmt.Sprintf("$d.Multiselect = $%t\n", opts.Multiple)+
+		"if ($d.ShowDialog() -eq 'OK') { $d.FileNames -join \"`n\" }")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+func save(opts Options) (string, error) {
+	var script string
+	if opts.Name != "" {
+		script = "$d.FileName = " + quote(opts.Name) + "\n"
+	}
+	return powershell("SaveFileDialog", opts, script+
+		"if ($d.ShowDialog() -eq 'OK') { $d.FileName }")
+}
+
+func message(title, text string) error {
+	script := "Add-Type -AssemblyName System.Windows.Forms\n" +
+		"[void][System.Windows.Forms.MessageBox]::Show(" + quote(text) + ", " + quote(title) + ", 'OK', 'Error')"
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-STA", "-Command", script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to show message: %w", err)
+	}
+	return nil
+}
+
+// powershell shows a Windows Forms dialog of the given class. It prints the
+// chosen paths, or nothing when canceled.
+func powershell(class string, opts Options, body string) (string, error) {
+	script := "Add-Type -AssemblyName System.Windows.Forms\n" +
+		"$d = New-Object System.Windows.Forms." + class + "\n"
+	if opts.Title != "" {
+		script += "$d.Title = " + quote(opts.Title) + "\n"
+	}
+	if opts.Dir != "" {
+		script += "$d.InitialDirectory = " + quote(opts.Dir) + "\n"
+	}
+	if len(opts.Filters) > 0 {
+		filters := make([]string, len(opts.Filters))
+		for i, f := range opts.Filters {
+			patterns := strings.Join(f.Patterns, ";")
+			filters[i] = f.Name + " (" + patterns + ")|" + patterns
+		}
+		script += "$d.Filter = " + quote(strings.Join(filters, "|")) + "\n"
+	}
+	script += body
+
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-STA", "-Command", script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to show file dialog: %w", err)
+	}
+	res := strings.TrimSpace(strings.ReplaceAll(string(out), "\r\n", "\n"))
+	if res == "" {
+		return "", ErrCanceled
+	}
+	return res, nil
+}
+
+// quote makes a single-quoted PowerShell string literal.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,34 @@
+package browser
+
+import "errors"
+
+var (
+	// ErrTimeout is returned when the browser does not answer a command
+	// within the configured command timeout.
+	ErrTimeout = errors.New("command timed out")
+
+	// ErrConnectionClosed is returned for commands that were still waiting
+	// for a response when the browser closed the connection.
+	ErrConnectionClosed = errors.New("connection closed by browser")
+
+	// ErrConnectionLost is returned for commands that were in flight when
+	// the connection dropped and was re-established. The command may or may
+	// not have been executed.
+	ErrConnectionLost = errors.New("connection lost while waiting for response")
+
+	// ErrBrowserGone is returned for commands that were in flight when the
+	// browser process exited or the page's renderer crashed.
+	ErrBrowserGone = errors.New("browser process is gone")
+
+	// ErrBrowserTooOld is returned when the browser is older than the
+	// version required with WithMinVersion.
+	ErrBrowserTooOld = errors.New("browser is too old")
+
+	// ErrNotImplemented is returned by methods a backend does not support
+	// yet.
+	ErrNotImplemented = errors.New("not implemented")
+
+	// ErrNoBrowser is returned by Auto when no backend can run on this
+	// machine, typically because no supported browser is installed.
+	ErrNoBrowser = errors.New("no supported browser found")
+)
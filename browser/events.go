@@ -0,0 +1,182 @@
+package browser
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Event is an unsolicited protocol message, e.g. "Page.loadEventFired".
+type Event struct {
+	Method string
+	Params json.RawMessage
+}
+
+// EventHandler receives events subscribed to with On.
+type EventHandler func(e Event)
+
+type subscription struct {
+	method  string
+	handler EventHandler
+}
+
+// queued is an event waiting for dispatch. Replayed events carry the only
+// subscription they are for.
+type queued struct {
+	Event
+	only *subscription
+}
+
+// ReplayCapacity is the number of recent events kept for OnReplay.
+const ReplayCapacity = 100
+
+// ReplayMethods lists the events kept for OnReplay: those describing what
+// happened to the page rather than routine traffic. Backends add their own
+// in init.
+var ReplayMethods = map[string]bool{
+	"Runtime.consoleAPICalled": true,
+	"Runtime.exceptionThrown":  true,
+	"Log.entryAdded":           true,
+	"Page.frameNavigated":      true,
+	"Page.loadEventFired":      true,
+	"Inspector.targetCrashed":  true,
+	"Inspector.detached":       true,
+	"Target.targetCrashed":     true,
+	"Target.targetDestroyed":   true,
+	"browsingContext.load":     true,
+	"log.entryAdded":           true,
+	"script.realmDestroyed":    true,
+}
+
+// events queues protocol events and runs handlers on a dedicated goroutine,
+// so handlers may issue commands of their own without stalling the reader.
+type events struct {
+	mu      sync.Mutex
+	subs    []*subscription
+	queue   []queued
+	recent  []Event // Ring of ReplayMethods events, oldest first
+	signal  chan struct{}
+	started bool
+}
+
+// On registers handler for events named method, or for every event when
+// method is "*". The returned function removes the subscription.
+func (b *BaseBrowser) On(method string, handler EventHandler) func() {
+	sub := &subscription{method: method, handler: handler}
+
+	b.events.mu.Lock()
+	b.events.subs = append(b.events.subs, sub)
+	b.events.mu.Unlock()
+
+	return func() {
+		b.events.mu.Lock()
+		defer b.events.mu.Unlock()
+		for i, s := range b.events.subs {
+			if s == sub {
+				b.events.subs = append(b.events.subs[:i:i], b.events.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// OnReplay is On, but handler first receives the buffered ReplayMethods
+// events matching method that were dispatched before it subscribed. This
+// lets subscribers registered after launch see console output, navigations
+// and crashes that happened during startup.
+func (b *BaseBrowser) OnReplay(method string, handler EventHandler) func() {
+	sub := &subscription{method: method, handler: handler}
+
+	b.events.mu.Lock()
+	var replay []queued
+	for _, e := range b.events.recent {
+		if method == e.Method || method == "*" {
+			replay = append(replay, queued{Event: e, only: sub})
+		}
+	}
+	// Replayed events predate everything still queued.
+	b.events.queue = append(replay, b.events.queue...)
+	b.events.subs = append(b.events.subs, sub)
+	b.events.mu.Unlock()
+
+	if len(replay) > 0 {
+		b.wake()
+	}
+
+	return func() {
+		b.events.mu.Lock()
+		defer b.events.mu.Unlock()
+		for i, s := range b.events.subs {
+			if s == sub {
+				b.events.subs = append(b.events.subs[:i:i], b.events.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Emit queues an event for delivery to its subscribers. Backends call it
+// from their read loop for every message without an id.
+func (b *BaseBrowser) Emit(e Event) {
+	b.events.mu.Lock()
+	b.events.queue = append(b.events.queue, queued{Event: e})
+	b.events.mu.Unlock()
+	b.wake()
+}
+
+// wake starts the dispatcher if needed and tells it there is work queued.
+func (b *BaseBrowser) wake() {
+	b.events.mu.Lock()
+	if !b.events.started {
+		b.events.started = true
+		b.events.signal = make(chan struct{}, 1)
+		go b.dispatchEvents()
+	}
+	signal := b.events.signal
+	b.events.mu.Unlock()
+
+	select {
+	case signal <- struct{}{}:
+	default:
+	}
+}
+
+func (b *BaseBrowser) dispatchEvents() {
+	for {
+		select {
+		case <-b.events.signal:
+		case <-b.Stop:
+			return
+		}
+
+		for {
+			b.events.mu.Lock()
+			if len(b.events.queue) == 0 {
+				b.events.mu.Unlock()
+				break
+			}
+			q := b.events.queue[0]
+			b.events.queue = b.events.queue[1:]
+			var handlers []EventHandler
+			if q.only != nil {
+				handlers = append(handlers, q.only.handler)
+			} else {
+				for _, s := range b.events.subs {
+					if s.method == q.Method || s.method == "*" {
+						handlers = append(handlers, s.handler)
+					}
+				}
+				if ReplayMethods[q.Method] {
+					b.events.recent = append(b.events.recent, q.Event)
+					if len(b.events.recent) > ReplayCapacity {
+						b.events.recent = b.events.recent[1:]
+					}
+				}
+			}
+			b.events.mu.Unlock()
+
+			for _, h := range handlers {
+				h(q.Event)
+			}
+		}
+	}
+}
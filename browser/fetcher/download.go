@@ -0,0 +1,112 @@
+// Package fetcher provisions pinned browser builds, like Playwright and
+// Puppeteer do: a Manifest lists one checksummed archive per platform, which
+// is downloaded into a cache directory, verified and unpacked once, and
+// reused by every later launch.
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultCacheDir returns the per-user directory builds are installed into
+// when no other is given, e.g. ~/.cache/majorca/browsers on Linux.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(dir, "majorca", "browsers"), nil
+}
+
+// Ensure returns the executable of the build pinned for the running
+// platform, downloading it first if it is not in cacheDir yet. An empty
+// cacheDir means DefaultCacheDir.
+func (m *Manifest) Ensure(ctx context.Context, cacheDir string) (string, error) {
+	b, err := m.Current()
+	if err != nil {
+		return "", err
+	}
+	return Download(ctx, b, cacheDir)
+}
+
+// Download fetches b's archive, verifies it against the pinned checksum and
+// installs it into cacheDir. Builds that are already installed are returned
+// without touching the network. An empty cacheDir means DefaultCacheDir.
+func Download(ctx context.Context, b *Build, cacheDir string) (string, error) {
+	if cacheDir == "" {
+		var err error
+		if cacheDir, err = DefaultCacheDir(); err != nil {
+			return "", err
+		}
+	}
+	if exe, ok := Installed(b, cacheDir); ok {
+		return exe, nil
+	}
+	if b.URL == "" {
+		return "", fmt.Errorf("build %s has no download URL", b.Dir())
+	}
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", err
+	}
+
+	archive, err := fetch(ctx, b.URL, cacheDir)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(archive)
+	return InstallArchive(b, archive, cacheDir)
+}
+
+// fetch downloads rawURL into a temporary file in dir that keeps the
+// archive's extension, which InstallArchive goes by.
+func fetch(ctx context.Context, rawURL, dir string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download browser: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download browser: %s", resp.Status)
+	}
+
+	f, err := os.CreateTemp(dir, "download-*"+archiveExt(rawURL))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to download browser: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func archiveExt(rawURL string) string {
+	name := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		name = u.Path
+	}
+	name = path.Base(name)
+	for _, ext := range []string{".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(name, ext) {
+			return ext
+		}
+	}
+	return path.Ext(name)
+}
@@ -0,0 +1,172 @@
+package fetcher_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grngxd/majorca/browser/fetcher"
+)
+
+func writeZip(t *testing.T, path string, files map[string]string) string {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	f.Close()
+
+	data, _ := os.ReadFile(path)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// tarEntry is a regular file, or a symlink when link is set.
+type tarEntry struct {
+	name, link, content string
+}
+
+func writeTarGz(t *testing.T, path string, entries []tarEntry) string {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create archive: %v", err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(e.content))}
+		if e.link != "" {
+			hdr = &tar.Header{Name: e.name, Linkname: e.link, Mode: 0777, Typeflag: tar.TypeSymlink}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("Failed to add %s: %v", e.name, err)
+		}
+		tw.Write([]byte(e.content))
+	}
+	tw.Close()
+	gz.Close()
+	f.Close()
+
+	data, _ := os.ReadFile(path)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestInstallArchive(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "chrome.zip")
+	sum := writeZip(t, archive, map[string]string{"chrome-win/chrome.exe": "binary"})
+
+	b := &fetcher.Build{OS: "windows", Arch: "amd64", Revision: "1", SHA256: sum, Executable: "chrome-win/chrome.exe"}
+	exe, err := fetcher.InstallArchive(b, archive, filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatalf("Failed to install archive: %v", err)
+	}
+	if got, ok := fetcher.Installed(b, filepath.Join(dir, "cache")); !ok || got != exe {
+		t.Errorf("Installed returned %s, %v; want %s, true", got, ok, exe)
+	}
+
+	b.SHA256 = hex.EncodeToString(make([]byte, sha256.Size))
+	if _, err := fetcher.InstallArchive(b, archive, filepath.Join(dir, "cache")); err == nil {
+		t.Errorf("Expected checksum mismatch error")
+	}
+}
+
+func TestInstallArchiveRejectsTraversal(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "evil.zip")
+	sum := writeZip(t, archive, map[string]string{"../evil.exe": "binary"})
+
+	b := &fetcher.Build{Revision: "1", SHA256: sum, Executable: "evil.exe"}
+	if _, err := fetcher.InstallArchive(b, archive, filepath.Join(dir, "cache")); err == nil {
+		t.Errorf("Expected path traversal to be rejected")
+	}
+}
+
+func TestInstallArchiveRejectsSymlinkEscape(t *testing.T) {
+	tests := map[string][]tarEntry{
+		"absolute link": {{name: "x/link", link: "/etc"}, {name: "x/link/passwd", content: "pwned"}},
+		"relative link": {{name: "x/link", link: "../../.."}},
+		"write through": {{name: "x/link", link: "."}, {name: "x/link/chrome", content: "binary"}},
+	}
+	for name, entries := range tests {
+		dir := t.TempDir()
+		archive := filepath.Join(dir, "evil.tar.gz")
+		sum := writeTarGz(t, archive, append(entries, tarEntry{name: "chrome", content: "binary"}))
+
+		b := &fetcher.Build{OS: "linux", Arch: "amd64", Revision: "1", SHA256: sum, Executable: "chrome"}
+		if _, err := fetcher.InstallArchive(b, archive, filepath.Join(dir, "cache")); err == nil {
+			t.Errorf("%s: expected the archive to be rejected", name)
+		}
+	}
+
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "ok.tar.gz")
+	sum := writeTarGz(t, archive, []tarEntry{{name: "bin/chrome", content: "binary"}, {name: "chrome", link: "bin/chrome"}})
+	b := &fetcher.Build{OS: "linux", Arch: "amd64", Revision: "1", SHA256: sum, Executable: "chrome"}
+	if _, err := fetcher.InstallArchive(b, archive, filepath.Join(dir, "cache")); err != nil {
+		t.Errorf("Failed to install archive with an internal link: %v", err)
+	}
+}
+
+func TestBuildDir(t *testing.T) {
+	b := &fetcher.Build{OS: "linux", Arch: "amd64", Revision: "../../etc"}
+	if dir := b.Dir(); filepath.Base(dir) != dir || dir == ".." {
+		t.Errorf("Dir() = %q, want a single path element", dir)
+	}
+	sum := hex.EncodeToString(make([]byte, sha256.Size))
+	if _, err := fetcher.ParseManifest([]byte(`{"builds":[{"os":"linux","arch":"amd64","revision":"../x","executable":"chrome","sha256":"` + sum + `"}]}`)); err == nil {
+		t.Errorf("Expected revision with path separators to be rejected")
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	_, err := fetcher.ParseManifest([]byte(`{"browser":"chromium","builds":[{"os":"linux","arch":"amd64","revision":"1","executable":"chrome","sha256":"abc"}]}`))
+	if err == nil {
+		t.Errorf("Expected invalid checksum to be rejected")
+	}
+}
+
+func TestDownload(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "chrome.zip")
+	sum := writeZip(t, archive, map[string]string{"chrome-linux/chrome": "binary"})
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.ServeFile(w, r, archive)
+	}))
+	defer srv.Close()
+
+	b := &fetcher.Build{Revision: "1", URL: srv.URL + "/chrome.zip", SHA256: sum, Executable: "chrome-linux/chrome"}
+	cache := filepath.Join(dir, "cache")
+	for i := 0; i < 2; i++ {
+		exe, err := fetcher.Download(context.Background(), b, cache)
+		if err != nil {
+			t.Fatalf("Failed to download: %v", err)
+		}
+		if data, _ := os.ReadFile(exe); string(data) != "binary" {
+			t.Errorf("Installed executable holds %q", data)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected the cached build to be reused, got %d requests", requests)
+	}
+}
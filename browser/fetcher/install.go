@@ -0,0 +1,202 @@
+package fetcher
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Installed returns the executable path of b inside cacheDir if the build has
+// already been installed.
+func Installed(b *Build, cacheDir string) (string, bool) {
+	exe := filepath.Join(cacheDir, b.Dir(), filepath.FromSlash(b.Executable))
+	if _, err := os.Stat(exe); err != nil {
+		return "", false
+	}
+	return exe, true
+}
+
+// InstallArchive verifies a locally available archive against the pinned
+// checksum and unpacks it into cacheDir. It never touches the network, so it
+// doubles as the offline install path. Zip and tar.gz archives are supported.
+func InstallArchive(b *Build, archive, cacheDir string) (string, error) {
+	if err := VerifyFile(archive, b.SHA256); err != nil {
+		return "", err
+	}
+
+	dest := filepath.Join(cacheDir, b.Dir())
+	tmp := dest + ".partial"
+	if err := os.RemoveAll(tmp); err != nil {
+		return "", fmt.Errorf("failed to clear %s: %w", tmp, err)
+	}
+
+	var err error
+	switch {
+	case strings.HasSuffix(archive, ".zip"):
+		err = extractZip(archive, tmp)
+	case strings.HasSuffix(archive, ".tar.gz"), strings.HasSuffix(archive, ".tgz"):
+		err = extractTarGz(archive, tmp)
+	default:
+		err = fmt.Errorf("unsupported archive format: %s", filepath.Base(archive))
+	}
+	if err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+
+	if err := os.RemoveAll(dest); err != nil {
+		return "", fmt.Errorf("failed to replace %s: %w", dest, err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		return "", fmt.Errorf("failed to move build into place: %w", err)
+	}
+
+	exe, ok := Installed(b, cacheDir)
+	if !ok {
+		return "", fmt.Errorf("archive does not contain %s", b.Executable)
+	}
+	return exe, nil
+}
+
+// target joins name onto dir, refusing entries that escape dir.
+func target(dir, name string) (string, error) {
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	if p != dir && !strings.HasPrefix(p, dir+string(os.PathSeparator)) {
+		return "", fmt.Errorf("archive entry %q escapes destination", name)
+	}
+	return p, nil
+}
+
+// noSymlinks refuses to write p if it or any directory between dir and p is
+// a symlink, so an archive cannot plant a link and then write through it.
+func noSymlinks(dir, p string) error {
+	rel, err := filepath.Rel(dir, p)
+	if err != nil {
+		return err
+	}
+	cur := dir
+	for _, part := range strings.Split(rel, string(os.PathSeparator)) {
+		cur = filepath.Join(cur, part)
+		fi, err := os.Lstat(cur)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("archive entry %q is written through a symlink", rel)
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func extractZip(archive, dir string) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open zip: %w", err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		p, err := target(dir, f.Name)
+		if err != nil {
+			return err
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(p, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		err = writeFile(p, rc, f.Mode().Perm())
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func extractTarGz(archive, dir string) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar: %w", err)
+		}
+
+		p, err := target(dir, hdr.Name)
+		if err != nil {
+			return err
+		}
+		if err := noSymlinks(dir, p); err != nil {
+			return err
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(p, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(p, tr, os.FileMode(hdr.Mode).Perm()); err != nil {
+				return fmt.Errorf("failed to extract %s: %w", hdr.Name, err)
+			}
+		case tar.TypeSymlink:
+			// Links may only point at other entries of the archive.
+			if path.IsAbs(hdr.Linkname) || filepath.IsAbs(hdr.Linkname) || filepath.VolumeName(hdr.Linkname) != "" {
+				return fmt.Errorf("archive entry %q links to absolute path %q", hdr.Name, hdr.Linkname)
+			}
+			if _, err := target(dir, path.Clean(path.Join(path.Dir(hdr.Name), hdr.Linkname))); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(hdr.Linkname, p); err != nil {
+				return err
+			}
+		}
+	}
+}
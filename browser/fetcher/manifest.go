@@ -0,0 +1,108 @@
+package fetcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Manifest pins the exact browser builds an app is allowed to run.
+type Manifest struct {
+	Browser string  `json:"browser"` // "chromium" or "firefox"
+	Builds  []Build `json:"builds"`
+}
+
+// Build is a single pinned revision for one OS/architecture pair.
+type Build struct {
+	OS         string `json:"os"`         // GOOS value, e.g. "windows"
+	Arch       string `json:"arch"`       // GOARCH value, e.g. "amd64"
+	Revision   string `json:"revision"`   // Browser revision or version
+	URL        string `json:"url"`        // Archive download location
+	SHA256     string `json:"sha256"`     // Hex encoded archive checksum
+	Executable string `json:"executable"` // Binary path inside the archive
+}
+
+// LoadManifest reads and validates a manifest file.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return ParseManifest(data)
+}
+
+// ParseManifest decodes and validates a JSON manifest.
+func ParseManifest(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+
+	for i, b := range m.Builds {
+		if b.OS == "" || b.Arch == "" || b.Revision == "" || b.Executable == "" {
+			return nil, fmt.Errorf("manifest build %d is missing os, arch, revision or executable", i)
+		}
+		if b.Dir() != b.Revision+"-"+b.OS+"-"+b.Arch {
+			return nil, fmt.Errorf("manifest build %d has an invalid os, arch or revision", i)
+		}
+		sum, err := hex.DecodeString(b.SHA256)
+		if err != nil || len(sum) != sha256.Size {
+			return nil, fmt.Errorf("manifest build %d has an invalid sha256 checksum", i)
+		}
+	}
+
+	return &m, nil
+}
+
+// Build returns the pinned build for the given platform.
+func (m *Manifest) Build(goos, goarch string) (*Build, error) {
+	for i := range m.Builds {
+		if m.Builds[i].OS == goos && m.Builds[i].Arch == goarch {
+			return &m.Builds[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no pinned %s build for %s/%s", m.Browser, goos, goarch)
+}
+
+// Current returns the pinned build for the running platform.
+func (m *Manifest) Current() (*Build, error) {
+	return m.Build(runtime.GOOS, runtime.GOARCH)
+}
+
+// Dir is the directory name a build is installed under. Characters other
+// than letters, digits, dots, dashes and underscores are replaced, so it is
+// always a single path element.
+func (b *Build) Dir() string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, fmt.Sprintf("%s-%s-%s", b.Revision, b.OS, b.Arch))
+}
+
+// VerifyFile checks that the file at path has the expected SHA-256 checksum.
+func VerifyFile(path, expected string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+
+	actual := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", path, actual, expected)
+	}
+	return nil
+}
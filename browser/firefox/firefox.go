@@ -2,57 +2,99 @@ package firefox
 
 import (
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/grngxd/majorca/browser"
-	"golang.org/x/net/websocket"
 )
 
 type Firefox struct {
 	browser.BaseBrowser
-	Id      int32
-	mu      sync.Mutex
-	profile string
+	mu          sync.Mutex
+	profile     string
+	keepProfile bool // persistent profiles survive Kill
 }
 
-func New(args ...string) (*Firefox, error) {
-	path, err := FindPath()
+func New(opts ...browser.Option) (*Firefox, error) {
+	o := browser.NewOptions(opts...)
+
+	path := o.ExecutablePath
+	var err error
+	if path == "" && o.Portable {
+		path, err = o.FindPortable(binaryNames()...)
+	} else if path == "" {
+		path, err = FindPath()
+		if err != nil && o.BrowserManifest != nil {
+			path, err = o.DownloadBrowser("firefox")
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
-	os.Setenv("MAJORCA_BROWSER", path)
+	if o.Headless {
+		// Without page control, a Firefox nobody can see is of no use.
+		return nil, fmt.Errorf("Firefox cannot run headless")
+	}
+	if o.MinVersion != "" {
+		version, err := Version(path)
+		if err == nil {
+			err = o.CheckVersion(version)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	profileDir := filepath.Join(os.TempDir(), fmt.Sprintf("firefox_profile_%d", time.Now().UnixNano()))
+	// Like Chrome, use a throwaway profile unless WithProfileDir or
+	// WithPortable asked for one that keeps cookies and storage between runs.
+	profileDir, err := o.Profile()
+	if err != nil {
+		return nil, err
+	}
+	keepProfile := profileDir != ""
+	if !keepProfile {
+		profileDir = filepath.Join(os.TempDir(), fmt.Sprintf("firefox_profile_%d", time.Now().UnixNano()))
+	}
 
 	firefox := &Firefox{
 		BaseBrowser: browser.BaseBrowser{
-			Pending:  make(map[string]chan interface{}),
-			Bindings: make(map[string]browser.BindingFunc),
-			Path:     path,
-			Done:     make(chan struct{}),
+			Pending:      make(map[string]chan interface{}),
+			Bindings:     make(map[string]browser.BindingFunc),
+			Path:         path,
+			Log:          o.Logger,
+			Timeout:      o.CommandTimeout,
+			TraceEnabled: o.Trace,
+			Redact:       o.Redaction,
+			ReadLimit:    o.ReadLimit,
+			Compression:  o.Compression,
+			Stop:         make(chan struct{}),
 		},
-		Id:      1,
-		profile: profileDir,
+		profile:     profileDir,
+		keepProfile: keepProfile,
 	}
 
 	err = os.MkdirAll(profileDir, 0755)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Firefox profile directory: %w", err)
 	}
-	fmt.Printf("Profile directory: %s\n", profileDir)
+	if !keepProfile {
+		scavengeProfiles(firefox)
+	}
+	firefox.Logger().Debug("using profile directory", "path", profileDir)
 
-	if err := customizeProfile(profileDir); err != nil {
+	if err := customizeProfile(profileDir, o.Prefs); err != nil {
 		return nil, fmt.Errorf("failed to customize Firefox profile: %w", err)
 	}
 
 	// Add necessary flags
-	args = append(args,
+	args := append(o.Args,
 		"--remote-debugging-port=9223",
 		"--no-remote",
 		"--profile", profileDir,
@@ -61,18 +103,33 @@ func New(args ...string) (*Firefox, error) {
 		"--no-extensions",
 		"--disable-popup-blocking",
 		"--disable-infobars",
-		"about:blank",
 	)
+	if o.Kiosk {
+		args = append(args, "--kiosk")
+	}
+	args = append(args, "about:blank")
 
 	firefox.Cmd = exec.Command(path, args...)
-	firefox.Cmd.Stdout = os.Stdout
-	firefox.Cmd.Stderr = os.Stderr
+	firefox.Cmd.Stdout = o.Stdout
+	firefox.Cmd.Stderr = o.Stderr
+	if o.CrashDir != "" {
+		// Without a report prompt, Firefox leaves minidumps in the profile.
+		firefox.CrashDir, err = filepath.Abs(o.CrashDir)
+		if err != nil {
+			return nil, err
+		}
+		firefox.DumpDir = filepath.Join(profileDir, "minidumps")
+		firefox.Cmd.Env = append(os.Environ(), "MOZ_CRASHREPORTER=1", "MOZ_CRASHREPORTER_NO_REPORT=1")
+	}
 
 	if err := firefox.Start(); err != nil {
 		return nil, err
 	}
+	if !firefox.keepProfile {
+		firefox.TrackTemp(profileDir, 9223)
+	}
 
-	if err := firefox.connectWebSocketWithRetry(10, 1*time.Second); err != nil {
+	if err := firefox.connectWebSocketWithRetry(o.ConnectRetry); err != nil {
 		firefox.Kill()
 		return nil, err
 	}
@@ -80,19 +137,37 @@ func New(args ...string) (*Firefox, error) {
 	firefox.Wg.Add(1)
 	go firefox.handleResponse()
 
+	if o.AlwaysOnTop {
+		if err := firefox.SetAlwaysOnTop(true); err != nil {
+			firefox.Logger().Warn("failed to keep window on top", "error", err)
+		}
+	}
+
 	return firefox, nil
 }
 
+// defaultPrefs make Firefox look like an app window rather than a browser.
+var defaultPrefs = map[string]interface{}{
+	"toolkit.legacyUserProfileCustomizations.stylesheets": true,
+	"browser.tabs.drawInTitlebar":                         true,
+	"browser.tabs.inTitlebar":                             0,
+	"devtools.policy.disabled":                            true,
+}
+
 // the profile dir is like 100mb give or take a bit so we gotta delete it
-func customizeProfile(profileDir string) error {
-	userJSPath := filepath.Join(profileDir, "user.js")
-	userJSContent := []byte(
-		`user_pref("toolkit.legacyUserProfileCustomizations.stylesheets", true);
-		user_pref("browser.tabs.drawInTitlebar", true);
-		user_pref("browser.tabs.inTitlebar", 0);
-		user_pref("devtools.policy.disabled", true);`,
-	)
-	err := os.WriteFile(userJSPath, userJSContent, 0644)
+func customizeProfile(profileDir string, prefs map[string]interface{}) error {
+	merged := make(map[string]interface{}, len(defaultPrefs)+len(prefs))
+	for name, value := range defaultPrefs {
+		merged[name] = value
+	}
+	for name, value := range prefs {
+		merged[name] = value
+	}
+	userJSContent, err := UserJS(merged)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(profileDir, "user.js"), userJSContent, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write user.js: %w", err)
 	}
@@ -143,42 +218,58 @@ func (f *Firefox) Kill() error {
 		return err
 	}
 
+	if f.keepProfile {
+		return nil
+	}
+
 	// delete profile directory
 	err = os.RemoveAll(f.profile)
 	if err != nil {
 		return fmt.Errorf("failed to delete Firefox profile directory: %w", err)
 	}
+	f.UntrackTemp()
 
 	return nil
 }
 
-// connectWebSocketWithRetry tries to connect to the WebSocket endpoint with retries.
-func (f *Firefox) connectWebSocketWithRetry(maxRetries int, delay time.Duration) error {
-	var err error
-	for i := 0; i < maxRetries; i++ {
-		err = f.connectWebSocket()
-		if err == nil {
-			return nil
+// connectWebSocketWithRetry connects to the WebSocket endpoint, retrying
+// as policy says while the browser starts up.
+func (f *Firefox) connectWebSocketWithRetry(policy browser.RetryPolicy) error {
+	attempt := 0
+	err := policy.RetryWithin(func(remaining time.Duration) error {
+		attempt++
+		err := f.connectWebSocket(remaining)
+		if err != nil {
+			f.Logger().Debug("remote debugging connection attempt failed", "attempt", attempt, "error", err)
 		}
-		fmt.Printf("Attempt %d: %v\n", i+1, err)
-		time.Sleep(delay)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to WebSocket: %w", err)
 	}
-	return fmt.Errorf("failed to connect to WebSocket after %d attempts: %v", maxRetries, err)
+	return nil
 }
 
 // connectWebSocket establishes a WebSocket connection to Firefox's Remote Debugging.
-func (f *Firefox) connectWebSocket() error {
+// It waits up to timeout for the debugging port, or portTimeout for zero.
+func (f *Firefox) connectWebSocket(timeout time.Duration) error {
+	if timeout <= 0 || timeout > portTimeout {
+		timeout = portTimeout
+	}
 	// Check if port is open
-	if !waitForPort("localhost", 9223, 10*time.Second) {
+	if !waitForPort("localhost", 9223, timeout) {
 		return fmt.Errorf("Firefox remote debugging port 9223 is not open")
 	}
 
 	return nil
 }
 
+// portTimeout bounds a connection attempt's wait for the debugging port.
+const portTimeout = 10 * time.Second
+
 // waitForPort checks if a TCP port is open within a timeout period.
 func waitForPort(host string, port int, timeout time.Duration) bool {
-	address := fmt.Sprintf("%s:%d", host, port)
+	address := net.JoinHostPort(host, strconv.Itoa(port))
 	deadline := time.Now().Add(timeout)
 	for time.Now().Before(deadline) {
 		conn, err := net.DialTimeout("tcp", address, 500*time.Millisecond)
@@ -196,22 +287,35 @@ func (f *Firefox) handleResponse() {
 	defer f.Wg.Done()
 	for {
 		select {
-		case <-f.Done:
+		case <-f.Stop:
 			return
 		default:
 			var res browser.Result
-			if err := websocket.JSON.Receive(f.Ws, &res); err != nil {
-				fmt.Printf("Error receiving response: %v\n", err)
+			if err := f.Ws.ReadJSON(&res); err != nil {
+				if f.Stopping() {
+					return
+				}
+				if err == io.EOF {
+					// The browser dropped the page connection, i.e. the
+					// window was closed.
+					f.FailPending(browser.ErrConnectionClosed)
+					f.Closed(nil)
+					return
+				}
+				if f.Gone() {
+					return
+				}
+				f.Logger().Error("failed to receive response", "error", err)
 				continue
 			}
 
-			idStr := fmt.Sprintf("%d", res.ID)
-			f.Lock()
-			if ch, ok := f.Pending[idStr]; ok {
-				ch <- res
-				delete(f.Pending, idStr)
+			if res.Method != "" {
+				f.Trace("event", res.Method, res.Params)
+				f.Emit(browser.Event{Method: res.Method, Params: res.Params})
+				continue
 			}
-			f.Unlock()
+
+			f.Deliver(fmt.Sprintf("%d", res.ID), res)
 		}
 	}
 }
@@ -242,16 +346,28 @@ func FindPath() (string, error) {
 			`C:\Program Files (x86)\Mozilla Firefox\firefox.exe`,
 			filepath.Join("C:\\Users", username, "AppData\\Local\\Mozilla Firefox\\firefox.exe"),
 		}
+	} else if runtime.GOOS == "darwin" {
+		home, _ := os.UserHomeDir()
+		paths = []string{
+			"/Applications/Firefox.app/Contents/MacOS/firefox",
+			filepath.Join(home, "Applications/Firefox.app/Contents/MacOS/firefox"),
+		}
 	} else {
 		return "", fmt.Errorf("unsupported OS: %s", runtime.GOOS)
 	}
 
-	for _, p := range paths {
-		p = os.ExpandEnv(p)
-		if _, err := os.Stat(p); err == nil {
-			return p, nil
-		}
+	// Prefer a build native to the host over an emulated one.
+	if p, ok := browser.SelectBinary(paths); ok {
+		return p, nil
 	}
 
 	return "", fmt.Errorf("could not find Firefox binary")
 }
+
+// binaryNames lists the executable names searched for in portable mode.
+func binaryNames() []string {
+	if runtime.GOOS == "windows" {
+		return []string{"firefox.exe"}
+	}
+	return []string{"firefox"}
+}
@@ -0,0 +1,67 @@
+package firefox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// UserJS serializes prefs as user.js lines, sorted by name. Firefox only
+// knows string, bool and integer preferences, so other values are rejected;
+// floats are accepted when they hold a whole number, as JSON-decoded
+// settings do.
+func UserJS(prefs map[string]interface{}) ([]byte, error) {
+	names := make([]string, 0, len(prefs))
+	for name := range prefs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	for _, name := range names {
+		value, err := prefValue(prefs[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pref %s: %w", name, err)
+		}
+		key, _ := json.Marshal(name)
+		fmt.Fprintf(&b, "user_pref(%s, %s);\n", key, value)
+	}
+	return b.Bytes(), nil
+}
+
+func prefValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		s, _ := json.Marshal(v)
+		return string(s), nil
+	case bool:
+		return fmt.Sprint(v), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32:
+		return integer(fmt.Sprint(v))
+	case uint64:
+		if v > math.MaxInt32 {
+			return "", fmt.Errorf("%d overflows a 32-bit integer", v)
+		}
+		return fmt.Sprint(v), nil
+	case float32:
+		return prefValue(float64(v))
+	case float64:
+		if v != math.Trunc(v) {
+			return "", fmt.Errorf("%v is not an integer", v)
+		}
+		return integer(fmt.Sprintf("%.0f", v))
+	default:
+		return "", fmt.Errorf("unsupported type %T", v)
+	}
+}
+
+// integer checks that n fits Firefox's 32-bit integer prefs.
+func integer(n string) (string, error) {
+	var i int64
+	if _, err := fmt.Sscan(n, &i); err != nil || i < math.MinInt32 || i > math.MaxInt32 {
+		return "", fmt.Errorf("%s overflows a 32-bit integer", n)
+	}
+	return n, nil
+}
@@ -0,0 +1,35 @@
+package firefox_test
+
+import (
+	"testing"
+
+	"github.com/grngxd/majorca/browser/firefox"
+)
+
+func TestUserJS(t *testing.T) {
+	got, err := firefox.UserJS(map[string]interface{}{
+		"network.proxy.type":      1,
+		"network.proxy.http":      `proxy "local"`,
+		"devtools.chrome.enabled": true,
+		"privacy.sanitize.level":  float64(2),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `user_pref("devtools.chrome.enabled", true);
+user_pref("network.proxy.http", "proxy \"local\"");
+user_pref("network.proxy.type", 1);
+user_pref("privacy.sanitize.level", 2);
+`
+	if string(got) != want {
+		t.Errorf("UserJS() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUserJSRejectsInvalidValues(t *testing.T) {
+	for _, v := range []interface{}{1.5, int64(1) << 40, []string{"a"}} {
+		if _, err := firefox.UserJS(map[string]interface{}{"a": v}); err == nil {
+			t.Errorf("Expected an error for %#v", v)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package firefox
+
+import (
+	"sync"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+var scavengeOnce sync.Once
+
+// scavengeProfiles runs browser.CleanupStale once per process in the
+// background, so temporary profiles of crashed apps, around 100MB each, do
+// not pile up in the temp directory.
+func scavengeProfiles(f *Firefox) {
+	scavengeOnce.Do(func() {
+		go func() {
+			cleaned, err := browser.CleanupStale()
+			if len(cleaned) > 0 {
+				f.Logger().Info("removed orphaned temp profiles", "count", len(cleaned))
+			}
+			if err != nil {
+				f.Logger().Warn("failed to remove orphaned temp profiles", "error", err)
+			}
+		}()
+	})
+}
@@ -0,0 +1,17 @@
+package firefox
+
+import "github.com/grngxd/majorca/browser"
+
+func init() {
+	browser.Register("firefox", browser.Factory{
+		New: func(opts ...browser.Option) (browser.Browser, error) {
+			return New(opts...)
+		},
+		Available: func() bool {
+			_, err := FindPath()
+			return err == nil
+		},
+		Priority:    50,
+		Description: "Firefox via its remote debugging protocol",
+	})
+}
@@ -0,0 +1,51 @@
+package firefox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// Version runs the Firefox binary at path with --version and returns its
+// version number, e.g. "115.0" for "Mozilla Firefox 115.0esr". It works
+// without launching a browser window.
+func Version(path string) (string, error) {
+	out, err := exec.Command(path, "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to query Firefox version: %w", err)
+	}
+	v := browser.ParseVersion(string(out))
+	if v == "" {
+		return "", fmt.Errorf("unexpected Firefox version output %q", strings.TrimSpace(string(out)))
+	}
+	return v, nil
+}
+
+// BrowserVersion returns the version number of the Firefox binary.
+func (f *Firefox) BrowserVersion() (string, error) {
+	return Version(f.Path)
+}
+
+// ProtocolVersion returns the remote protocol version Firefox reports on
+// its /json/version endpoint.
+func (f *Firefox) ProtocolVersion() (string, error) {
+	resp, err := http.Get("http://localhost:9223/json/version")
+	if err != nil {
+		return "", fmt.Errorf("failed to query protocol version: %w", err)
+	}
+	defer resp.Body.Close()
+	var v struct {
+		Protocol string `json:"Protocol-Version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return "", fmt.Errorf("failed to decode protocol version: %w", err)
+	}
+	if v.Protocol == "" {
+		return "", fmt.Errorf("Firefox did not report a protocol version")
+	}
+	return v.Protocol, nil
+}
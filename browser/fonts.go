@@ -0,0 +1,101 @@
+package browser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Font is a font file bundled with the app.
+type Font struct {
+	Family string // CSS font-family name pages refer to
+	Weight string // CSS font-weight, e.g. "400" or "bold"; empty means normal
+	Style  string // CSS font-style, e.g. "italic"; empty means normal
+	Data   []byte // TTF, OTF, WOFF or WOFF2 file contents
+}
+
+// RemoteFontPatterns are the URL patterns blocked by WithoutRemoteFonts.
+var RemoteFontPatterns = []string{
+	"*.woff*",
+	"*.ttf*",
+	"*.otf*",
+	"*.eot*",
+	"*fonts.googleapis.com*",
+	"*fonts.gstatic.com*",
+	"*use.typekit.net*",
+}
+
+// WithoutRemoteFonts stops pages from downloading web fonts, so text renders
+// with bundled and installed fonts only.
+func WithoutRemoteFonts() Option {
+	return func(o *Options) {
+		o.BlockRemoteFonts = true
+	}
+}
+
+// WithFontStack forces every element to use the given font families, in
+// order of preference, regardless of the page's styles.
+func WithFontStack(families ...string) Option {
+	return func(o *Options) {
+		o.FontStack = families
+	}
+}
+
+// WithFonts makes fonts available to every page under their family names.
+// They are embedded in the page, so they work without network access and
+// alongside WithoutRemoteFonts.
+func WithFonts(fonts ...Font) Option {
+	return func(o *Options) {
+		o.Fonts = append(o.Fonts, fonts...)
+	}
+}
+
+// FontCSS returns the style sheet implementing the font options, or "" when
+// none are set.
+func (o *Options) FontCSS() string {
+	var css strings.Builder
+	for _, f := range o.Fonts {
+		format, mime := fontFormat(f.Data)
+		fmt.Fprintf(&css, "@font-face { font-family: %q; src: url(data:%s;base64,%s) format(%q);",
+			f.Family, mime, base64.StdEncoding.EncodeToString(f.Data), format)
+		if f.Weight != "" {
+			fmt.Fprintf(&css, " font-weight: %s;", f.Weight)
+		}
+		if f.Style != "" {
+			fmt.Fprintf(&css, " font-style: %s;", f.Style)
+		}
+		css.WriteString(" }\n")
+	}
+	if len(o.FontStack) > 0 {
+		quoted := make([]string, len(o.FontStack))
+		for i, family := range o.FontStack {
+			quoted[i] = family
+			if !genericFamilies[family] {
+				quoted[i] = fmt.Sprintf("%q", family)
+			}
+		}
+		fmt.Fprintf(&css, "*, *::before, *::after { font-family: %s !important; }\n", strings.Join(quoted, ", "))
+	}
+	return css.String()
+}
+
+// genericFamilies are CSS keywords, which stop working when quoted.
+var genericFamilies = map[string]bool{
+	"serif": true, "sans-serif": true, "monospace": true, "cursive": true,
+	"fantasy": true, "system-ui": true, "ui-serif": true, "ui-sans-serif": true,
+	"ui-monospace": true, "ui-rounded": true, "emoji": true, "math": true,
+}
+
+// fontFormat detects a font file's type from its signature.
+func fontFormat(data []byte) (format, mime string) {
+	switch {
+	case bytes.HasPrefix(data, []byte("wOF2")):
+		return "woff2", "font/woff2"
+	case bytes.HasPrefix(data, []byte("wOFF")):
+		return "woff", "font/woff"
+	case bytes.HasPrefix(data, []byte("OTTO")):
+		return "opentype", "font/otf"
+	}
+	return "truetype", "font/ttf"
+}
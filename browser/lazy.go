@@ -0,0 +1,167 @@
+package browser
+
+import (
+	"fmt"
+	"sync"
+)
+
+// WithLazyLaunch makes Open return at once without starting the browser.
+// It is launched on the first call that needs it: Load, Eval, Show, Start
+// or a version query. Bindings registered before that are kept and
+// installed at launch. Background and tray-resident apps then only pay for
+// the browser once the user opens a window.
+//
+// Open returns a wrapper rather than the backend's own type; Unwrap returns
+// the backend once it runs. Done is closed when the launched browser exits,
+// or on Kill if it never launched.
+func WithLazyLaunch() Option {
+	return func(o *Options) {
+		o.LazyLaunch = true
+	}
+}
+
+// lazy is the Browser returned by Open with WithLazyLaunch.
+type lazy struct {
+	mu       sync.Mutex
+	launch   func() (Browser, error)
+	b        Browser // nil until launched
+	killed   bool
+	bindings map[string]BindingFunc
+	order    []string // Binding names in registration order
+
+	done    chan struct{}
+	exitErr error
+}
+
+func newLazy(launch func() (Browser, error)) *lazy {
+	return &lazy{launch: launch, bindings: make(map[string]BindingFunc), done: make(chan struct{})}
+}
+
+// launched returns the running browser, or nil.
+func (l *lazy) launched() Browser {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.b
+}
+
+// get launches the browser on first use. A failed launch is tried again on
+// the next call.
+func (l *lazy) get() (Browser, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.b != nil {
+		return l.b, nil
+	}
+	if l.killed {
+		return nil, ErrBrowserGone
+	}
+
+	b, err := l.launch()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range l.order {
+		if err := b.Bind(name, l.bindings[name]); err != nil {
+			b.Kill()
+			return nil, err
+		}
+	}
+	l.b = b
+	go func() {
+		err := b.Wait()
+		l.mu.Lock()
+		l.exitErr = err
+		l.mu.Unlock()
+		close(l.done)
+	}()
+	return b, nil
+}
+
+func (l *lazy) Start() error {
+	_, err := l.get()
+	return err
+}
+
+// Show launches the browser if needed and, for backends that can hide
+// their window, shows it.
+func (l *lazy) Show() error {
+	b, err := l.get()
+	if err != nil {
+		return err
+	}
+	if s, ok := Unwrap(b).(interface{ Show() error }); ok {
+		return s.Show()
+	}
+	return nil
+}
+
+func (l *lazy) Kill() error {
+	l.mu.Lock()
+	b := l.b
+	if b == nil && !l.killed {
+		l.killed = true
+		close(l.done)
+	}
+	l.mu.Unlock()
+	if b == nil {
+		return nil
+	}
+	return b.Kill()
+}
+
+func (l *lazy) Load(url string) error {
+	b, err := l.get()
+	if err != nil {
+		return err
+	}
+	return b.Load(url)
+}
+
+func (l *lazy) Eval(expr string) (string, string, error) {
+	b, err := l.get()
+	if err != nil {
+		return "", "", err
+	}
+	return b.Eval(expr)
+}
+
+func (l *lazy) Bind(name string, f BindingFunc) error {
+	l.mu.Lock()
+	if l.b == nil {
+		defer l.mu.Unlock()
+		if _, exists := l.bindings[name]; exists {
+			return fmt.Errorf("binding %s already exists", name)
+		}
+		l.bindings[name] = f
+		l.order = append(l.order, name)
+		return nil
+	}
+	b := l.b
+	l.mu.Unlock()
+	return b.Bind(name, f)
+}
+
+func (l *lazy) Done() <-chan struct{} { return l.done }
+
+func (l *lazy) Wait() error {
+	<-l.done
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.exitErr
+}
+
+func (l *lazy) BrowserVersion() (string, error) {
+	b, err := l.get()
+	if err != nil {
+		return "", err
+	}
+	return b.BrowserVersion()
+}
+
+func (l *lazy) ProtocolVersion() (string, error) {
+	b, err := l.get()
+	if err != nil {
+		return "", err
+	}
+	return b.ProtocolVersion()
+}
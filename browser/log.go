@@ -0,0 +1,25 @@
+package browser
+
+import (
+	"context"
+	"log/slog"
+)
+
+// discardHandler drops every record; it backs the silent default logger.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+var silent = slog.New(discardHandler{})
+
+// Logger returns the logger configured with WithLogger, or a logger that
+// discards everything.
+func (b *BaseBrowser) Logger() *slog.Logger {
+	if b.Log == nil {
+		return silent
+	}
+	return b.Log
+}
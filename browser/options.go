@@ -0,0 +1,476 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/grngxd/majorca/browser/fetcher"
+)
+
+// WaitUntil names a page lifecycle stage that Load can wait for.
+type WaitUntil string
+
+const (
+	WaitDOMContentLoaded WaitUntil = "domcontentloaded"
+	WaitLoad             WaitUntil = "load"
+	WaitNetworkIdle      WaitUntil = "networkidle"
+)
+
+// DefaultCommandTimeout bounds how long a single protocol command may take.
+const DefaultCommandTimeout = 30 * time.Second
+
+// DefaultBrowserDownloadTimeout bounds provisioning a browser with
+// WithBrowserDownload, including the archive download.
+const DefaultBrowserDownloadTimeout = 10 * time.Minute
+
+// Options holds the launch configuration shared by all browser backends.
+type Options struct {
+	Backend           string        // Registered backend to use; empty selects automatically
+	PreferredBackends []string      // Tried first during automatic selection
+	Args              []string      // Extra command line flags passed to the browser
+	Headless          bool          // Run without a window, e.g. for CI or scraping
+	ExecutablePath    string        // Browser binary to launch, skipping discovery
+	MinVersion        string        // Oldest accepted browser version, see WithMinVersion
+	CommandTimeout    time.Duration // How long to wait for each protocol command
+	WaitUntil         WaitUntil     // Page stage Load waits for; empty returns immediately
+	Deterministic     bool          // Reproducible rendering, see WithDeterministicRendering
+	ReadLimit         int64         // Largest protocol message; zero means DefaultReadLimit
+	Compression       bool          // Compress protocol traffic
+	Fallback          Fallback      // Called when no browser is found, see WithFallbackMessage
+	CriticalRetry     bool          // Restart a dead browser and retry, see WithCriticalRetry
+	HandleSignals     bool          // Close the browser on SIGINT/SIGTERM, see WithSignalHandling
+	LazyLaunch        bool          // Launch on first use, see WithLazyLaunch
+	ConnectRetry      RetryPolicy   // Connecting to a starting browser, see WithConnectRetry
+	SlowCommand       time.Duration // Commands taking longer are logged, see WithSlowCommandLog
+	LargeMessage      int           // Commands with bigger payloads are logged
+
+	Logger         *slog.Logger // Library diagnostics; nil keeps the library silent
+	Trace          bool         // Log full protocol messages at debug level
+	Redaction      *Redaction   // Applied to traced messages
+	Stdout, Stderr io.Writer    // Browser process output; nil discards it
+
+	// Persistent profile directory. When empty, backends create a temporary
+	// profile and delete it on Kill.
+	ProfileDir string
+
+	// Portable mode: the browser binary and profile live next to the app
+	// executable instead of in system locations. Both paths may be relative,
+	// in which case they are resolved against AppDir() at launch time.
+	Portable   bool
+	BrowserDir string
+
+	// Pinned build downloaded when no installed browser is found, see
+	// WithBrowserDownload.
+	BrowserManifest *fetcher.Manifest
+	BrowserCache    string
+	// Bounds the download; zero means DefaultBrowserDownloadTimeout, see
+	// WithBrowserDownloadTimeout.
+	BrowserDownloadTimeout time.Duration
+
+	// Directory receiving downloads; empty keeps the browser default.
+	DownloadDir string
+
+	// Directory collecting browser crash minidumps, see WithCrashReports.
+	CrashDir string
+
+	// Firefox preferences written to the profile's user.js, see WithPrefs.
+	Prefs map[string]interface{}
+
+	// Fonts, see WithoutRemoteFonts, WithFontStack and WithFonts.
+	BlockRemoteFonts bool
+	FontStack        []string
+	Fonts            []Font
+
+	// Initial window geometry; zero values keep the browser defaults.
+	Width, Height int
+	X, Y          int
+	HasPosition   bool
+
+	// Window title, see WithTitle.
+	Title string
+
+	// Window modes, see WithKiosk, WithFullscreen and WithAlwaysOnTop.
+	Kiosk       bool
+	Fullscreen  bool
+	AlwaysOnTop bool
+}
+
+type Option func(*Options)
+
+// NewOptions applies opts on top of the defaults.
+func NewOptions(opts ...Option) *Options {
+	o := &Options{
+		CommandTimeout: DefaultCommandTimeout,
+		ConnectRetry:   DefaultConnectRetry,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithArgs appends extra command line flags for the browser process.
+func WithArgs(args ...string) Option {
+	return func(o *Options) {
+		o.Args = append(o.Args, args...)
+	}
+}
+
+// WithBackend selects a backend registered with Register instead of
+// choosing the best available one.
+func WithBackend(name string) Option {
+	return func(o *Options) {
+		o.Backend = name
+	}
+}
+
+// WithPreferredBackends makes Auto try the named backends first, in order,
+// before falling back to the remaining ones by priority.
+func WithPreferredBackends(names ...string) Option {
+	return func(o *Options) {
+		o.PreferredBackends = names
+	}
+}
+
+// WithFallbackMessage makes Auto call handler when no supported browser is
+// found, instead of failing silently. A nil handler shows MissingBrowserText
+// in a native message box. See Fallback for retrying after the handler
+// installed a browser.
+func WithFallbackMessage(handler Fallback) Option {
+	return func(o *Options) {
+		if handler == nil {
+			handler = ShowMissingBrowser
+		}
+		o.Fallback = handler
+	}
+}
+
+// WithExecutablePath launches the browser binary at path instead of
+// searching for one. Unlike the MAJORCA_BROWSER environment variable it only
+// affects the instance being created.
+func WithExecutablePath(path string) Option {
+	return func(o *Options) {
+		o.ExecutablePath = path
+	}
+}
+
+// WithMinVersion refuses to run browsers older than version, e.g. "120" or
+// "115.0", so features an app relies on fail at launch instead of at random
+// later. The check fails with ErrBrowserTooOld.
+func WithMinVersion(version string) Option {
+	return func(o *Options) {
+		o.MinVersion = version
+	}
+}
+
+// WithCommandTimeout changes how long Load, Eval and other commands wait for
+// the browser to respond before failing with ErrTimeout. Zero disables the
+// timeout.
+func WithCommandTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.CommandTimeout = d
+	}
+}
+
+// WithConnectRetry sets how Chrome and Firefox retry connecting to the
+// browser while it starts up, e.g. ExponentialBackoff(8, 100*time.Millisecond,
+// 2*time.Second). A Deadline bounds the whole connect, including each
+// attempt's wait for the debugging port. The default is DefaultConnectRetry.
+func WithConnectRetry(p RetryPolicy) Option {
+	return func(o *Options) {
+		o.ConnectRetry = p
+	}
+}
+
+// WithReadLimit changes the largest protocol message accepted from the
+// browser. Bigger messages, e.g. huge screenshots, fail with
+// ErrMessageTooLarge.
+func WithReadLimit(n int64) Option {
+	return func(o *Options) {
+		o.ReadLimit = n
+	}
+}
+
+// WithCompression compresses protocol traffic when the browser supports it,
+// which helps with large payloads over slow links such as remote browsers.
+// Local connections are usually faster without it.
+func WithCompression() Option {
+	return func(o *Options) {
+		o.Compression = true
+	}
+}
+
+// WithWaitUntil makes Load block until the new page reaches the given stage
+// instead of returning as soon as navigation starts, so a following Eval
+// runs against the new document.
+func WithWaitUntil(until WaitUntil) Option {
+	return func(o *Options) {
+		o.WaitUntil = until
+	}
+}
+
+// WithSlowCommandLog logs a warning for every protocol command that takes
+// longer than latency or whose request or response is larger than size
+// bytes, with the command id to correlate it with debug logs and traces.
+// Zero disables either threshold.
+func WithSlowCommandLog(latency time.Duration, size int) Option {
+	return func(o *Options) {
+		o.SlowCommand = latency
+		o.LargeMessage = size
+	}
+}
+
+// WithLogger routes the library's diagnostics to l. Protocol traffic is
+// logged at debug level.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *Options) {
+		o.Logger = l
+	}
+}
+
+// WithProtocolTrace logs every protocol command, response and event with
+// its payload at debug level, masking values according to r. A nil r uses
+// DefaultRedaction.
+func WithProtocolTrace(r *Redaction) Option {
+	return func(o *Options) {
+		if r == nil {
+			r = DefaultRedaction()
+		}
+		o.Trace = true
+		o.Redaction = r
+	}
+}
+
+// WithBrowserOutput captures the browser process's stdout and stderr, which
+// are discarded by default.
+func WithBrowserOutput(stdout, stderr io.Writer) Option {
+	return func(o *Options) {
+		o.Stdout = stdout
+		o.Stderr = stderr
+	}
+}
+
+// WithHeadless launches the browser without a visible window. Load, Eval and
+// Bind keep working, which makes the package usable as an automation driver.
+// The chrome and bidi backends support it; Firefox and Safari refuse to
+// start headless.
+func WithHeadless() Option {
+	return func(o *Options) {
+		o.Headless = true
+	}
+}
+
+// WithDeterministicRendering makes pages render the same on every machine so
+// screenshot comparisons don't flake: timezone and locale are pinned to UTC
+// and en-US, Date and Math.random are frozen, animations and transitions are
+// disabled and font rendering ignores host settings.
+func WithDeterministicRendering() Option {
+	return func(o *Options) {
+		o.Deterministic = true
+	}
+}
+
+// WithWindowSize sets the initial size of the app window.
+func WithWindowSize(width, height int) Option {
+	return func(o *Options) {
+		o.Width = width
+		o.Height = height
+	}
+}
+
+// WithWindowPosition sets the initial screen position of the app window.
+func WithWindowPosition(x, y int) Option {
+	return func(o *Options) {
+		o.X = x
+		o.Y = y
+		o.HasPosition = true
+	}
+}
+
+// WithTitle sets the app window title from the start and keeps it across
+// navigations.
+func WithTitle(title string) Option {
+	return func(o *Options) {
+		o.Title = title
+	}
+}
+
+// WithKiosk launches the browser in kiosk mode: fullscreen without any
+// browser UI and without a way for the user to leave it, for point-of-sale
+// terminals and wall-mounted dashboards.
+func WithKiosk() Option {
+	return func(o *Options) {
+		o.Kiosk = true
+	}
+}
+
+// WithFullscreen starts the app window fullscreen. Unlike WithKiosk the user
+// can still leave fullscreen, e.g. with F11. Only Chrome supports it.
+func WithFullscreen() Option {
+	return func(o *Options) {
+		o.Fullscreen = true
+	}
+}
+
+// WithAlwaysOnTop keeps the app window above other windows. It is best
+// effort, see BaseBrowser.SetAlwaysOnTop; failures are logged.
+func WithAlwaysOnTop() Option {
+	return func(o *Options) {
+		o.AlwaysOnTop = true
+	}
+}
+
+// WithProfileDir uses dir as a persistent browser profile that is kept
+// between runs instead of a temporary one.
+func WithProfileDir(dir string) Option {
+	return func(o *Options) {
+		o.ProfileDir = dir
+	}
+}
+
+// WithPrefs sets Firefox preferences such as proxy, devtools or privacy
+// settings in the profile's user.js. Values must be strings, bools or
+// integers. Repeated calls merge, later values winning. Chrome ignores it.
+func WithPrefs(prefs map[string]interface{}) Option {
+	return func(o *Options) {
+		if o.Prefs == nil {
+			o.Prefs = make(map[string]interface{})
+		}
+		for name, value := range prefs {
+			o.Prefs[name] = value
+		}
+	}
+}
+
+// WithCrashReports enables the browser's crash reporter without uploading
+// anything and collects minidumps in dir, so they can be picked up from
+// LastCrashReport and shipped by the app. Exit status and stderr are
+// recorded for every crash regardless of this option.
+func WithCrashReports(dir string) Option {
+	return func(o *Options) {
+		o.CrashDir = dir
+	}
+}
+
+// WithDownloadDir saves files downloaded by pages to dir.
+func WithDownloadDir(dir string) Option {
+	return func(o *Options) {
+		o.DownloadDir = dir
+	}
+}
+
+// WithBrowserDownload makes a missing browser recoverable: when no installed
+// browser is found, the build m pins for this platform is downloaded into
+// cacheDir, verified and launched instead. Later launches reuse it. An
+// empty cacheDir means fetcher.DefaultCacheDir.
+func WithBrowserDownload(m *fetcher.Manifest, cacheDir string) Option {
+	return func(o *Options) {
+		o.BrowserManifest = m
+		o.BrowserCache = cacheDir
+	}
+}
+
+// WithBrowserDownloadTimeout changes how long WithBrowserDownload may take
+// to provision a browser before New gives up. The default is
+// DefaultBrowserDownloadTimeout.
+func WithBrowserDownloadTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.BrowserDownloadTimeout = d
+	}
+}
+
+// DownloadBrowser provisions the build set with WithBrowserDownload and
+// returns its executable. kind is the manifest's browser, "chromium" or
+// "firefox"; backends only use builds of their own kind. It fails once the
+// download timeout passes.
+func (o *Options) DownloadBrowser(kind string) (string, error) {
+	m := o.BrowserManifest
+	if m == nil {
+		return "", fmt.Errorf("no browser download configured")
+	}
+	if m.Browser != kind {
+		return "", fmt.Errorf("browser manifest pins %s, not %s", m.Browser, kind)
+	}
+	if o.Logger != nil {
+		o.Logger.Info("no installed browser found, provisioning pinned build", "browser", kind)
+	}
+	timeout := o.BrowserDownloadTimeout
+	if timeout <= 0 {
+		timeout = DefaultBrowserDownloadTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return m.Ensure(ctx, o.BrowserCache)
+}
+
+// WithPortable enables portable-app mode. browserDir is searched for the
+// browser binary and profileDir is used as a persistent profile; relative
+// paths are resolved against the directory of the running executable, so
+// the app keeps working after being copied to another location.
+func WithPortable(browserDir, profileDir string) Option {
+	return func(o *Options) {
+		o.Portable = true
+		o.BrowserDir = browserDir
+		o.ProfileDir = profileDir
+	}
+}
+
+// AppDir returns the directory containing the running executable, with
+// symlinks resolved.
+func AppDir() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return filepath.Dir(exe), nil
+}
+
+// ResolvePortable turns a portable path into an absolute one. Absolute paths
+// are returned unchanged, relative ones are joined with AppDir().
+func ResolvePortable(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path), nil
+	}
+	dir, err := AppDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, path), nil
+}
+
+// FindPortable looks for the first of names inside the portable browser
+// directory.
+func (o *Options) FindPortable(names ...string) (string, error) {
+	dir, err := ResolvePortable(o.BrowserDir)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range names {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("could not find browser binary in %s", dir)
+}
+
+// Profile returns the absolute persistent profile directory, or "" when the
+// backend should create a throwaway one. In portable mode the directory is
+// relative to AppDir(), otherwise to the working directory.
+func (o *Options) Profile() (string, error) {
+	if o.ProfileDir == "" {
+		return "", nil
+	}
+	if o.Portable {
+		return ResolvePortable(o.ProfileDir)
+	}
+	return filepath.Abs(o.ProfileDir)
+}
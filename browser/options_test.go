@@ -0,0 +1,88 @@
+package browser_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+	"github.com/grngxd/majorca/browser/fetcher"
+)
+
+func TestResolvePortable(t *testing.T) {
+	dir, err := browser.AppDir()
+	if err != nil {
+		t.Fatalf("Failed to get app directory: %v", err)
+	}
+
+	got, err := browser.ResolvePortable("profile")
+	if err != nil {
+		t.Fatalf("Failed to resolve relative path: %v", err)
+	}
+	if want := filepath.Join(dir, "profile"); got != want {
+		t.Errorf("Relative path resolved to %s, want %s", got, want)
+	}
+
+	abs := filepath.Join(t.TempDir(), "browser")
+	got, err = browser.ResolvePortable(abs)
+	if err != nil {
+		t.Fatalf("Failed to resolve absolute path: %v", err)
+	}
+	if got != abs {
+		t.Errorf("Absolute path resolved to %s, want %s", got, abs)
+	}
+}
+
+func TestFontCSS(t *testing.T) {
+	o := browser.NewOptions(
+		browser.WithFonts(browser.Font{Family: "Inter", Weight: "700", Data: []byte("wOF2data")}),
+		browser.WithFontStack("Inter", "sans-serif"),
+	)
+	css := o.FontCSS()
+	for _, want := range []string{
+		`font-family: "Inter"; src: url(data:font/woff2;base64,`,
+		`format("woff2"); font-weight: 700;`,
+		`font-family: "Inter", sans-serif !important;`,
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("Font CSS missing %q:\n%s", want, css)
+		}
+	}
+
+	if css := browser.NewOptions().FontCSS(); css != "" {
+		t.Errorf("Expected no CSS without font options, got %q", css)
+	}
+}
+
+func TestDownloadBrowserTimeout(t *testing.T) {
+	// A server that sends headers and then stalls mid-download.
+	stall := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-stall:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(stall)
+
+	m := &fetcher.Manifest{Browser: "chromium", Builds: []fetcher.Build{{
+		OS: runtime.GOOS, Arch: runtime.GOARCH, Revision: "1", URL: srv.URL + "/chrome.zip",
+		SHA256: strings.Repeat("0", 64), Executable: "chrome",
+	}}}
+	o := browser.NewOptions(browser.WithBrowserDownload(m, t.TempDir()), browser.WithBrowserDownloadTimeout(100*time.Millisecond))
+
+	start := time.Now()
+	if _, err := o.DownloadBrowser("chromium"); err == nil {
+		t.Error("a stalled download succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("DownloadBrowser took %v, want the 100ms timeout to end it", elapsed)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+
+package browser
+
+import (
+	"os"
+	"syscall"
+)
+
+// processAlive reports whether pid exists without affecting it.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
+
+// ownedByUser reports whether info belongs to the current user.
+func ownedByUser(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(st.Uid) == os.Getuid()
+}
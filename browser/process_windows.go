@@ -0,0 +1,32 @@
+package browser
+
+import (
+	"os"
+	"syscall"
+)
+
+const stillActive = 259
+
+// processAlive reports whether pid exists without affecting it.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	h, err := syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer syscall.CloseHandle(h)
+
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// ownedByUser reports whether info belongs to the current user. The temp
+// directory is per user on Windows, so everything in it qualifies.
+func ownedByUser(info os.FileInfo) bool {
+	return true
+}
@@ -0,0 +1,210 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TempResource records something majorca created that has to be cleaned up
+// if the app dies without calling Kill, such as a temporary profile.
+type TempResource struct {
+	OwnerPID   int       `json:"ownerPid"`   // The majorca app that created it
+	BrowserPID int       `json:"browserPid"` // The browser using it, if started
+	Profile    string    `json:"profile"`    // Temporary profile directory
+	Port       int       `json:"port"`       // Remote debugging port
+	Created    time.Time `json:"created"`
+}
+
+// unregisteredProfileAge is how old a temporary profile without a registry
+// entry must be before CleanupStale considers it abandoned. Such profiles
+// come from older versions or from apps that died between creating the
+// profile and registering it.
+const unregisteredProfileAge = 24 * time.Hour
+
+// tempProfilePrefixes are the names of the temporary profiles the backends
+// create directly in os.TempDir. CleanupStale removes nothing else.
+var tempProfilePrefixes = []string{"chrome_profile_", "firefox_profile_"}
+
+// RegistryDir is where temp resources are recorded. It lives in the user's
+// cache directory rather than the shared temp directory, so other users can
+// neither read nor plant entries.
+func RegistryDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate registry: %w", err)
+	}
+	return filepath.Join(dir, "majorca", "registry"), nil
+}
+
+// removableProfile reports whether p is a temporary profile one of the
+// backends created, i.e. directly in os.TempDir with a profile prefix.
+func removableProfile(p string) bool {
+	if !filepath.IsAbs(p) || filepath.Dir(filepath.Clean(p)) != filepath.Clean(os.TempDir()) {
+		return false
+	}
+	for _, prefix := range tempProfilePrefixes {
+		if strings.HasPrefix(filepath.Base(p), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterTemp records r on disk and returns an id for UnregisterTemp.
+func RegisterTemp(r TempResource) (string, error) {
+	if r.OwnerPID == 0 {
+		r.OwnerPID = os.Getpid()
+	}
+	if r.Created.IsZero() {
+		r.Created = time.Now()
+	}
+
+	dir, err := RegistryDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create registry: %w", err)
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+
+	id := fmt.Sprintf("%d-%d", r.OwnerPID, r.Created.UnixNano())
+	if err := os.WriteFile(filepath.Join(dir, id+".json"), data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write registry entry: %w", err)
+	}
+	return id, nil
+}
+
+// UpdateTemp rewrites an existing entry, e.g. once the browser PID is known.
+func UpdateTemp(id string, r TempResource) error {
+	dir, err := RegistryDir()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, id+".json"), data, 0600)
+}
+
+// UnregisterTemp forgets an entry after its resources were cleaned up.
+func UnregisterTemp(id string) {
+	if dir, err := RegistryDir(); id != "" && err == nil {
+		os.Remove(filepath.Join(dir, id+".json"))
+	}
+}
+
+// CleanupStale removes resources left behind by majorca apps that are no
+// longer running, e.g. temp profiles of a crashed run. Entries whose owner or
+// browser is still alive are left alone. Temporary profiles of the current
+// user without a registry entry are removed once they are a day old. Only
+// temporary profiles directly in os.TempDir are ever deleted; entries naming
+// any other path are dropped. It returns the cleaned resources.
+func CleanupStale() ([]TempResource, error) {
+	dir, err := RegistryDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read registry: %w", err)
+	}
+
+	var cleaned []TempResource
+	var errs []string
+	known := make(map[string]bool) // Profiles of kept entries
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		p := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+
+		var r TempResource
+		if err := json.Unmarshal(data, &r); err != nil {
+			os.Remove(p)
+			continue
+		}
+		if r.OwnerPID == os.Getpid() || processAlive(r.OwnerPID) {
+			known[filepath.Clean(r.Profile)] = true
+			continue
+		}
+		if r.BrowserPID != 0 && processAlive(r.BrowserPID) {
+			// The orphaned browser still holds the profile open.
+			known[filepath.Clean(r.Profile)] = true
+			continue
+		}
+
+		if r.Profile != "" && !removableProfile(r.Profile) {
+			os.Remove(p)
+			continue
+		}
+		if r.Profile != "" {
+			if err := os.RemoveAll(r.Profile); err != nil {
+				errs = append(errs, err.Error())
+				known[filepath.Clean(r.Profile)] = true
+				continue
+			}
+		}
+		os.Remove(p)
+		cleaned = append(cleaned, r)
+	}
+
+	tmp, err := os.ReadDir(os.TempDir())
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, e := range tmp {
+		p := filepath.Join(os.TempDir(), e.Name())
+		if !e.IsDir() || known[p] || !removableProfile(p) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || !ownedByUser(info) || time.Since(info.ModTime()) < unregisteredProfileAge {
+			continue
+		}
+		if err := os.RemoveAll(p); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		cleaned = append(cleaned, TempResource{Profile: p})
+	}
+
+	if len(errs) > 0 {
+		return cleaned, fmt.Errorf("failed to clean up: %s", strings.Join(errs, "; "))
+	}
+	return cleaned, nil
+}
+
+// TrackTemp records a temporary profile used by the started browser process
+// so CleanupStale can remove it if the app crashes. Failures are only logged
+// since the registry is best effort.
+func (b *BaseBrowser) TrackTemp(profile string, port int) {
+	r := TempResource{Profile: profile, Port: port}
+	if b.Cmd != nil && b.Cmd.Process != nil {
+		r.BrowserPID = b.Cmd.Process.Pid
+	}
+	id, err := RegisterTemp(r)
+	if err != nil {
+		b.Logger().Warn("failed to register temp profile", "path", profile, "error", err)
+		return
+	}
+	b.tempID = id
+}
+
+// UntrackTemp drops the registry entry created by TrackTemp.
+func (b *BaseBrowser) UntrackTemp() {
+	UnregisterTemp(b.tempID)
+	b.tempID = ""
+}
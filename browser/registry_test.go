@@ -0,0 +1,75 @@
+package browser_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+func TestCleanupStale(t *testing.T) {
+	tmp, cache := t.TempDir(), t.TempDir()
+	for _, env := range []string{"TMPDIR", "TMP", "TEMP"} {
+		t.Setenv(env, tmp)
+	}
+	for _, env := range []string{"XDG_CACHE_HOME", "LocalAppData", "HOME"} {
+		t.Setenv(env, cache)
+	}
+
+	stale := filepath.Join(os.TempDir(), "chrome_profile_1")
+	live := filepath.Join(os.TempDir(), "chrome_profile_2")
+	victim := filepath.Join(t.TempDir(), "home")
+	abandoned := filepath.Join(os.TempDir(), "firefox_profile_1")
+	recent := filepath.Join(os.TempDir(), "firefox_profile_2")
+	for _, dir := range []string{stale, live, victim, abandoned, recent} {
+		os.MkdirAll(dir, 0755)
+	}
+	// Unregistered profiles are only removed once they are old.
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(abandoned, old, old)
+
+	// A negative PID never belongs to a running app.
+	if _, err := browser.RegisterTemp(browser.TempResource{OwnerPID: -1, Profile: stale}); err != nil {
+		t.Fatalf("Failed to register stale profile: %v", err)
+	}
+	if _, err := browser.RegisterTemp(browser.TempResource{Profile: live}); err != nil {
+		t.Fatalf("Failed to register live profile: %v", err)
+	}
+	if _, err := browser.RegisterTemp(browser.TempResource{OwnerPID: -1, Profile: victim}); err != nil {
+		t.Fatalf("Failed to register planted entry: %v", err)
+	}
+
+	cleaned, err := browser.CleanupStale()
+	if err != nil {
+		t.Fatalf("Failed to clean up: %v", err)
+	}
+	if len(cleaned) != 2 || cleaned[0].Profile != stale || cleaned[1].Profile != abandoned {
+		t.Errorf("Cleaned %v, want %s and %s", cleaned, stale, abandoned)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("Stale profile was not removed")
+	}
+	if _, err := os.Stat(live); err != nil {
+		t.Errorf("Profile of the running app was removed")
+	}
+	if _, err := os.Stat(abandoned); !os.IsNotExist(err) {
+		t.Errorf("Abandoned unregistered profile was not removed")
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("Recent unregistered profile was removed")
+	}
+	if _, err := os.Stat(victim); err != nil {
+		t.Errorf("Directory outside the temp directory was removed")
+	}
+
+	dir, err := browser.RegistryDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(dir); err != nil || runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		t.Errorf("Registry %s is accessible to other users", dir)
+	}
+}
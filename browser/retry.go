@@ -0,0 +1,184 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// WithCriticalRetry makes Open survive a dead browser: when Load, Eval or
+// Bind fail because the browser process or its connection is gone, the
+// browser is relaunched with the same options, bindings are registered
+// again, the last loaded URL is restored and the call is retried once
+// before the error is returned. This suits unattended deployments such as
+// kiosks, where nobody is around to restart the app.
+//
+// Open then returns a wrapper rather than the backend's own type; use
+// Unwrap to reach the running backend. Done and Wait refer to the current
+// browser, so a restart replaces them. An explicit Kill ends this: later
+// calls return ErrBrowserGone instead of relaunching.
+func WithCriticalRetry() Option {
+	return func(o *Options) {
+		o.CriticalRetry = true
+	}
+}
+
+// Unwrap returns the backend running behind b if b was opened with
+// WithCriticalRetry or WithLazyLaunch, and b itself otherwise. A lazy
+// browser that was not launched yet is returned as is.
+func Unwrap(b Browser) Browser {
+	switch w := b.(type) {
+	case *retrying:
+		return w.get()
+	case *lazy:
+		if inner := w.launched(); inner != nil {
+			return Unwrap(inner)
+		}
+	}
+	return b
+}
+
+// retrying is the Browser returned by Open with WithCriticalRetry.
+type retrying struct {
+	mu       sync.Mutex
+	current  Browser
+	launch   func() (Browser, error)
+	log      *slog.Logger
+	url      string // Last URL loaded successfully
+	bindings map[string]BindingFunc
+	killed   bool // Kill was called; never relaunch
+}
+
+func newRetrying(launch func() (Browser, error), log *slog.Logger) (*retrying, error) {
+	b, err := launch()
+	if err != nil {
+		return nil, err
+	}
+	if log == nil {
+		log = silent
+	}
+	return &retrying{current: b, launch: launch, log: log, bindings: make(map[string]BindingFunc)}, nil
+}
+
+func (r *retrying) get() Browser {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// do runs op and, if it failed because the browser died, restarts the
+// browser and runs op once more.
+func (r *retrying) do(op func(b Browser) error) error {
+	r.mu.Lock()
+	b, killed := r.current, r.killed
+	r.mu.Unlock()
+	if killed {
+		return ErrBrowserGone
+	}
+	err := op(b)
+	if err == nil || !dead(b, err) {
+		return err
+	}
+	r.log.Warn("browser is gone, restarting it", "error", err)
+	nb, rerr := r.restart(b)
+	if rerr == ErrBrowserGone {
+		return rerr
+	}
+	if rerr != nil {
+		return fmt.Errorf("%w (restart failed: %v)", err, rerr)
+	}
+	return op(nb)
+}
+
+// dead reports whether err means b can no longer run commands.
+func dead(b Browser, err error) bool {
+	if errors.Is(err, ErrConnectionClosed) || errors.Is(err, ErrBrowserGone) {
+		return true
+	}
+	select {
+	case <-b.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// restart replaces failed with a fresh browser and restores the session.
+// If another call restarted it already, the new browser is returned as is.
+func (r *retrying) restart(failed Browser) (Browser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.killed {
+		return nil, ErrBrowserGone
+	}
+	if r.current != failed {
+		return r.current, nil
+	}
+
+	failed.Kill()
+	b, err := r.launch()
+	if err != nil {
+		return nil, err
+	}
+	for name, f := range r.bindings {
+		if err := b.Bind(name, f); err != nil {
+			b.Kill()
+			return nil, fmt.Errorf("failed to restore binding %s: %w", name, err)
+		}
+	}
+	if r.url != "" {
+		if err := b.Load(r.url); err != nil {
+			b.Kill()
+			return nil, fmt.Errorf("failed to restore %s: %w", r.url, err)
+		}
+	}
+	r.current = b
+	return b, nil
+}
+
+func (r *retrying) Start() error { return r.get().Start() }
+
+// Kill kills the current browser for good.
+func (r *retrying) Kill() error {
+	r.mu.Lock()
+	r.killed = true
+	b := r.current
+	r.mu.Unlock()
+	return b.Kill()
+}
+
+func (r *retrying) Load(url string) error {
+	err := r.do(func(b Browser) error { return b.Load(url) })
+	if err == nil {
+		r.mu.Lock()
+		r.url = url
+		r.mu.Unlock()
+	}
+	return err
+}
+
+func (r *retrying) Eval(expr string) (string, string, error) {
+	var value, typ string
+	err := r.do(func(b Browser) error {
+		var err error
+		value, typ, err = b.Eval(expr)
+		return err
+	})
+	return value, typ, err
+}
+
+func (r *retrying) Bind(name string, f BindingFunc) error {
+	err := r.do(func(b Browser) error { return b.Bind(name, f) })
+	if err == nil {
+		r.mu.Lock()
+		r.bindings[name] = f
+		r.mu.Unlock()
+	}
+	return err
+}
+
+func (r *retrying) Done() <-chan struct{}            { return r.get().Done() }
+func (r *retrying) Wait() error                      { return r.get().Wait() }
+func (r *retrying) BrowserVersion() (string, error)  { return r.get().BrowserVersion() }
+func (r *retrying) ProtocolVersion() (string, error) { return r.get().ProtocolVersion() }
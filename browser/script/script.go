@@ -0,0 +1,241 @@
+// Package script runs declarative automation scripts against a browser, so
+// smoke tests for majorca apps can be written without Go.
+//
+// A script is a JSON document with a list of steps:
+//
+//	{
+//	  "name": "login works",
+//	  "steps": [
+//	    {"action": "goto", "url": "http://localhost:8080"},
+//	    {"action": "waitFor", "selector": "#user", "timeout": "5s"},
+//	    {"action": "type", "selector": "#user", "text": "admin"},
+//	    {"action": "click", "selector": "button[type=submit]"},
+//	    {"action": "assert", "selector": "h1", "contains": "Welcome"},
+//	    {"action": "screenshot", "path": "welcome.png"}
+//	  ]
+//	}
+package script
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// Script is a named list of steps.
+type Script struct {
+	Name  string `json:"name"`
+	Steps []Step `json:"steps"`
+}
+
+// Step is a single action. Which fields are used depends on Action.
+type Step struct {
+	Action   string `json:"action"`   // goto, waitFor, click, type, screenshot or assert
+	URL      string `json:"url"`      // goto
+	Selector string `json:"selector"` // waitFor, click, type, assert
+	Text     string `json:"text"`     // type
+	Expr     string `json:"expr"`     // assert: JavaScript that must be truthy
+	Contains string `json:"contains"` // assert: substring of the selector's text
+	Path     string `json:"path"`     // screenshot
+	Timeout  string `json:"timeout"`  // waitFor, e.g. "5s"
+}
+
+// DefaultTimeout is used by waitFor steps without an explicit timeout.
+const DefaultTimeout = 10 * time.Second
+
+// Load reads a script from a JSON file.
+func Load(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes a JSON script and validates its steps.
+func Parse(data []byte) (*Script, error) {
+	var s Script
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to decode script: %w", err)
+	}
+	for i, step := range s.Steps {
+		if err := step.validate(); err != nil {
+			return nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return &s, nil
+}
+
+func (s Step) validate() error {
+	switch s.Action {
+	case "goto":
+		if s.URL == "" {
+			return fmt.Errorf("goto requires url")
+		}
+	case "waitFor", "click", "type":
+		if s.Selector == "" {
+			return fmt.Errorf("%s requires selector", s.Action)
+		}
+	case "assert":
+		if s.Expr == "" && s.Selector == "" {
+			return fmt.Errorf("assert requires expr or selector")
+		}
+	case "screenshot":
+		if s.Path == "" {
+			return fmt.Errorf("screenshot requires path")
+		}
+	default:
+		return fmt.Errorf("unknown action %q", s.Action)
+	}
+	if s.Timeout != "" {
+		if _, err := time.ParseDuration(s.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+	return nil
+}
+
+// Run executes the steps in order and stops at the first failure. Progress is
+// written to log if it is not nil.
+func (s *Script) Run(b browser.Browser, log io.Writer) error {
+	if log == nil {
+		log = io.Discard
+	}
+	for i, step := range s.Steps {
+		fmt.Fprintf(log, "[%d/%d] %s\n", i+1, len(s.Steps), step)
+		if err := step.run(b); err != nil {
+			return fmt.Errorf("step %d (%s): %w", i+1, step.Action, err)
+		}
+	}
+	return nil
+}
+
+func (s Step) String() string {
+	switch s.Action {
+	case "goto":
+		return "goto " + s.URL
+	case "type":
+		return fmt.Sprintf("type %q into %s", s.Text, s.Selector)
+	case "assert":
+		if s.Expr != "" {
+			return "assert " + s.Expr
+		}
+		return fmt.Sprintf("assert %s contains %q", s.Selector, s.Contains)
+	case "screenshot":
+		return "screenshot " + s.Path
+	}
+	return s.Action + " " + s.Selector
+}
+
+// quote turns a Go string into a JavaScript string literal.
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s Step) run(b browser.Browser) error {
+	switch s.Action {
+	case "goto":
+		return b.Load(s.URL)
+
+	case "waitFor":
+		timeout := DefaultTimeout
+		if s.Timeout != "" {
+			timeout, _ = time.ParseDuration(s.Timeout)
+		}
+		deadline := time.Now().Add(timeout)
+		for {
+			v, _, err := b.Eval(fmt.Sprintf("document.querySelector(%s) !== null", quote(s.Selector)))
+			if err == nil && v == "true" {
+				return nil
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("%s did not appear within %s", s.Selector, timeout)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+
+	case "click":
+		return evalTrue(b, fmt.Sprintf(`(() => {
+			const el = document.querySelector(%s);
+			if (!el) return false;
+			el.click();
+			return true;
+		})()`, quote(s.Selector)), "no element matches "+s.Selector)
+
+	case "type":
+		return evalTrue(b, fmt.Sprintf(`(() => {
+			const el = document.querySelector(%s);
+			if (!el) return false;
+			el.focus();
+			el.value = %s;
+			el.dispatchEvent(new Event("input", {bubbles: true}));
+			el.dispatchEvent(new Event("change", {bubbles: true}));
+			return true;
+		})()`, quote(s.Selector), quote(s.Text)), "no element matches "+s.Selector)
+
+	case "assert":
+		if s.Expr != "" {
+			return evalTrue(b, fmt.Sprintf("!!(%s)", s.Expr), "expression is falsy")
+		}
+		// Backends render null differently, so report absence explicitly.
+		raw, _, err := b.Eval(fmt.Sprintf(`(() => {
+			const el = document.querySelector(%s);
+			return JSON.stringify({found: !!el, text: el ? el.innerText : ""});
+		})()`, quote(s.Selector)))
+		if err != nil {
+			return err
+		}
+		var res struct {
+			Found bool   `json:"found"`
+			Text  string `json:"text"`
+		}
+		if err := json.Unmarshal([]byte(raw), &res); err != nil {
+			return fmt.Errorf("failed to unmarshal %s text: %w", s.Selector, err)
+		}
+		if !res.Found {
+			return fmt.Errorf("no element matches %s", s.Selector)
+		}
+		if !strings.Contains(res.Text, s.Contains) {
+			return fmt.Errorf("%s text %q does not contain %q", s.Selector, res.Text, s.Contains)
+		}
+		return nil
+
+	case "screenshot":
+		return screenshot(b, s.Path)
+	}
+	return fmt.Errorf("unknown action %q", s.Action)
+}
+
+func evalTrue(b browser.Browser, expr, failure string) error {
+	v, _, err := b.Eval(expr)
+	if err != nil {
+		return err
+	}
+	if v != "true" {
+		return fmt.Errorf("%s", failure)
+	}
+	return nil
+}
+
+// screenshotter is implemented by backends that can capture the page.
+type screenshotter interface {
+	Screenshot(format string, quality int) ([]byte, error)
+}
+
+func screenshot(b browser.Browser, path string) error {
+	s, ok := b.(screenshotter)
+	if !ok {
+		return fmt.Errorf("this browser does not support screenshots")
+	}
+	img, err := s.Screenshot("png", 0)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, img, 0644)
+}
@@ -0,0 +1,96 @@
+package script_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/grngxd/majorca/browser"
+	"github.com/grngxd/majorca/browser/script"
+)
+
+// fakeBrowser answers every Eval with a fixed value and records calls.
+type fakeBrowser struct {
+	browser.BaseBrowser
+	value  string
+	loaded []string
+	evals  []string
+}
+
+func (f *fakeBrowser) Load(url string) error {
+	f.loaded = append(f.loaded, url)
+	return nil
+}
+
+func (f *fakeBrowser) Eval(expr string) (string, string, error) {
+	f.evals = append(f.evals, expr)
+	return f.value, "boolean", nil
+}
+
+func TestRun(t *testing.T) {
+	s, err := script.Parse([]byte(`{"steps":[
+		{"action":"goto","url":"https://example.com"},
+		{"action":"waitFor","selector":"#q"},
+		{"action":"type","selector":"#q","text":"it's \"quoted\""},
+		{"action":"click","selector":"button"}
+	]}`))
+	if err != nil {
+		t.Fatalf("Failed to parse script: %v", err)
+	}
+
+	b := &fakeBrowser{value: "true"}
+	if err := s.Run(b, nil); err != nil {
+		t.Fatalf("Failed to run script: %v", err)
+	}
+	if len(b.loaded) != 1 || b.loaded[0] != "https://example.com" {
+		t.Errorf("Loaded %v, want [https://example.com]", b.loaded)
+	}
+	if !strings.Contains(b.evals[1], `"it's \"quoted\""`) {
+		t.Errorf("Typed text was not quoted safely: %s", b.evals[1])
+	}
+}
+
+func TestRunStopsOnFailure(t *testing.T) {
+	s, err := script.Parse([]byte(`{"steps":[
+		{"action":"assert","expr":"false"},
+		{"action":"goto","url":"https://example.com"}
+	]}`))
+	if err != nil {
+		t.Fatalf("Failed to parse script: %v", err)
+	}
+
+	b := &fakeBrowser{value: "false"}
+	if err := s.Run(b, nil); err == nil || !strings.Contains(err.Error(), "step 1") {
+		t.Errorf("Expected step 1 to fail, got %v", err)
+	}
+	if len(b.loaded) != 0 {
+		t.Errorf("Steps after the failure were run")
+	}
+}
+
+func TestParseRejectsUnknownAction(t *testing.T) {
+	if _, err := script.Parse([]byte(`{"steps":[{"action":"dance"}]}`)); err == nil {
+		t.Errorf("Expected unknown action to be rejected")
+	}
+}
+
+func TestAssertText(t *testing.T) {
+	s, err := script.Parse([]byte(`{"steps":[{"action":"assert","selector":"h1","contains":"Welcome"}]}`))
+	if err != nil {
+		t.Fatalf("Failed to parse script: %v", err)
+	}
+	for _, tc := range []struct {
+		value string
+		want  string // Substring of the error, "" for success
+	}{
+		{`{"found":true,"text":"Welcome back"}`, ""},
+		{`{"found":true,"text":"Goodbye"}`, "does not contain"},
+		// An element whose text happens to read like a Go nil is still found.
+		{`{"found":true,"text":"<nil>"}`, "does not contain"},
+		{`{"found":false,"text":""}`, "no element matches h1"},
+	} {
+		err := s.Run(&fakeBrowser{value: tc.value}, nil)
+		if tc.want == "" && err != nil || tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)) {
+			t.Errorf("Eval %s: err = %v, want %q", tc.value, err, tc.want)
+		}
+	}
+}
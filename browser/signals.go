@@ -0,0 +1,55 @@
+package browser
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// HandleSignals closes b and exits the program when it receives SIGINT
+// (Ctrl+C) or SIGTERM. Without it the process dies on the spot, leaving the
+// browser running and its temporary profile on disk. Kill is given the
+// chance to clean up; a second signal exits immediately. The exit status is
+// 128 plus the signal number, as shells report it. The returned function
+// stops handling signals.
+func HandleSignals(b Browser) func() {
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	stop := make(chan struct{})
+
+	go func() {
+		var sig os.Signal
+		select {
+		case sig = <-ch:
+		case <-stop:
+			return
+		}
+		code := 1
+		if s, ok := sig.(syscall.Signal); ok {
+			code = 128 + int(s)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			b.Kill()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ch:
+		}
+		os.Exit(code)
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(stop)
+	}
+}
+
+// WithSignalHandling makes Open call HandleSignals on the new browser.
+func WithSignalHandling() Option {
+	return func(o *Options) {
+		o.HandleSignals = true
+	}
+}
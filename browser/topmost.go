@@ -0,0 +1,21 @@
+package browser
+
+import "errors"
+
+// ErrTopmostUnsupported is returned by SetAlwaysOnTop where the window
+// system offers no way to pin another process's windows.
+var ErrTopmostUnsupported = errors.New("always-on-top is not supported on this platform")
+
+// SetAlwaysOnTop keeps the browser's windows above all other windows, or
+// releases them again. It is best effort: the browser protocols have no
+// such command, so it goes through the window system directly. On Linux it
+// needs wmctrl and an X11 session.
+func (b *BaseBrowser) SetAlwaysOnTop(on bool) error {
+	b.Lock()
+	cmd := b.Cmd
+	b.Unlock()
+	if cmd == nil || cmd.Process == nil {
+		return errors.New("browser is not running")
+	}
+	return setTopmost(cmd.Process.Pid, on)
+}
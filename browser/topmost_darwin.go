@@ -0,0 +1,7 @@
+package browser
+
+// setTopmost is unavailable: macOS only lets an app raise its own windows'
+// level.
+func setTopmost(pid int, on bool) error {
+	return ErrTopmostUnsupported
+}
@@ -0,0 +1,47 @@
+//go:build !windows && !darwin
+
+package browser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// setTopmost toggles the _NET_WM_STATE_ABOVE hint on every window owned by
+// pid using wmctrl.
+func setTopmost(pid int, on bool) error {
+	wmctrl, err := exec.LookPath("wmctrl")
+	if err != nil {
+		return fmt.Errorf("%w: wmctrl not found", ErrTopmostUnsupported)
+	}
+	out, err := exec.Command(wmctrl, "-lp").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list windows: %w", err)
+	}
+
+	action := "remove,above"
+	if on {
+		action = "add,above"
+	}
+	found := 0
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		// <window id> <desktop> <pid> <host> <title>
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[2] != strconv.Itoa(pid) {
+			continue
+		}
+		if err := exec.Command(wmctrl, "-i", "-r", fields[0], "-b", action).Run(); err != nil {
+			return fmt.Errorf("failed to change window %s: %w", fields[0], err)
+		}
+		found++
+	}
+	if found == 0 {
+		return fmt.Errorf("no window found for process %d", pid)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package browser
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	user32                       = syscall.NewLazyDLL("user32.dll")
+	procEnumWindows              = user32.NewProc("EnumWindows")
+	procGetWindowThreadProcessId = user32.NewProc("GetWindowThreadProcessId")
+	procIsWindowVisible          = user32.NewProc("IsWindowVisible")
+	procSetWindowPos             = user32.NewProc("SetWindowPos")
+)
+
+const (
+	hwndTopmost   = ^uintptr(0) // HWND_TOPMOST, -1
+	hwndNoTopmost = ^uintptr(1) // HWND_NOTOPMOST, -2
+
+	swpNoSize     = 0x0001
+	swpNoMove     = 0x0002
+	swpNoActivate = 0x0010
+)
+
+// setTopmost changes the z-order of every visible top-level window owned by
+// pid.
+func setTopmost(pid int, on bool) error {
+	insertAfter := hwndNoTopmost
+	if on {
+		insertAfter = hwndTopmost
+	}
+
+	found := 0
+	cb := syscall.NewCallback(func(hwnd, _ uintptr) uintptr {
+		var owner uint32
+		procGetWindowThreadProcessId.Call(hwnd, uintptr(unsafe.Pointer(&owner)))
+		if int(owner) != pid {
+			return 1
+		}
+		if visible, _, _ := procIsWindowVisible.Call(hwnd); visible == 0 {
+			return 1
+		}
+		procSetWindowPos.Call(hwnd, insertAfter, 0, 0, 0, 0, swpNoMove|swpNoSize|swpNoActivate)
+		found++
+		return 1
+	})
+	procEnumWindows.Call(cb, 0)
+
+	if found == 0 {
+		return fmt.Errorf("no window found for process %d", pid)
+	}
+	return nil
+}
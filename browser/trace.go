@@ -0,0 +1,142 @@
+package browser
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// Redacted replaces sensitive values in protocol traces.
+const Redacted = "[REDACTED]"
+
+// Redaction configures what is masked in protocol traces, so traces can be
+// attached to bug reports without leaking credentials.
+type Redaction struct {
+	Headers      []string // Header names to mask, case-insensitive
+	Cookies      bool     // Mask cookie values
+	QueryParams  []string // URL query parameters to mask; "*" masks all
+	EvalPayloads bool     // Mask evaluated JavaScript and its results
+}
+
+// DefaultRedaction masks credentials that commonly show up in traffic.
+func DefaultRedaction() *Redaction {
+	return &Redaction{
+		Headers: []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"},
+		Cookies: true,
+	}
+}
+
+// evalMethods carry JavaScript source or values in their payloads.
+var evalMethods = map[string]bool{
+	"Runtime.evaluate":                      true,
+	"Runtime.callFunctionOn":                true,
+	"Runtime.compileScript":                 true,
+	"Page.addScriptToEvaluateOnNewDocument": true,
+	"Runtime.bindingCalled":                 true,
+	"Runtime.consoleAPICalled":              true,
+}
+
+// Apply returns a copy of payload with sensitive values masked. method is
+// the command or event the payload belongs to.
+func (r *Redaction) Apply(method string, payload json.RawMessage) json.RawMessage {
+	if r == nil || len(payload) == 0 {
+		return payload
+	}
+	var v interface{}
+	if json.Unmarshal(payload, &v) != nil {
+		return payload
+	}
+	if r.EvalPayloads && evalMethods[method] {
+		return json.RawMessage(`"` + Redacted + `"`)
+	}
+	out, err := json.Marshal(r.walk("", v))
+	if err != nil {
+		return payload
+	}
+	return out
+}
+
+func (r *Redaction) walk(key string, v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if strings.EqualFold(key, "headers") || strings.EqualFold(key, "extraHeaders") {
+			for name := range val {
+				if r.header(name) {
+					val[name] = Redacted
+				}
+			}
+		}
+		for k, child := range val {
+			if r.Cookies && k == "value" && strings.EqualFold(key, "cookie") {
+				val[k] = Redacted
+				continue
+			}
+			val[k] = r.walk(k, child)
+		}
+		return val
+	case []interface{}:
+		for i, child := range val {
+			// Cookie lists hold objects whose "value" must be masked.
+			if strings.EqualFold(key, "cookies") {
+				val[i] = r.walk("cookie", child)
+			} else {
+				val[i] = r.walk(key, child)
+			}
+		}
+		return val
+	case string:
+		if strings.Contains(val, "?") && (strings.HasSuffix(strings.ToLower(key), "url") || key == "documentURL") {
+			return r.url(val)
+		}
+		return val
+	}
+	return v
+}
+
+func (r *Redaction) header(name string) bool {
+	for _, h := range r.Headers {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redaction) url(raw string) string {
+	if len(r.QueryParams) == 0 {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	q := u.Query()
+	all := len(r.QueryParams) == 1 && r.QueryParams[0] == "*"
+	for name := range q {
+		mask := all
+		for _, p := range r.QueryParams {
+			mask = mask || p == name
+		}
+		if mask {
+			q.Set(name, Redacted)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// Trace logs a protocol message at debug level when tracing is enabled via
+// WithProtocolTrace. direction is "send", "recv" or "event".
+func (b *BaseBrowser) Trace(direction, method string, payload interface{}) {
+	if !b.TraceEnabled {
+		return
+	}
+	var raw json.RawMessage
+	switch p := payload.(type) {
+	case json.RawMessage:
+		raw = p
+	default:
+		raw, _ = json.Marshal(p)
+	}
+	b.Logger().Debug("cdp "+direction, "method", method, "payload", string(b.Redact.Apply(method, raw)))
+}
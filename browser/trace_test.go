@@ -0,0 +1,40 @@
+package browser_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+func TestRedactionApply(t *testing.T) {
+	r := browser.DefaultRedaction()
+	r.QueryParams = []string{"token"}
+
+	payload := json.RawMessage(`{
+		"request": {
+			"url": "https://example.com/api?token=secret&page=2",
+			"headers": {"Authorization": "Bearer secret", "Accept": "text/html"}
+		},
+		"cookies": [{"name": "session", "value": "secret"}]
+	}`)
+
+	out := string(r.Apply("Network.requestWillBeSent", payload))
+	if strings.Contains(out, "secret") {
+		t.Errorf("Secret leaked into redacted payload: %s", out)
+	}
+	for _, keep := range []string{"text/html", "page=2", "session"} {
+		if !strings.Contains(out, keep) {
+			t.Errorf("Redacted payload lost %q: %s", keep, out)
+		}
+	}
+}
+
+func TestRedactionEvalPayloads(t *testing.T) {
+	r := &browser.Redaction{EvalPayloads: true}
+	out := string(r.Apply("Runtime.evaluate", json.RawMessage(`{"expression":"login('pw')"}`)))
+	if strings.Contains(out, "pw") {
+		t.Errorf("Eval payload was not redacted: %s", out)
+	}
+}
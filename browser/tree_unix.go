@@ -0,0 +1,41 @@
+//go:build !windows
+
+package browser
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// processTree is the process group the browser and its helpers run in.
+type processTree struct {
+	pgid int
+}
+
+// prepareTree starts the browser in a process group of its own, so its
+// renderer, GPU and utility processes can be killed together.
+func prepareTree(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+	setDeathSignal(cmd.SysProcAttr)
+}
+
+// attachTree remembers the group of the started browser.
+func attachTree(cmd *exec.Cmd) (processTree, error) {
+	return processTree{pgid: cmd.Process.Pid}, nil
+}
+
+// kill terminates every process left in the group.
+func (t *processTree) kill() error {
+	if t.pgid <= 0 {
+		return nil
+	}
+	err := syscall.Kill(-t.pgid, syscall.SIGKILL)
+	t.pgid = 0
+	if err == syscall.ESRCH {
+		err = nil
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package browser
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                     = syscall.NewLazyDLL("kernel32.dll")
+	procCreateJobObjectW         = kernel32.NewProc("CreateJobObjectW")
+	procSetInformationJobObject  = kernel32.NewProc("SetInformationJobObject")
+	procAssignProcessToJobObject = kernel32.NewProc("AssignProcessToJobObject")
+	procTerminateJobObject       = kernel32.NewProc("TerminateJobObject")
+)
+
+const (
+	jobObjectExtendedLimitInformation = 9
+	jobObjectLimitKillOnJobClose      = 0x2000
+
+	processSetQuota  = 0x0100
+	processTerminate = 0x0001
+)
+
+// jobLimits mirrors JOBOBJECT_EXTENDED_LIMIT_INFORMATION.
+type jobLimits struct {
+	PerProcessUserTimeLimit int64
+	PerJobUserTimeLimit     int64
+	LimitFlags              uint32
+	MinimumWorkingSetSize   uintptr
+	MaximumWorkingSetSize   uintptr
+	ActiveProcessLimit      uint32
+	Affinity                uintptr
+	PriorityClass           uint32
+	SchedulingClass         uint32
+	IoCounters              [6]uint64
+	ProcessMemoryLimit      uintptr
+	JobMemoryLimit          uintptr
+	PeakProcessMemoryUsed   uintptr
+	PeakJobMemoryUsed       uintptr
+}
+
+// processTree is the Job Object the browser and its helpers run in.
+type processTree struct {
+	job syscall.Handle
+}
+
+// prepareTree has nothing to do before launch on Windows; the browser is
+// put into a job right after it started, before it spawns its helpers.
+func prepareTree(cmd *exec.Cmd) {}
+
+// attachTree puts the started browser into a new Job Object. Processes it
+// spawns inherit the job, and closing the job's last handle kills them all,
+// even if the app itself dies.
+func attachTree(cmd *exec.Cmd) (processTree, error) {
+	job, _, err := procCreateJobObjectW.Call(0, 0)
+	if job == 0 {
+		return processTree{}, fmt.Errorf("failed to create job object: %w", err)
+	}
+	t := processTree{job: syscall.Handle(job)}
+
+	limits := jobLimits{LimitFlags: jobObjectLimitKillOnJobClose}
+	if ok, _, err := procSetInformationJobObject.Call(job, jobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&limits)), unsafe.Sizeof(limits)); ok == 0 {
+		syscall.CloseHandle(t.job)
+		return processTree{}, fmt.Errorf("failed to configure job object: %w", err)
+	}
+
+	process, err := syscall.OpenProcess(processSetQuota|processTerminate, false, uint32(cmd.Process.Pid))
+	if err != nil {
+		syscall.CloseHandle(t.job)
+		return processTree{}, fmt.Errorf("failed to open browser process: %w", err)
+	}
+	defer syscall.CloseHandle(process)
+	if ok, _, err := procAssignProcessToJobObject.Call(job, uintptr(process)); ok == 0 {
+		syscall.CloseHandle(t.job)
+		return processTree{}, fmt.Errorf("failed to assign browser to job object: %w", err)
+	}
+	return t, nil
+}
+
+// kill terminates every process in the job and releases it.
+func (t *processTree) kill() error {
+	if t.job == 0 {
+		return nil
+	}
+	ok, _, err := procTerminateJobObject.Call(uintptr(t.job), 1)
+	syscall.CloseHandle(t.job)
+	t.job = 0
+	if ok == 0 {
+		return fmt.Errorf("failed to terminate job object: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package browser
+
+import (
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// ModuleVersion reports the version of this module the app was built with,
+// or "unknown" for builds without module information.
+func ModuleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if info.Main.Path == "github.com/grngxd/majorca" {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "github.com/grngxd/majorca" {
+			return dep.Version
+		}
+	}
+	return "unknown"
+}
+
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)*`)
+
+// ParseVersion extracts the dotted version number from a product string,
+// e.g. "120.0.6099.109" from "Chrome/120.0.6099.109" or "115.0" from
+// "Mozilla Firefox 115.0esr". It returns "" if there is none.
+func ParseVersion(s string) string {
+	return versionPattern.FindString(s)
+}
+
+// CompareVersions compares dotted numeric versions part by part, treating
+// missing parts as zero. It returns -1, 0 or 1.
+func CompareVersions(a, b string) int {
+	pa, pb := strings.Split(ParseVersion(a), "."), strings.Split(ParseVersion(b), ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			y, _ = strconv.Atoi(pb[i])
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// CheckVersion returns an error wrapping ErrBrowserTooOld if version is
+// older than the minimum set with WithMinVersion.
+func (o *Options) CheckVersion(version string) error {
+	if o.MinVersion == "" {
+		return nil
+	}
+	if ParseVersion(version) == "" {
+		return fmt.Errorf("%w: cannot tell the version of %q", ErrBrowserTooOld, version)
+	}
+	if CompareVersions(version, o.MinVersion) < 0 {
+		return fmt.Errorf("%w: found %s, need %s or newer", ErrBrowserTooOld, ParseVersion(version), o.MinVersion)
+	}
+	return nil
+}
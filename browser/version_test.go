@@ -0,0 +1,34 @@
+package browser_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+func TestParseVersion(t *testing.T) {
+	for in, want := range map[string]string{
+		"Chrome/120.0.6099.109":    "120.0.6099.109",
+		"Mozilla Firefox 115.0esr": "115.0",
+		"HeadlessChrome/99":        "99",
+		"unknown":                  "",
+	} {
+		if got := browser.ParseVersion(in); got != want {
+			t.Errorf("ParseVersion(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	o := browser.NewOptions(browser.WithMinVersion("115"))
+	if err := o.CheckVersion("115.0.1"); err != nil {
+		t.Errorf("Expected 115.0.1 to pass, got %v", err)
+	}
+	if err := o.CheckVersion("Chrome/114.9"); !errors.Is(err, browser.ErrBrowserTooOld) {
+		t.Errorf("Expected ErrBrowserTooOld, got %v", err)
+	}
+	if browser.CompareVersions("1.10", "1.9") != 1 {
+		t.Errorf("Expected 1.10 to be newer than 1.9")
+	}
+}
@@ -0,0 +1,243 @@
+// Package webkit drives Safari on macOS through safaridriver, giving apps a
+// native engine when Chrome isn't installed. It speaks classic WebDriver
+// over HTTP, which has no channel from the page back to Go, so Bind is not
+// supported yet.
+//
+// safaridriver must be enabled once with "safaridriver --enable" and
+// "Allow Remote Automation" in Safari's Develop menu.
+package webkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// driverPath is where macOS installs safaridriver.
+const driverPath = "/usr/bin/safaridriver"
+
+// Safari is a Safari window automated through safaridriver.
+type Safari struct {
+	browser.BaseBrowser
+	driverURL string
+	session   string
+	client    *http.Client
+}
+
+func init() {
+	browser.Register("webkit", browser.Factory{
+		New: func(opts ...browser.Option) (browser.Browser, error) {
+			return New(opts...)
+		},
+		Available: func() bool {
+			_, err := FindDriver()
+			return err == nil
+		},
+		Priority:    60,
+		Description: "Safari via safaridriver (macOS)",
+	})
+}
+
+// New starts safaridriver and opens a Safari window.
+func New(opts ...browser.Option) (*Safari, error) {
+	o := browser.NewOptions(opts...)
+
+	driver := o.ExecutablePath
+	var err error
+	if driver == "" {
+		driver, err = FindDriver()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if o.Headless {
+		return nil, fmt.Errorf("Safari cannot run headless")
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a free port: %w", err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	s := &Safari{
+		BaseBrowser: browser.BaseBrowser{
+			Pending:  make(map[string]chan interface{}),
+			Bindings: make(map[string]browser.BindingFunc),
+			Path:     driver,
+			Log:      o.Logger,
+			Timeout:  o.CommandTimeout,
+			Stop:     make(chan struct{}),
+		},
+		driverURL: "http://" + net.JoinHostPort("127.0.0.1", strconv.Itoa(port)),
+		client:    &http.Client{Timeout: o.CommandTimeout},
+	}
+
+	s.Cmd = exec.Command(driver, "--port", strconv.Itoa(port))
+	s.Cmd.Stdout = o.Stdout
+	s.Cmd.Stderr = o.Stderr
+	if err := s.Start(); err != nil {
+		return nil, err
+	}
+	if err := s.waitForDriver(10 * time.Second); err != nil {
+		s.Kill()
+		return nil, err
+	}
+
+	var session struct {
+		SessionID string `json:"sessionId"`
+	}
+	err = s.command(http.MethodPost, "/session", map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"alwaysMatch": map[string]interface{}{"browserName": "safari"},
+		},
+	}, &session)
+	if err != nil {
+		s.Kill()
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	s.session = "/session/" + session.SessionID
+
+	if o.Width > 0 && o.Height > 0 || o.HasPosition {
+		rect := map[string]interface{}{}
+		if o.Width > 0 && o.Height > 0 {
+			rect["width"], rect["height"] = o.Width, o.Height
+		}
+		if o.HasPosition {
+			rect["x"], rect["y"] = o.X, o.Y
+		}
+		if err := s.command(http.MethodPost, s.session+"/window/rect", rect, nil); err != nil {
+			s.Logger().Warn("failed to set window geometry", "error", err)
+		}
+	}
+	return s, nil
+}
+
+func (s *Safari) waitForDriver(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if err := s.command(http.MethodGet, "/status", nil, nil); err == nil {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("safaridriver did not start within %s", timeout)
+}
+
+// command performs a WebDriver request and decodes its "value" into v.
+func (s *Safari) command(method, path string, body interface{}, v interface{}) error {
+	var r *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	} else {
+		r = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, s.driverURL+path, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	s.Logger().Debug("sending message", "method", method, "path", path)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		}
+		json.Unmarshal(res.Value, &e)
+		return fmt.Errorf("%s: %s", e.Error, e.Message)
+	}
+	if v != nil {
+		if err := json.Unmarshal(res.Value, v); err != nil {
+			return fmt.Errorf("failed to unmarshal value: %w", err)
+		}
+	}
+	return nil
+}
+
+// Load navigates to url and waits for the page to load.
+func (s *Safari) Load(url string) error {
+	return s.command(http.MethodPost, s.session+"/url", map[string]interface{}{"url": url}, nil)
+}
+
+// evalScript runs the expression, awaiting promises, and reports the result
+// or the thrown error through WebDriver's async callback.
+const evalScript = `const done = arguments[arguments.length - 1];
+Promise.resolve().then(() => (0, eval)(arguments[0])).then(
+	(v) => done({type: v === null ? "null" : typeof v, value: typeof v === "object" || typeof v === "undefined" ? String(v) : v}),
+	(e) => done({error: String(e && e.message || e)}));`
+
+// Eval evaluates a JavaScript expression in the page.
+func (s *Safari) Eval(expr string) (string, string, error) {
+	var res struct {
+		Type  string      `json:"type"`
+		Value interface{} `json:"value"`
+		Error *string     `json:"error"`
+	}
+	err := s.command(http.MethodPost, s.session+"/execute/async", map[string]interface{}{
+		"script": evalScript,
+		"args":   []string{expr},
+	}, &res)
+	if err != nil {
+		return "", "", err
+	}
+	if res.Error != nil {
+		return "", "", fmt.Errorf("javascript error: %s", *res.Error)
+	}
+	if v, ok := res.Value.(string); ok {
+		return v, res.Type, nil
+	}
+	return fmt.Sprintf("%v", res.Value), res.Type, nil
+}
+
+// Bind is not supported by classic WebDriver. It returns an error wrapping
+// browser.ErrNotImplemented.
+func (s *Safari) Bind(name string, f browser.BindingFunc) error {
+	return fmt.Errorf("bindings are %w by the webkit backend", browser.ErrNotImplemented)
+}
+
+// Kill closes the Safari window and stops safaridriver.
+func (s *Safari) Kill() error {
+	if s.session != "" {
+		s.command(http.MethodDelete, s.session, nil, nil)
+		s.session = ""
+	}
+	return s.BaseBrowser.Kill()
+}
+
+// FindDriver locates safaridriver.
+func FindDriver() (string, error) {
+	if runtime.GOOS != "darwin" {
+		return "", fmt.Errorf("unsupported OS: %s", runtime.GOOS)
+	}
+	if _, err := os.Stat(driverPath); err != nil {
+		return "", fmt.Errorf("could not find safaridriver: %w", err)
+	}
+	return driverPath, nil
+}
@@ -0,0 +1,34 @@
+package webview2_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grngxd/majorca/browser/webview2"
+)
+
+func TestLatestRuntime(t *testing.T) {
+	root := t.TempDir()
+	for _, version := range []string{"99.0.1150.55", "120.0.2210.91", "120.0.2210.121", "EBWebView"} {
+		dir := filepath.Join(root, version)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "msedgewebview2.exe"), nil, 0755); err != nil {
+			t.Fatalf("Failed to create runtime: %v", err)
+		}
+	}
+	// A newer version without the executable, e.g. a half-finished update.
+	if err := os.MkdirAll(filepath.Join(root, "121.0.0.0"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	got, err := webview2.LatestRuntime(root)
+	if err != nil {
+		t.Fatalf("Failed to find runtime: %v", err)
+	}
+	if want := filepath.Join(root, "120.0.2210.121", "msedgewebview2.exe"); got != want {
+		t.Errorf("Found %s, want %s", got, want)
+	}
+}
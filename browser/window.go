@@ -0,0 +1,41 @@
+package browser
+
+import "encoding/json"
+
+// WindowState mirrors the window states understood by the DevTools
+// Browser domain.
+type WindowState string
+
+const (
+	WindowNormal     WindowState = "normal"
+	WindowMinimized  WindowState = "minimized"
+	WindowMaximized  WindowState = "maximized"
+	WindowFullscreen WindowState = "fullscreen"
+)
+
+// Bounds describes the position, size and state of the app window. A Bounds
+// without a WindowState asks for a position and size: Left and Top are always
+// applied, so 0 moves the window to the screen edge, while a zero Width or
+// Height keeps the current size.
+type Bounds struct {
+	Left        int         `json:"left,omitempty"`
+	Top         int         `json:"top,omitempty"`
+	Width       int         `json:"width,omitempty"`
+	Height      int         `json:"height,omitempty"`
+	WindowState WindowState `json:"windowState,omitempty"`
+}
+
+// MarshalJSON keeps left and top when WindowState is empty, which omitempty
+// would drop for windows placed at 0.
+func (b Bounds) MarshalJSON() ([]byte, error) {
+	type plain Bounds
+	if b.WindowState != "" {
+		return json.Marshal(plain(b))
+	}
+	return json.Marshal(struct {
+		Left   int `json:"left"`
+		Top    int `json:"top"`
+		Width  int `json:"width,omitempty"`
+		Height int `json:"height,omitempty"`
+	}{b.Left, b.Top, b.Width, b.Height})
+}
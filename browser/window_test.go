@@ -0,0 +1,31 @@
+package browser_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+func TestBoundsJSON(t *testing.T) {
+	tests := []struct {
+		b    browser.Bounds
+		want string
+	}{
+		{browser.Bounds{Left: 0, Top: 0, Width: 800}, `{"left":0,"top":0,"width":800}`},
+		{browser.Bounds{Left: 10, Top: 20}, `{"left":10,"top":20}`},
+		{browser.Bounds{WindowState: browser.WindowMaximized}, `{"windowState":"maximized"}`},
+		{browser.Bounds{WindowState: browser.WindowNormal, Width: 800}, `{"width":800,"windowState":"normal"}`},
+	}
+	for _, tt := range tests {
+		got, err := json.Marshal(tt.b)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("Marshal(%+v) = %s, %v; want %s", tt.b, got, err, tt.want)
+		}
+	}
+
+	var b browser.Bounds
+	if err := json.Unmarshal([]byte(`{"left":0,"top":5,"width":800,"height":600,"windowState":"normal"}`), &b); err != nil || b.Top != 5 || b.WindowState != browser.WindowNormal {
+		t.Errorf("Unmarshal = %+v, %v", b, err)
+	}
+}
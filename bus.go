@@ -0,0 +1,236 @@
+package majorca
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// busBinding is the single binding the page runtime talks to the bus with.
+const busBinding = "__majorcaBus"
+
+// busScript installs window.majorca in every window:
+//
+//	majorca.windowId                 id of this window
+//	majorca.on(event, fn)            subscribe, fn(payload, fromWindowId)
+//	majorca.broadcast(event, data)   send to every window and Go
+//	majorca.post(windowId, data)     send a "message" event to one window
+//	majorca.state / setState(k, v)   state shared by all windows
+//	majorca.diagnostics()            show the diagnostics page
+//	majorca.dumpDiagnostics()        save a diagnostic bundle, resolves to its path
+//	majorca.events(query)            recent app events, see App.Events
+//
+// Clicking a link to DiagnosticsURL also shows the diagnostics page.
+// Every delivered event is also dispatched as a "majorca:<event>"
+// CustomEvent on window with detail {from, data}.
+const busScript = `(() => {
+	const m = window.majorca = window.majorca || {};
+	const handlers = new Map();
+	const send = (msg) => window.%s(msg);
+	m.windowId = %d;
+	m.state = {};
+	m.on = (event, fn) => {
+		if (!handlers.has(event)) handlers.set(event, new Set());
+		handlers.get(event).add(fn);
+		return () => handlers.get(event).delete(fn);
+	};
+	m.dispatch = (event, data, from) => {
+		if (event === "state") Object.assign(m.state, data);
+		for (const fn of handlers.get(event) || []) fn(data, from);
+		window.dispatchEvent(new CustomEvent("majorca:" + event, {detail: {from, data}}));
+	};
+	m.post = (to, data) => send({op: "post", to, data});
+	m.broadcast = (event, data) => send({op: "broadcast", event, data});
+	m.setState = (key, value) => send({op: "state", key, data: value});
+	m.diagnostics = () => send({op: "diagnostics"});
+	m.dumpDiagnostics = () => send({op: "dumpDiagnostics"});
+	m.events = (query) => send({op: "events", data: query || {}});
+	document.addEventListener("click", (e) => {
+		const a = e.target.closest && e.target.closest("a[href]");
+		if (a && a.getAttribute("href") === %q) {
+			e.preventDefault();
+			m.diagnostics();
+		}
+	}, true);
+	send({op: "getState"}).then((s) => m.dispatch("state", s, 0));
+})()`
+
+type busHandler func(from int, payload json.RawMessage)
+
+// bus holds the Go side of the inter-window message bus.
+type bus struct {
+	mu       sync.Mutex
+	handlers map[string][]busHandler
+	state    map[string]json.RawMessage
+}
+
+type busMessage struct {
+	Op    string          `json:"op"`
+	To    int             `json:"to"`
+	Event string          `json:"event"`
+	Key   string          `json:"key"`
+	Data  json.RawMessage `json:"data"`
+}
+
+func (a *App) installBus(w *Window) error {
+	err := w.Bind(busBinding, func(args []json.RawMessage) (interface{}, error) {
+		var msg busMessage
+		if len(args) != 1 || json.Unmarshal(args[0], &msg) != nil {
+			return nil, fmt.Errorf("malformed bus message")
+		}
+		return a.handleBus(w.ID, msg)
+	})
+	if err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf(busScript, busBinding, w.ID, DiagnosticsURL)
+	if _, err := w.Send("Page.addScriptToEvaluateOnNewDocument", map[string]interface{}{"source": script}); err != nil {
+		return err
+	}
+	_, _, err = w.Eval(script)
+	return err
+}
+
+func (a *App) handleBus(from int, msg busMessage) (interface{}, error) {
+	switch msg.Op {
+	case "post":
+		target, ok := a.Window(msg.To)
+		if !ok {
+			return nil, fmt.Errorf("no window with id %d", msg.To)
+		}
+		return nil, target.deliver("message", msg.Data, from)
+	case "broadcast":
+		return nil, a.broadcast(msg.Event, msg.Data, from)
+	case "state":
+		return nil, a.setState(msg.Key, msg.Data, from)
+	case "focus":
+		return nil, a.focusWindow(msg.To)
+	case "diagnostics":
+		// Navigating replaces the page waiting for this call's result.
+		w, ok := a.Window(from)
+		if !ok {
+			return nil, fmt.Errorf("no window with id %d", from)
+		}
+		go func() {
+			if err := w.ShowDiagnostics(); err != nil {
+				w.Logger().Error("failed to show diagnostics", "error", err)
+			}
+		}()
+		return nil, nil
+	case "dumpDiagnostics":
+		return a.DumpDiagnostics("")
+	case "events":
+		var q EventQuery
+		if len(msg.Data) > 0 {
+			if err := json.Unmarshal(msg.Data, &q); err != nil {
+				return nil, fmt.Errorf("malformed event query: %w", err)
+			}
+		}
+		return a.Events(q), nil
+	case "getState":
+		a.bus.mu.Lock()
+		defer a.bus.mu.Unlock()
+		state := make(map[string]json.RawMessage, len(a.bus.state))
+		for k, v := range a.bus.state {
+			state[k] = v
+		}
+		return state, nil
+	}
+	return nil, fmt.Errorf("unknown bus operation %q", msg.Op)
+}
+
+// On subscribes a Go handler to an event broadcast by any window or by Go.
+// from is the sending window id, or 0 for Go.
+func (a *App) On(event string, handler func(from int, payload json.RawMessage)) {
+	a.bus.mu.Lock()
+	defer a.bus.mu.Unlock()
+	if a.bus.handlers == nil {
+		a.bus.handlers = make(map[string][]busHandler)
+	}
+	a.bus.handlers[event] = append(a.bus.handlers[event], handler)
+}
+
+// Broadcast sends event to every open window and every Go handler.
+func (a *App) Broadcast(event string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return a.broadcast(event, data, 0)
+}
+
+func (a *App) broadcast(event string, data json.RawMessage, from int) error {
+	a.bus.mu.Lock()
+	handlers := append([]busHandler(nil), a.bus.handlers[event]...)
+	a.bus.mu.Unlock()
+	for _, h := range handlers {
+		h(from, data)
+	}
+
+	var firstErr error
+	for _, w := range a.Windows() {
+		if err := w.deliver(event, data, from); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// SetState stores a shared value and pushes it to every window, where it
+// shows up in majorca.state and triggers "state" subscribers.
+func (a *App) SetState(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	return a.setState(key, data, 0)
+}
+
+func (a *App) setState(key string, data json.RawMessage, from int) error {
+	if key == "" {
+		return fmt.Errorf("state key must not be empty")
+	}
+	a.bus.mu.Lock()
+	if a.bus.state == nil {
+		a.bus.state = make(map[string]json.RawMessage)
+	}
+	a.bus.state[key] = data
+	a.bus.mu.Unlock()
+
+	change, _ := json.Marshal(map[string]json.RawMessage{key: data})
+	return a.broadcast("state", change, from)
+}
+
+// State returns a shared value set with SetState or majorca.setState.
+func (a *App) State(key string) (json.RawMessage, bool) {
+	a.bus.mu.Lock()
+	defer a.bus.mu.Unlock()
+	v, ok := a.bus.state[key]
+	return v, ok
+}
+
+// EmitEvent sends event to this window only. It is not the embedded
+// Chrome's Emit, which queues a protocol event for Go subscribers.
+func (w *Window) EmitEvent(event string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return w.deliver(event, data, 0)
+}
+
+// Post delivers data to the page as a "message" event. from is the sending
+// window id, or 0 for Go.
+func (w *Window) Post(from int, data json.RawMessage) error {
+	return w.deliver("message", data, from)
+}
+
+func (w *Window) deliver(event string, data json.RawMessage, from int) error {
+	if data == nil {
+		data = json.RawMessage("null")
+	}
+	ev, _ := json.Marshal(event)
+	_, _, err := w.Eval(fmt.Sprintf(`window.majorca && window.majorca.dispatch(%s, %s, %d)`, ev, data, from))
+	return err
+}
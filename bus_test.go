@@ -0,0 +1,96 @@
+package majorca_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/grngxd/majorca"
+)
+
+func TestBusRouting(t *testing.T) {
+	p := newFakePages()
+	a, _ := newFakeApp(t, p)
+	w1, err := a.NewWindow(majorca.WindowOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w2, err := a.NewWindow(majorca.WindowOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Targets of the fake server: the main page and the first new one.
+	t1, t2 := "main", "target-1"
+	p.takeDispatches(t1)
+	p.takeDispatches(t2)
+
+	var got []string
+	a.On("news", func(from int, payload json.RawMessage) {
+		got = append(got, fmt.Sprintf("%d %s", from, payload))
+	})
+
+	// post reaches only its target.
+	if _, err := majorca.HandleBus(a, w1.ID, fmt.Sprintf(`{"op":"post","to":%d,"data":{"x":1}}`, w2.ID)); err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf(`"message", {"x":1}, %d`, w1.ID)
+	if d := p.takeDispatches(t2); len(d) != 1 || d[0] != want {
+		t.Errorf("post delivered %q to the target, want [%s]", d, want)
+	}
+	if d := p.takeDispatches(t1); len(d) != 0 {
+		t.Errorf("post delivered %q to the sender", d)
+	}
+	if _, err := majorca.HandleBus(a, w1.ID, `{"op":"post","to":99}`); err == nil {
+		t.Error("post to a missing window succeeded")
+	}
+
+	// broadcast reaches every window and Go.
+	if _, err := majorca.HandleBus(a, w2.ID, `{"op":"broadcast","event":"news","data":"hi"}`); err != nil {
+		t.Fatal(err)
+	}
+	want = fmt.Sprintf(`"news", "hi", %d`, w2.ID)
+	for _, target := range []string{t1, t2} {
+		if d := p.takeDispatches(target); len(d) != 1 || d[0] != want {
+			t.Errorf("broadcast delivered %q to %s, want [%s]", d, target, want)
+		}
+	}
+	if len(got) != 1 || got[0] != fmt.Sprintf(`%d "hi"`, w2.ID) {
+		t.Errorf("Go handler got %q", got)
+	}
+
+	// state is stored, pushed to every window and returned by getState.
+	if _, err := majorca.HandleBus(a, w1.ID, `{"op":"state","key":"theme","data":"dark"}`); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := a.State("theme"); !ok || string(v) != `"dark"` {
+		t.Errorf("State(theme) = %s, %v", v, ok)
+	}
+	want = fmt.Sprintf(`"state", {"theme":"dark"}, %d`, w1.ID)
+	for _, target := range []string{t1, t2} {
+		if d := p.takeDispatches(target); len(d) != 1 || d[0] != want {
+			t.Errorf("state delivered %q to %s, want [%s]", d, target, want)
+		}
+	}
+	state, err := majorca.HandleBus(a, w2.ID, `{"op":"getState"}`)
+	if b, _ := json.Marshal(state); err != nil || string(b) != `{"theme":"dark"}` {
+		t.Errorf("getState = %s, %v", b, err)
+	}
+	if _, err := majorca.HandleBus(a, w1.ID, `{"op":"state","data":1}`); err == nil {
+		t.Error("state without a key succeeded")
+	}
+
+	// EmitEvent reaches only its window.
+	if err := w2.EmitEvent("ping", 1); err != nil {
+		t.Fatal(err)
+	}
+	if d := p.takeDispatches(t2); len(d) != 1 || d[0] != `"ping", 1, 0` {
+		t.Errorf("EmitEvent delivered %q", d)
+	}
+	if d := p.takeDispatches(t1); len(d) != 0 {
+		t.Errorf("EmitEvent delivered %q to another window", d)
+	}
+
+	if _, err := majorca.HandleBus(a, w1.ID, `{"op":"bogus"}`); err == nil {
+		t.Error("unknown operation succeeded")
+	}
+}
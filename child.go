@@ -0,0 +1,102 @@
+package majorca
+
+import (
+	"fmt"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// Default size of child windows when WindowOptions leaves it open.
+const (
+	defaultChildWidth  = 480
+	defaultChildHeight = 320
+)
+
+// modalScript blocks input to the parent page while a modal child is open.
+// Clicking the overlay brings the child back to the front.
+const modalScript = `(() => {
+	if (document.getElementById("__majorca_modal")) return;
+	const overlay = document.createElement("div");
+	overlay.id = "__majorca_modal";
+	overlay.style.cssText = "position:fixed;inset:0;z-index:2147483647;background:rgba(0,0,0,.15)";
+	overlay.addEventListener("mousedown", (e) => {
+		e.preventDefault();
+		window.majorca && window.%s({op: "focus", to: %d});
+	});
+	document.documentElement.appendChild(overlay);
+	document.body && (document.body.inert = true);
+})()`
+
+const unmodalScript = `(() => {
+	const overlay = document.getElementById("__majorca_modal");
+	overlay && overlay.remove();
+	document.body && (document.body.inert = false);
+})()`
+
+// NewChildWindow opens a window centred on parent. A modal child blocks
+// input to its parent until it is closed. Child windows are closed together
+// with their parent.
+func (a *App) NewChildWindow(parent *Window, opts WindowOptions, modal bool) (*Window, error) {
+	pb, err := parent.GetBounds()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Width == 0 {
+		opts.Width = defaultChildWidth
+	}
+	if opts.Height == 0 {
+		opts.Height = defaultChildHeight
+	}
+	if opts.Left == 0 && opts.Top == 0 {
+		opts.Left = pb.Left + (pb.Width-opts.Width)/2
+		opts.Top = pb.Top + (pb.Height-opts.Height)/2
+	}
+
+	child, err := a.NewWindow(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if modal {
+		if _, _, err := parent.Eval(fmt.Sprintf(modalScript, busBinding, child.ID)); err != nil {
+			child.Kill()
+			return nil, err
+		}
+	}
+
+	go func() {
+		select {
+		case <-child.Done():
+			if modal {
+				parent.Eval(unmodalScript)
+			}
+		case <-parent.Done():
+			child.Kill()
+		}
+	}()
+
+	return child, nil
+}
+
+// Focus brings the window to the front.
+func (w *Window) Focus() error {
+	_, err := w.Send("Page.bringToFront", nil)
+	return err
+}
+
+// focusWindow handles the bus "focus" operation used by modal overlays.
+func (a *App) focusWindow(id int) error {
+	w, ok := a.Window(id)
+	if !ok {
+		return fmt.Errorf("no window with id %d", id)
+	}
+	if err := w.Focus(); err != nil {
+		return err
+	}
+	// Un-minimize the child if the user minimized it.
+	b, err := w.GetBounds()
+	if err == nil && b.WindowState == browser.WindowMinimized {
+		return w.Restore()
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+	"github.com/grngxd/majorca/browser/chrome"
+)
+
+// parseURLFlags parses "<url> [flags]" as well as "[flags] <url>", since the
+// flag package stops at the first positional argument.
+func parseURLFlags(fs *flag.FlagSet, args []string) (string, error) {
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return "", fmt.Errorf("missing url")
+	}
+	url := fs.Arg(0)
+	fs.Parse(fs.Args()[1:])
+	if fs.NArg() != 0 {
+		return "", fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	return url, nil
+}
+
+// openHeadless launches a headless Chrome, loads url and gives the page time
+// to render.
+func openHeadless(url string, width, height int, wait time.Duration) (*chrome.Chrome, error) {
+	c, err := chrome.New(browser.WithHeadless(), browser.WithWindowSize(width, height))
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Load(url); err != nil {
+		c.Kill()
+		return nil, err
+	}
+	time.Sleep(wait)
+	return c, nil
+}
+
+func runShot(args []string) error {
+	fs := flag.NewFlagSet("shot", flag.ExitOnError)
+	out := fs.String("o", "out.png", "output file")
+	format := fs.String("format", "png", "image format: png, jpeg or webp")
+	quality := fs.Int("quality", 90, "jpeg/webp quality (0-100)")
+	width := fs.Int("width", 1280, "viewport width")
+	height := fs.Int("height", 800, "viewport height")
+	wait := fs.Duration("wait", time.Second, "time to let the page render")
+	url, err := parseURLFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	c, err := openHeadless(url, *width, *height, *wait)
+	if err != nil {
+		return err
+	}
+	defer c.Kill()
+
+	img, err := c.Screenshot(*format, *quality)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(*out, img, 0644)
+}
+
+func runPDF(args []string) error {
+	fs := flag.NewFlagSet("pdf", flag.ExitOnError)
+	out := fs.String("o", "out.pdf", "output file")
+	landscape := fs.Bool("landscape", false, "landscape orientation")
+	background := fs.Bool("background", true, "print background graphics")
+	wait := fs.Duration("wait", time.Second, "time to let the page render")
+	url, err := parseURLFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	c, err := openHeadless(url, 1280, 800, *wait)
+	if err != nil {
+		return err
+	}
+	defer c.Kill()
+
+	pdf, err := c.PrintToPDF(chrome.PDFOptions{
+		Landscape:       *landscape,
+		PrintBackground: *background,
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(*out, pdf, 0644)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/grngxd/majorca"
+)
+
+func runCtl(args []string) error {
+	fs := flag.NewFlagSet("ctl", flag.ExitOnError)
+	socket := fs.String("socket", "", "control socket (or named pipe on Windows) of the app (required)")
+	window := fs.Int("window", 0, "target window id; 0 is the first window")
+	out := fs.String("o", "", "screenshot: output file")
+	format := fs.String("format", "png", "screenshot: png, jpeg or webp")
+	timeout := fs.Duration("timeout", 30*time.Second, "how long to wait for the app")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, `usage: majorca ctl -socket <path> <command> [url]
+
+commands: windows, navigate <url>, screenshot -o <file>, diagnostics, dump, quit`)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *socket == "" || fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	req := majorca.ControlRequest{ID: 1, Command: fs.Arg(0), Window: *window, Format: *format}
+	if req.Command == "navigate" {
+		req.URL = fs.Arg(1)
+	}
+	if req.Command == "screenshot" && *out == "" {
+		return fmt.Errorf("screenshot needs -o")
+	}
+
+	resp, err := majorca.SendControl(*socket, req, *timeout)
+	if err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("%s", resp.Error)
+	}
+
+	if req.Command == "screenshot" {
+		var encoded string
+		if err := json.Unmarshal(resp.Result, &encoded); err != nil {
+			return fmt.Errorf("malformed screenshot: %w", err)
+		}
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("malformed screenshot: %w", err)
+		}
+		return os.WriteFile(*out, data, 0644)
+	}
+	if len(resp.Result) > 0 {
+		var pretty interface{}
+		json.Unmarshal(resp.Result, &pretty)
+		b, _ := json.MarshalIndent(pretty, "", "  ")
+		fmt.Println(string(b))
+	}
+	return nil
+}
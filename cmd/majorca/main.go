@@ -0,0 +1,55 @@
+// Command majorca is a small toolbox for driving majorca browsers from the
+// command line.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: majorca <command> [flags]
+
+commands:
+  repl    launch or attach to a browser and drive it interactively
+  run     execute a JSON automation script
+  shot    render a url to an image: majorca shot <url> -o out.png
+  pdf     render a url to a PDF: majorca pdf <url> -o out.pdf
+  package generate MSIX, .desktop and Info.plist manifests and icons
+  ctl     send a command to a running app's control socket`)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "repl":
+		err = runRepl(os.Args[2:])
+	case "run":
+		err = runScript(os.Args[2:])
+	case "shot":
+		err = runShot(os.Args[2:])
+	case "pdf":
+		err = runPDF(os.Args[2:])
+	case "package":
+		err = runPackage(os.Args[2:])
+	case "ctl":
+		err = runCtl(os.Args[2:])
+	case "help", "-h", "--help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "majorca %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
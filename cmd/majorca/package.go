@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/grngxd/majorca/packaging"
+)
+
+func runPackage(args []string) error {
+	fs := flag.NewFlagSet("package", flag.ExitOnError)
+	var app packaging.App
+	fs.StringVar(&app.Name, "name", "", "human-readable app name")
+	fs.StringVar(&app.ID, "id", "", "reverse-DNS app id, e.g. com.example.app")
+	fs.StringVar(&app.Version, "version", "1.0.0", "numeric app version")
+	fs.StringVar(&app.Publisher, "publisher", "", "MSIX publisher, e.g. CN=Example Inc")
+	fs.StringVar(&app.Description, "description", "", "short description")
+	fs.StringVar(&app.Executable, "exe", "", "app binary name inside the package")
+	fs.StringVar(&app.Icon, "icon", "", "square PNG to generate icons from")
+	fs.StringVar(&app.Scheme, "scheme", "", "custom URL scheme to register")
+	fs.BoolVar(&app.SingleInstance, "single-instance", false, "ask the OS to keep one instance")
+	categories := fs.String("categories", "", "comma-separated freedesktop.org categories")
+	out := fs.String("o", "dist", "output directory")
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	if *categories != "" {
+		app.Categories = strings.Split(*categories, ",")
+	}
+
+	if err := packaging.Write(&app, *out); err != nil {
+		return err
+	}
+	fmt.Println("wrote", *out)
+	return nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grngxd/majorca/browser"
+	"github.com/grngxd/majorca/browser/chrome"
+	"github.com/grngxd/majorca/browser/firefox"
+)
+
+const replHelp = `commands:
+  load <url>               navigate to url
+  eval <expr>              evaluate JavaScript in the page
+  <Domain.method> [json]   send a raw DevTools command (Chrome only)
+  history                  list previous commands
+  !<n>                     re-run command n from history
+  help                     show this help
+  exit                     kill the browser (or detach) and quit`
+
+// cdpMethod matches raw protocol calls such as "Page.reload".
+var cdpMethod = regexp.MustCompile(`^[A-Z][A-Za-z]*\.[a-z][A-Za-z]*$`)
+
+func runRepl(args []string) error {
+	fs := flag.NewFlagSet("repl", flag.ExitOnError)
+	engine := fs.String("browser", "chrome", "browser backend: chrome or firefox")
+	headless := fs.Bool("headless", false, "run without a window")
+	verbose := fs.Bool("v", false, "log protocol traffic and browser output to stderr")
+	attach := fs.String("attach", "", "attach to a running Chrome at this DevTools endpoint (ws://... or http://host:port)")
+	fs.Parse(args)
+
+	var opts []browser.Option
+	if *headless {
+		opts = append(opts, browser.WithHeadless())
+	}
+	if *verbose {
+		opts = append(opts,
+			browser.WithLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+			browser.WithBrowserOutput(os.Stderr, os.Stderr),
+		)
+	}
+
+	var b browser.Browser
+	var err error
+	switch {
+	case *attach != "" && *engine != "chrome":
+		return fmt.Errorf("-attach is only supported for chrome")
+	case *attach != "":
+		b, err = chrome.Attach(*attach, opts...)
+	case *engine == "chrome":
+		b, err = chrome.New(opts...)
+	case *engine == "firefox":
+		b, err = firefox.New(opts...)
+	default:
+		return fmt.Errorf("unknown browser: %s", *engine)
+	}
+	if err != nil {
+		return err
+	}
+	defer b.Kill()
+
+	r := &repl{b: b, out: os.Stdout}
+	r.loadHistory()
+	defer r.saveHistory()
+
+	fmt.Fprintln(r.out, "majorca repl, type help for commands")
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for {
+		fmt.Fprint(r.out, "> ")
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+		if !r.exec(strings.TrimSpace(scanner.Text())) {
+			return nil
+		}
+	}
+}
+
+type repl struct {
+	b       browser.Browser
+	out     io.Writer
+	history []string
+}
+
+// exec runs a single line and reports whether the loop should continue.
+func (r *repl) exec(line string) bool {
+	if line == "" {
+		return true
+	}
+
+	if strings.HasPrefix(line, "!") {
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 1 || n > len(r.history) {
+			fmt.Fprintf(r.out, "no such history entry: %s\n", line[1:])
+			return true
+		}
+		line = r.history[n-1]
+		fmt.Fprintln(r.out, line)
+	}
+
+	cmd, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+
+	switch {
+	case cmd == "exit" || cmd == "quit":
+		return false
+	case cmd == "help":
+		fmt.Fprintln(r.out, replHelp)
+		return true
+	case cmd == "history":
+		for i, h := range r.history {
+			fmt.Fprintf(r.out, "%4d  %s\n", i+1, h)
+		}
+		return true
+	}
+
+	r.history = append(r.history, line)
+
+	switch {
+	case cmd == "load":
+		if err := r.b.Load(rest); err != nil {
+			fmt.Fprintf(r.out, "error: %v\n", err)
+		}
+	case cmd == "eval":
+		value, typ, err := r.b.Eval(rest)
+		if err != nil {
+			fmt.Fprintf(r.out, "error: %v\n", err)
+			return true
+		}
+		fmt.Fprintf(r.out, "(%s) %s\n", typ, pretty([]byte(value)))
+	case cdpMethod.MatchString(cmd):
+		s, ok := r.b.(browser.Sender)
+		if !ok {
+			fmt.Fprintln(r.out, "error: this browser does not accept raw protocol commands")
+			return true
+		}
+		var params interface{}
+		if rest != "" {
+			params = json.RawMessage(rest)
+			if !json.Valid([]byte(rest)) {
+				fmt.Fprintln(r.out, "error: params are not valid JSON")
+				return true
+			}
+		}
+		res, err := s.Send(cmd, params)
+		if err != nil {
+			fmt.Fprintf(r.out, "error: %v\n", err)
+			return true
+		}
+		fmt.Fprintln(r.out, pretty(res))
+	default:
+		fmt.Fprintf(r.out, "unknown command: %s (type help)\n", cmd)
+	}
+	return true
+}
+
+// pretty indents JSON values and returns anything else unchanged.
+func pretty(data []byte) string {
+	var buf bytes.Buffer
+	if json.Indent(&buf, data, "", "  ") != nil {
+		return string(data)
+	}
+	return buf.String()
+}
+
+func historyPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "majorca", "repl_history")
+}
+
+func (r *repl) loadHistory() {
+	data, err := os.ReadFile(historyPath())
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			r.history = append(r.history, line)
+		}
+	}
+}
+
+func (r *repl) saveHistory() {
+	p := historyPath()
+	if p == "" {
+		return
+	}
+	if len(r.history) > 500 {
+		r.history = r.history[len(r.history)-500:]
+	}
+	os.MkdirAll(filepath.Dir(p), 0755)
+	os.WriteFile(p, []byte(strings.Join(r.history, "\n")+"\n"), 0644)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/grngxd/majorca/browser"
+	"github.com/grngxd/majorca/browser/chrome"
+	"github.com/grngxd/majorca/browser/script"
+)
+
+func runScript(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	headless := fs.Bool("headless", false, "run without a window")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: majorca run [-headless] <script.json>")
+	}
+
+	s, err := script.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var opts []browser.Option
+	if *headless {
+		opts = append(opts, browser.WithHeadless())
+	}
+	b, err := chrome.New(opts...)
+	if err != nil {
+		return err
+	}
+	defer b.Kill()
+
+	if err := s.Run(b, os.Stdout); err != nil {
+		return err
+	}
+	fmt.Println("ok")
+	return nil
+}
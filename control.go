@@ -0,0 +1,209 @@
+package majorca
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ControlRequest is a command for the control socket, sent as one line of
+// JSON. Commands:
+//
+//	windows      list open windows: [{"id": 1, "url": "…"}]
+//	navigate     load URL in Window
+//	screenshot   capture Window as Format (png, jpeg or webp), base64 encoded
+//	diagnostics  return the Diagnostics of the app
+//	dump         write a diagnostic bundle and return its path
+//	quit         close the app
+type ControlRequest struct {
+	ID      int    `json:"id,omitempty"` // Echoed in the response
+	Command string `json:"command"`
+	Window  int    `json:"window,omitempty"` // Zero means the first window
+	URL     string `json:"url,omitempty"`
+	Format  string `json:"format,omitempty"`
+}
+
+// ControlResponse answers a ControlRequest, as one line of JSON.
+type ControlResponse struct {
+	ID     int             `json:"id,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// ServeControl listens on a local endpoint at path and executes
+// ControlRequests from companion tools, e.g. to manage a fleet of kiosks
+// with "majorca ctl". On Unix path is a socket file; on Windows it names a
+// named pipe, either in full (\\.\pipe\kiosk) or as any other string,
+// which is placed under \\.\pipe\. Either way only the current user can
+// connect. A stale socket left by a crashed app is replaced, but ServeControl
+// fails if another app serves path or a different kind of file is there.
+// It is served until the returned function is called or the app exits.
+// Clients connect with DialControl.
+func (a *App) ServeControl(path string) (func(), error) {
+	l, err := listenControl(path)
+	if err != nil {
+		return nil, err
+	}
+	a.main.Logger().Info("control socket listening", "path", path)
+
+	stop := make(chan struct{})
+	go func() {
+		select {
+		case <-stop:
+		case <-a.Done():
+		}
+		l.Close()
+	}()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go a.serveControlConn(conn)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(stop) })
+	}, nil
+}
+
+// SendControl sends req to the app serving the control endpoint at path and
+// returns its response. It gives up after timeout.
+func SendControl(path string, req ControlRequest, timeout time.Duration) (*ControlResponse, error) {
+	deadline := time.Now().Add(timeout)
+	conn, err := DialControl(path, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	type result struct {
+		resp *ControlResponse
+		err  error
+	}
+	// Named pipes have no deadlines, so the exchange runs aside and is
+	// abandoned by closing the connection.
+	done := make(chan result, 1)
+	go func() {
+		if err := json.NewEncoder(conn).Encode(req); err != nil {
+			done <- result{err: err}
+			return
+		}
+		line, err := bufio.NewReader(conn).ReadBytes('\n')
+		if err != nil {
+			done <- result{err: fmt.Errorf("no response: %w", err)}
+			return
+		}
+		var resp ControlResponse
+		if err := json.Unmarshal(line, &resp); err != nil {
+			done <- result{err: fmt.Errorf("malformed response: %w", err)}
+			return
+		}
+		done <- result{resp: &resp}
+	}()
+	select {
+	case r := <-done:
+		return r.resp, r.err
+	case <-time.After(time.Until(deadline)):
+		return nil, errors.New("no response: timed out")
+	}
+}
+
+func (a *App) serveControlConn(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		var req ControlRequest
+		var resp ControlResponse
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp.Error = fmt.Sprintf("malformed request: %v", err)
+		} else {
+			resp.ID = req.ID
+			result, err := a.control(req)
+			if err == nil && result != nil {
+				resp.Result, err = json.Marshal(result)
+			}
+			if err != nil {
+				resp.Error = err.Error()
+			}
+		}
+		if err := enc.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+// control executes a single request.
+func (a *App) control(req ControlRequest) (interface{}, error) {
+	switch req.Command {
+	case "windows":
+		type info struct {
+			ID  int    `json:"id"`
+			URL string `json:"url"`
+		}
+		windows := a.Windows()
+		sort.Slice(windows, func(i, j int) bool { return windows[i].ID < windows[j].ID })
+		list := make([]info, 0, len(windows))
+		for _, w := range windows {
+			url, _, _ := w.Eval("location.href")
+			list = append(list, info{w.ID, url})
+		}
+		return list, nil
+	case "diagnostics":
+		return a.Diagnostics(), nil
+	case "dump":
+		return a.DumpDiagnostics("")
+	case "quit":
+		go a.Quit()
+		return nil, nil
+	}
+
+	w, err := a.controlWindow(req.Window)
+	if err != nil {
+		return nil, err
+	}
+	switch req.Command {
+	case "navigate":
+		if req.URL == "" {
+			return nil, errors.New("navigate needs a url")
+		}
+		return nil, w.Load(req.URL)
+	case "screenshot":
+		format := req.Format
+		if format == "" {
+			format = "png"
+		}
+		return w.Screenshot(format, 90)
+	}
+	return nil, fmt.Errorf("unknown command %q", req.Command)
+}
+
+// controlWindow returns the window with id, or the first one for zero.
+func (a *App) controlWindow(id int) (*Window, error) {
+	if id != 0 {
+		if w, ok := a.Window(id); ok {
+			return w, nil
+		}
+		return nil, fmt.Errorf("no window with id %d", id)
+	}
+	var first *Window
+	for _, w := range a.Windows() {
+		if first == nil || w.ID < first.ID {
+			first = w
+		}
+	}
+	if first == nil {
+		return nil, errors.New("no open windows")
+	}
+	return first, nil
+}
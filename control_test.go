@@ -0,0 +1,127 @@
+package majorca_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grngxd/majorca"
+	"github.com/grngxd/majorca/internal/cdptest"
+)
+
+func newControlApp(t *testing.T) *majorca.App {
+	t.Helper()
+	a, err := majorca.AttachApp(cdptest.New(t, nil).URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.Quit() })
+	return a
+}
+
+// controlPath returns a control path unique to the test; on Windows it is
+// mapped to a named pipe.
+func controlPath(t *testing.T) string {
+	if runtime.GOOS == "windows" {
+		return `\\.\pipe\majorca-test-` + strings.ReplaceAll(t.Name(), "/", "-")
+	}
+	return filepath.Join(t.TempDir(), "ctl.sock")
+}
+
+func TestServeControl(t *testing.T) {
+	a := newControlApp(t)
+	path := controlPath(t)
+	stop, err := a.ServeControl(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	resp, err := majorca.SendControl(path, majorca.ControlRequest{ID: 7, Command: "windows"}, 3*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ID != 7 || resp.Error != "" || string(resp.Result) != "[]" {
+		t.Errorf("windows = %+v, want id 7 and an empty list", resp)
+	}
+
+	resp, err = majorca.SendControl(path, majorca.ControlRequest{Command: "navigate", URL: "https://example.com"}, 3*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error != "no open windows" {
+		t.Errorf("navigate without windows: error = %q", resp.Error)
+	}
+
+	conn, err := majorca.DialControl(path, 3*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, "{not json\n")
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || !strings.Contains(line, "malformed request") {
+		t.Errorf("malformed request: response %q, %v", line, err)
+	}
+
+	if _, err := a.ServeControl(path); err == nil {
+		t.Error("ServeControl succeeded on a path another listener serves")
+	}
+}
+
+func TestServeControlSocketFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("control uses named pipes on Windows")
+	}
+	a := newControlApp(t)
+	dir := t.TempDir()
+
+	// Regular files are never removed.
+	file := filepath.Join(dir, "file")
+	os.WriteFile(file, []byte("keep"), 0644)
+	if _, err := a.ServeControl(file); err == nil {
+		t.Error("ServeControl replaced a regular file")
+	}
+	if b, err := os.ReadFile(file); err != nil || string(b) != "keep" {
+		t.Errorf("regular file changed: %q, %v", b, err)
+	}
+
+	// A socket nobody serves anymore is replaced.
+	stale := filepath.Join(dir, "stale.sock")
+	l, err := net.Listen("unix", stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+	stop, err := a.ServeControl(stale)
+	if err != nil {
+		t.Fatalf("ServeControl on a stale socket: %v", err)
+	}
+	info, err := os.Lstat(stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("socket permissions = %v, want 0600", perm)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("ServeControl left files behind: %v", entries)
+	}
+	stop()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Lstat(stale); os.IsNotExist(err) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("socket not removed after stop")
+}
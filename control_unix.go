@@ -0,0 +1,94 @@
+//go:build !windows
+
+package majorca
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// listenControl creates the Unix socket at path, accessible to the current
+// user only. The socket is bound inside a private directory, restricted and
+// only then linked to path, so nobody else can connect in between. A stale
+// socket left by a crashed app is replaced; any other file at path, or a
+// socket another app still serves, is an error.
+func listenControl(path string) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".majorca-control-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create control socket: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	tmp := filepath.Join(dir, "s")
+
+	l, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on control socket: %w", err)
+	}
+	ul := l.(*net.UnixListener)
+	// The socket moves to path; controlListener removes it from there.
+	ul.SetUnlinkOnClose(false)
+	if err := os.Chmod(tmp, 0600); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to restrict control socket: %w", err)
+	}
+	if err := os.Link(tmp, path); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to create control socket: %w", err)
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	return &controlListener{UnixListener: ul, path: path, info: info}, nil
+}
+
+// removeStaleSocket removes the socket at path if no app serves it anymore.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("control socket path %s exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("control socket %s is in use by another app", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale control socket: %w", err)
+	}
+	return nil
+}
+
+// controlListener removes its socket on Close, unless it was replaced.
+type controlListener struct {
+	*net.UnixListener
+	path string
+	info os.FileInfo
+}
+
+func (l *controlListener) Close() error {
+	err := l.UnixListener.Close()
+	if info, serr := os.Lstat(l.path); serr == nil && os.SameFile(info, l.info) {
+		os.Remove(l.path)
+	}
+	return err
+}
+
+// DialControl connects to the control socket of a running app, see
+// ServeControl.
+func DialControl(path string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout("unix", path, timeout)
+}
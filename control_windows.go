@@ -0,0 +1,270 @@
+package majorca
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	kernel32                = syscall.NewLazyDLL("kernel32.dll")
+	procCreateNamedPipeW    = kernel32.NewProc("CreateNamedPipeW")
+	procConnectNamedPipe    = kernel32.NewProc("ConnectNamedPipe")
+	procDisconnectNamedPipe = kernel32.NewProc("DisconnectNamedPipe")
+	procWaitNamedPipeW      = kernel32.NewProc("WaitNamedPipeW")
+
+	advapi32                                                 = syscall.NewLazyDLL("advapi32.dll")
+	procConvertStringSecurityDescriptorToSecurityDescriptorW = advapi32.NewProc("ConvertStringSecurityDescriptorToSecurityDescriptorW")
+)
+
+const (
+	pipePrefix = `\\.\pipe\`
+
+	pipeAccessDuplex          = 0x00000003
+	fileFlagFirstPipeInstance = 0x00080000
+	pipeRejectRemoteClients   = 0x00000008
+	pipeUnlimitedInstances    = 255
+	pipeBufferSize            = 64 << 10
+
+	// Clients only let the server identify, never impersonate, them.
+	securitySQOSPresent    = 0x00100000
+	securityIdentification = 0x00010000
+
+	sddlRevision1 = 1
+
+	errorPipeBusy         syscall.Errno = 231
+	errorPipeNotConnected syscall.Errno = 233
+	errorPipeConnected    syscall.Errno = 535
+)
+
+var errNoDeadline = errors.New("deadlines are not supported on control pipes")
+
+// pipeName maps a control path to a named pipe. Names already under
+// \\.\pipe\ are used as is; anything else, e.g. a socket path shared with
+// Unix builds, is placed there.
+func pipeName(path string) string {
+	if strings.HasPrefix(strings.ToLower(path), pipePrefix) {
+		return path
+	}
+	return pipePrefix + strings.ReplaceAll(path, `\`, "/")
+}
+
+// listenControl creates the named pipe for path. Its DACL grants access to
+// the current user only, and it must be the pipe's first instance, so a pipe
+// another app already serves is an error rather than shared.
+func listenControl(path string) (net.Listener, error) {
+	name := pipeName(path)
+	name16, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	sa, err := userOnlySecurity()
+	if err != nil {
+		return nil, fmt.Errorf("failed to restrict control pipe: %w", err)
+	}
+	l := &pipeListener{name: name, name16: name16, sa: sa}
+	h, err := l.create(true)
+	if err != nil {
+		syscall.LocalFree(syscall.Handle(sa.SecurityDescriptor))
+		return nil, fmt.Errorf("failed to create control pipe %s: %w", name, err)
+	}
+	l.next = h
+	return l, nil
+}
+
+// userOnlySecurity returns security attributes whose DACL only admits the
+// user running the app.
+func userOnlySecurity() (*syscall.SecurityAttributes, error) {
+	token, err := syscall.OpenCurrentProcessToken()
+	if err != nil {
+		return nil, err
+	}
+	defer token.Close()
+	user, err := token.GetTokenUser()
+	if err != nil {
+		return nil, err
+	}
+	sid, err := user.User.Sid.String()
+	if err != nil {
+		return nil, err
+	}
+	sddl, err := syscall.UTF16PtrFromString("D:P(A;;GA;;;" + sid + ")")
+	if err != nil {
+		return nil, err
+	}
+	var sd uintptr
+	r, _, err := procConvertStringSecurityDescriptorToSecurityDescriptorW.Call(
+		uintptr(unsafe.Pointer(sddl)), sddlRevision1, uintptr(unsafe.Pointer(&sd)), 0)
+	if r == 0 {
+		return nil, err
+	}
+	return &syscall.SecurityAttributes{
+		Length:             uint32(unsafe.Sizeof(syscall.SecurityAttributes{})),
+		SecurityDescriptor: sd,
+	}, nil
+}
+
+// pipeListener accepts clients on a named pipe. One instance of the pipe
+// always waits for the next client, so the name never goes away while the
+// listener is open.
+type pipeListener struct {
+	name   string
+	name16 *uint16
+	sa     *syscall.SecurityAttributes
+
+	mu        sync.Mutex
+	next      syscall.Handle // Instance waiting for the next client
+	accepting bool
+	closed    bool
+}
+
+func (l *pipeListener) create(first bool) (syscall.Handle, error) {
+	mode := uintptr(pipeAccessDuplex)
+	if first {
+		mode |= fileFlagFirstPipeInstance
+	}
+	h, _, err := procCreateNamedPipeW.Call(uintptr(unsafe.Pointer(l.name16)), mode,
+		pipeRejectRemoteClients, pipeUnlimitedInstances, pipeBufferSize, pipeBufferSize, 0,
+		uintptr(unsafe.Pointer(l.sa)))
+	if syscall.Handle(h) == syscall.InvalidHandle {
+		return syscall.InvalidHandle, err
+	}
+	return syscall.Handle(h), nil
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil, net.ErrClosed
+	}
+	h := l.next
+	l.accepting = true
+	l.mu.Unlock()
+
+	r, _, err := procConnectNamedPipe.Call(uintptr(h), 0)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.accepting = false
+	if l.closed {
+		// Close woke us up by connecting; the instance is ours to close.
+		syscall.CloseHandle(h)
+		return nil, net.ErrClosed
+	}
+	if r == 0 && err != errorPipeConnected {
+		return nil, err
+	}
+	next, err := l.create(false)
+	if err != nil {
+		syscall.CloseHandle(h)
+		l.closed = true
+		return nil, fmt.Errorf("failed to create control pipe instance: %w", err)
+	}
+	l.next = next
+	return &pipeConn{h: h, name: l.name, server: true}, nil
+}
+
+func (l *pipeListener) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	accepting := l.accepting
+	if !accepting {
+		syscall.CloseHandle(l.next)
+	}
+	syscall.LocalFree(syscall.Handle(l.sa.SecurityDescriptor))
+	l.mu.Unlock()
+
+	if accepting {
+		// ConnectNamedPipe only returns once a client connects.
+		if c, err := DialControl(l.name, time.Second); err == nil {
+			c.Close()
+		}
+	}
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return pipeAddr(l.name)
+}
+
+type pipeAddr string
+
+func (a pipeAddr) Network() string { return "pipe" }
+func (a pipeAddr) String() string  { return string(a) }
+
+// pipeConn is one end of a named pipe connection. It does not support
+// deadlines.
+type pipeConn struct {
+	h      syscall.Handle
+	name   string
+	server bool
+	once   sync.Once
+}
+
+func (c *pipeConn) Read(b []byte) (int, error) {
+	var n uint32
+	err := syscall.ReadFile(c.h, b, &n, nil)
+	if err == syscall.ERROR_BROKEN_PIPE || err == errorPipeNotConnected {
+		return int(n), io.EOF
+	}
+	return int(n), err
+}
+
+func (c *pipeConn) Write(b []byte) (int, error) {
+	var n uint32
+	err := syscall.WriteFile(c.h, b, &n, nil)
+	return int(n), err
+}
+
+func (c *pipeConn) Close() error {
+	var err error
+	c.once.Do(func() {
+		if c.server {
+			syscall.FlushFileBuffers(c.h)
+			procDisconnectNamedPipe.Call(uintptr(c.h))
+		}
+		err = syscall.CloseHandle(c.h)
+	})
+	return err
+}
+
+func (c *pipeConn) LocalAddr() net.Addr                { return pipeAddr(c.name) }
+func (c *pipeConn) RemoteAddr() net.Addr               { return pipeAddr(c.name) }
+func (c *pipeConn) SetDeadline(t time.Time) error      { return errNoDeadline }
+func (c *pipeConn) SetReadDeadline(t time.Time) error  { return errNoDeadline }
+func (c *pipeConn) SetWriteDeadline(t time.Time) error { return errNoDeadline }
+
+// DialControl connects to the control pipe of a running app, see
+// ServeControl. The returned connection does not support deadlines.
+func DialControl(path string, timeout time.Duration) (net.Conn, error) {
+	name := pipeName(path)
+	name16, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		h, err := syscall.CreateFile(name16, syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil,
+			syscall.OPEN_EXISTING, securitySQOSPresent|securityIdentification, 0)
+		if err == nil {
+			return &pipeConn{h: h, name: name}, nil
+		}
+		wait := time.Until(deadline).Milliseconds()
+		if err != errorPipeBusy || wait <= 0 {
+			return nil, err
+		}
+		// Every instance is busy; wait for one to free up.
+		procWaitNamedPipeW.Call(uintptr(unsafe.Pointer(name16)), uintptr(wait))
+	}
+}
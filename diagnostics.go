@@ -0,0 +1,226 @@
+package majorca
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// DiagnosticsURL opens the built-in diagnostics page when passed to
+// Window.Load or WindowOptions.URL, or when a link to it is clicked.
+const DiagnosticsURL = "app://majorca"
+
+// maxRecentErrors bounds the log records kept for the diagnostics page.
+const maxRecentErrors = 50
+
+// Diagnostics describes the running installation for support purposes.
+type Diagnostics struct {
+	Version     string    `json:"version"`
+	GoVersion   string    `json:"goVersion"`
+	OS          string    `json:"os"`
+	Arch        string    `json:"arch"`
+	Backend     string    `json:"backend"`
+	Browser     string    `json:"browser"`
+	CommandLine []string  `json:"commandLine"`
+	Profile     string    `json:"profile"`
+	Bindings    []string  `json:"bindings"`
+	Windows     int       `json:"windows"`
+	Errors      []LogLine `json:"errors"`
+}
+
+// LogLine is a warning or error logged by the library.
+type LogLine struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+	Attrs   string    `json:"attrs,omitempty"`
+}
+
+// Diagnostics collects the current state of the app.
+func (a *App) Diagnostics() Diagnostics {
+	d := Diagnostics{
+		Version:     browser.ModuleVersion(),
+		GoVersion:   runtime.Version(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		Backend:     "chrome",
+		CommandLine: a.main.CommandLine(),
+		Profile:     a.main.ProfileDir(),
+		Errors:      a.errors.lines(),
+	}
+	if product, err := a.main.Product(); err == nil {
+		d.Browser = product
+	}
+
+	a.mu.Lock()
+	for name := range a.bindings {
+		d.Bindings = append(d.Bindings, name)
+	}
+	d.Windows = len(a.windows)
+	a.mu.Unlock()
+	sort.Strings(d.Bindings)
+	return d
+}
+
+// DumpDiagnostics writes the diagnostics as JSON into dir, or the temporary
+// directory if dir is empty, and returns the file's path.
+func (a *App) DumpDiagnostics(dir string) (string, error) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	data, err := json.MarshalIndent(a.Diagnostics(), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal diagnostics: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("majorca-diagnostics-%s.json", time.Now().Format("20060102-150405")))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write diagnostics: %w", err)
+	}
+	return path, nil
+}
+
+// ShowDiagnostics replaces the window's page with the diagnostics page.
+func (w *Window) ShowDiagnostics() error {
+	var html bytes.Buffer
+	if err := diagnosticsPage.Execute(&html, w.app.Diagnostics()); err != nil {
+		return fmt.Errorf("failed to render diagnostics: %w", err)
+	}
+	return w.LoadHTML(html.String())
+}
+
+// Load navigates the window to url, showing the diagnostics page for
+// DiagnosticsURL.
+func (w *Window) Load(url string) error {
+	if url == DiagnosticsURL {
+		return w.ShowDiagnostics()
+	}
+	return w.Chrome.Load(url)
+}
+
+var diagnosticsPage = template.Must(template.New("diagnostics").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>majorca diagnostics</title>
+<style>
+	body { font: 14px system-ui, sans-serif; margin: 2em; color: #222; }
+	table { border-collapse: collapse; margin-bottom: 2em; }
+	th, td { text-align: left; vertical-align: top; padding: 4px 12px 4px 0; }
+	th { color: #666; font-weight: normal; }
+	code { word-break: break-all; }
+	.error { color: #b00; }
+</style>
+</head>
+<body>
+<h1>majorca diagnostics</h1>
+<table>
+	<tr><th>Version</th><td>{{.Version}} ({{.GoVersion}}, {{.OS}}/{{.Arch}})</td></tr>
+	<tr><th>Backend</th><td>{{.Backend}} {{.Browser}}</td></tr>
+	<tr><th>Command line</th><td><code>{{range .CommandLine}}{{.}} {{end}}</code></td></tr>
+	<tr><th>Profile</th><td><code>{{.Profile}}</code></td></tr>
+	<tr><th>Bindings</th><td>{{range .Bindings}}<code>{{.}}</code> {{else}}none{{end}}</td></tr>
+	<tr><th>Windows</th><td>{{.Windows}}</td></tr>
+</table>
+<h2>Recent errors</h2>
+<table>
+{{range .Errors}}	<tr class="error"><td>{{.Time.Format "15:04:05"}}</td><td>{{.Level}}</td><td>{{.Message}} <code>{{.Attrs}}</code></td></tr>
+{{else}}	<tr><td>No errors logged.</td></tr>
+{{end}}</table>
+<button id="dump">Save diagnostic bundle</button> <span id="status"></span>
+<script>
+	document.getElementById("dump").onclick = async () => {
+		const status = document.getElementById("status");
+		try {
+			status.textContent = "Saved to " + await window.majorca.dumpDiagnostics();
+		} catch (e) {
+			status.textContent = e.message;
+		}
+	};
+</script>
+</body>
+</html>
+`))
+
+// errorLog is a slog.Handler that keeps the most recent warnings and errors
+// for the diagnostics page and passes every record on to the app's logger.
+type errorLog struct {
+	next   slog.Handler // nil when the app has no logger
+	mu     *sync.Mutex
+	recent *[]LogLine
+	attrs  []slog.Attr
+	events *eventLog // Also receives every kept record
+}
+
+func newErrorLog(next slog.Handler, events *eventLog) *errorLog {
+	return &errorLog{next: next, mu: &sync.Mutex{}, recent: new([]LogLine), events: events}
+}
+
+func (h *errorLog) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelWarn || (h.next != nil && h.next.Enabled(ctx, level))
+}
+
+func (h *errorLog) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelWarn {
+		var attrs bytes.Buffer
+		add := func(a slog.Attr) bool {
+			fmt.Fprintf(&attrs, "%s=%v ", a.Key, a.Value)
+			return true
+		}
+		for _, a := range h.attrs {
+			add(a)
+		}
+		r.Attrs(add)
+
+		line := LogLine{
+			Time:    r.Time,
+			Level:   r.Level.String(),
+			Message: r.Message,
+			Attrs:   string(bytes.TrimSpace(attrs.Bytes())),
+		}
+		h.events.add(AppEvent{Time: line.Time, Kind: EventError, Message: line.Message, Error: line.Attrs})
+		h.mu.Lock()
+		*h.recent = append(*h.recent, line)
+		if len(*h.recent) > maxRecentErrors {
+			*h.recent = (*h.recent)[len(*h.recent)-maxRecentErrors:]
+		}
+		h.mu.Unlock()
+	}
+	if h.next != nil && h.next.Enabled(ctx, r.Level) {
+		return h.next.Handle(ctx, r)
+	}
+	return nil
+}
+
+func (h *errorLog) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = append(append([]slog.Attr(nil), h.attrs...), attrs...)
+	if h.next != nil {
+		c.next = h.next.WithAttrs(attrs)
+	}
+	return &c
+}
+
+func (h *errorLog) WithGroup(name string) slog.Handler {
+	c := *h
+	if h.next != nil {
+		c.next = h.next.WithGroup(name)
+	}
+	return &c
+}
+
+func (h *errorLog) lines() []LogLine {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]LogLine(nil), *h.recent...)
+}
@@ -0,0 +1,57 @@
+package majorca_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/grngxd/majorca"
+	"github.com/grngxd/majorca/browser"
+	"github.com/grngxd/majorca/internal/cdptest"
+)
+
+func TestErrorLog(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	a, err := majorca.AttachApp(cdptest.New(t, nil).URL, browser.WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Quit()
+	w, err := a.NewWindow(majorca.WindowOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := w.Logger().With("component", "test")
+
+	log.Debug("debug line")
+	log.Info("info line")
+	log.Warn("warn line", "attempt", 2)
+	log.Error("error line")
+
+	// The app logger sees what its level allows.
+	if s := out.String(); strings.Contains(s, "debug line") || !strings.Contains(s, "info line") || !strings.Contains(s, "error line") {
+		t.Errorf("app logger got:\n%s", s)
+	}
+
+	// Diagnostics and the event log keep warnings and errors only.
+	lines := a.Diagnostics().Errors
+	if len(lines) != 2 || lines[0].Message != "warn line" || lines[0].Level != "WARN" || lines[1].Message != "error line" {
+		t.Fatalf("Diagnostics().Errors = %+v", lines)
+	}
+	if !strings.Contains(lines[0].Attrs, "component=test") || !strings.Contains(lines[0].Attrs, "attempt=2") {
+		t.Errorf("attrs = %q, want the logger's and the record's", lines[0].Attrs)
+	}
+	events := a.Events(majorca.EventQuery{Kind: majorca.EventError})
+	if len(events) != 2 || events[1].Message != "error line" {
+		t.Errorf("error events = %+v", events)
+	}
+
+	for i := 0; i < 100; i++ {
+		log.Warn("flood")
+	}
+	if n := len(a.Diagnostics().Errors); n != 50 {
+		t.Errorf("diagnostics keep %d lines, want 50", n)
+	}
+}
@@ -0,0 +1,134 @@
+package majorca
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+	"github.com/grngxd/majorca/browser/chrome"
+)
+
+// maxAppEvents bounds the app event log; older events are dropped.
+const maxAppEvents = 500
+
+// Kinds of AppEvent.
+const (
+	EventNavigation = "navigation" // Message is the new URL
+	EventError      = "error"      // A warning or error logged by the library
+	EventBinding    = "binding"    // Message is the binding name, Error is set on failure
+	EventWindow     = "window"     // Message is "opened" or "closed"
+	EventCrash      = "crash"      // The browser or a page renderer crashed
+	EventRestart    = "restart"    // The connection to a window was re-established, Message is the cause
+)
+
+// AppEvent is one entry of the app event log.
+type AppEvent struct {
+	Seq     int64     `json:"seq"` // Increases by one per event
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"`
+	Window  int       `json:"window,omitempty"` // 0 for app-wide events
+	Message string    `json:"message"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// EventQuery selects events from the log. Zero fields match everything.
+type EventQuery struct {
+	Kind   string `json:"kind"`
+	Window int    `json:"window"`
+	After  int64  `json:"after"` // Only events with a greater Seq, for polling
+	Limit  int    `json:"limit"` // Keep only the newest Limit matches
+}
+
+// eventLog is a bounded ring of AppEvents.
+type eventLog struct {
+	mu     sync.Mutex
+	seq    int64
+	events []AppEvent
+}
+
+func (l *eventLog) add(e AppEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seq++
+	e.Seq = l.seq
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	l.events = append(l.events, e)
+	if len(l.events) > maxAppEvents {
+		l.events = append(l.events[:0:0], l.events[len(l.events)-maxAppEvents:]...)
+	}
+}
+
+func (l *eventLog) query(q EventQuery) []AppEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	matches := []AppEvent{}
+	for _, e := range l.events {
+		if (q.Kind == "" || e.Kind == q.Kind) && (q.Window == 0 || e.Window == q.Window) && e.Seq > q.After {
+			matches = append(matches, e)
+		}
+	}
+	if q.Limit > 0 && len(matches) > q.Limit {
+		matches = matches[len(matches)-q.Limit:]
+	}
+	return matches
+}
+
+// Events returns the recent navigations, errors, binding calls, window
+// changes, crashes and restarts matching q, oldest first. The log keeps the
+// last maxAppEvents events. Pages read it with majorca.events(query), so
+// debug panels need no plumbing of their own.
+func (a *App) Events(q EventQuery) []AppEvent {
+	return a.events.query(q)
+}
+
+// logEvent adds an event to the app event log.
+func (a *App) logEvent(kind string, window int, message string, err error) {
+	e := AppEvent{Kind: kind, Window: window, Message: message}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	a.events.add(e)
+}
+
+// trackWindow logs w's main-frame navigations, reconnects and lifetime.
+func (a *App) trackWindow(w *Window) {
+	a.logEvent(EventWindow, w.ID, "opened", nil)
+	w.On("Page.frameNavigated", func(e browser.Event) {
+		var p struct {
+			Frame struct {
+				ParentID string `json:"parentId"`
+				URL      string `json:"url"`
+			} `json:"frame"`
+		}
+		if json.Unmarshal(e.Params, &p) != nil || p.Frame.ParentID != "" {
+			return
+		}
+		a.logEvent(EventNavigation, w.ID, p.Frame.URL, nil)
+	})
+	w.On(chrome.EventReconnected, func(e browser.Event) {
+		var p struct {
+			Cause string `json:"cause"`
+		}
+		json.Unmarshal(e.Params, &p)
+		a.logEvent(EventRestart, w.ID, p.Cause, nil)
+	})
+	w.OnCrash(func(r *browser.CrashReport) {
+		a.logEvent(EventCrash, w.ID, r.Err, nil)
+	})
+	w.OnExit(func(error) {
+		a.logEvent(EventWindow, w.ID, "closed", nil)
+	})
+}
+
+// loggedBinding wraps f so every call from window shows up in the event
+// log.
+func (a *App) loggedBinding(window int, name string, f browser.BindingFunc) browser.BindingFunc {
+	return func(args []json.RawMessage) (interface{}, error) {
+		result, err := f(args)
+		a.logEvent(EventBinding, window, name, err)
+		return result, err
+	}
+}
@@ -0,0 +1,66 @@
+package majorca_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grngxd/majorca"
+)
+
+func TestEventLog(t *testing.T) {
+	a, _ := newFakeApp(t, newFakePages())
+	for i := 1; i <= majorca.MaxAppEvents+10; i++ {
+		kind := majorca.EventNavigation
+		if i%2 == 0 {
+			kind = majorca.EventBinding
+		}
+		majorca.AddEvent(a, majorca.AppEvent{Kind: kind, Window: i % 3, Message: fmt.Sprint(i)})
+	}
+
+	all := a.Events(majorca.EventQuery{})
+	if len(all) != majorca.MaxAppEvents {
+		t.Fatalf("log holds %d events, want %d", len(all), majorca.MaxAppEvents)
+	}
+	if first, last := all[0], all[len(all)-1]; first.Seq != 11 || last.Seq != majorca.MaxAppEvents+10 || first.Time.IsZero() {
+		t.Errorf("log spans %+v to %+v, want the newest events with times", first, last)
+	}
+
+	for _, e := range a.Events(majorca.EventQuery{Kind: majorca.EventBinding, Window: 1}) {
+		if e.Kind != majorca.EventBinding || e.Window != 1 {
+			t.Errorf("query by kind and window returned %+v", e)
+		}
+	}
+	after := a.Events(majorca.EventQuery{After: majorca.MaxAppEvents + 5})
+	if len(after) != 5 || after[0].Seq != majorca.MaxAppEvents+6 {
+		t.Errorf("After returned %d events starting at %+v", len(after), after[0])
+	}
+	limited := a.Events(majorca.EventQuery{Kind: majorca.EventNavigation, Limit: 2})
+	if len(limited) != 2 || limited[1].Seq != majorca.MaxAppEvents+9 {
+		t.Errorf("Limit returned %+v, want the newest two navigations", limited)
+	}
+	if none := a.Events(majorca.EventQuery{Kind: majorca.EventCrash}); none == nil || len(none) != 0 {
+		t.Errorf("no matches returned %#v, want an empty list", none)
+	}
+}
+
+func TestRestartEvent(t *testing.T) {
+	a, d := newFakeApp(t, newFakePages())
+	w, err := a.NewWindow(majorca.WindowOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Drop("main")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if e := a.Events(majorca.EventQuery{Kind: majorca.EventRestart}); len(e) > 0 {
+			if e[0].Window != w.ID || e[0].Message == "" {
+				t.Errorf("restart event = %+v, want window %d and a cause", e[0], w.ID)
+			}
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("no restart event after the connection dropped")
+}
@@ -0,0 +1,43 @@
+package majorca
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+	"github.com/grngxd/majorca/browser/chrome"
+)
+
+// Internals exposed to the external tests.
+
+var URLAllowed = urlAllowed
+
+// AgentCommand runs one command the way a connected agent for a would.
+func AgentCommand(a *App, opts AgentOptions, req ControlRequest) (interface{}, error) {
+	ag := &agent{app: a, opts: opts, started: time.Now(), stop: make(chan struct{})}
+	return ag.command(req)
+}
+
+// AttachApp builds an App on a Chrome that is already running, such as a
+// cdptest server.
+func AttachApp(endpoint string, opts ...browser.Option) (*App, error) {
+	return newApp(func(opts ...browser.Option) (*chrome.Chrome, error) {
+		return chrome.Attach(endpoint, opts...)
+	}, opts...)
+}
+
+// HandleBus routes a bus message, given as JSON, as if window from sent it.
+func HandleBus(a *App, from int, msg string) (interface{}, error) {
+	var m busMessage
+	if err := json.Unmarshal([]byte(msg), &m); err != nil {
+		return nil, err
+	}
+	return a.handleBus(from, m)
+}
+
+const MaxAppEvents = maxAppEvents
+
+// AddEvent adds e to a's event log.
+func AddEvent(a *App, e AppEvent) {
+	a.events.add(e)
+}
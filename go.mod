@@ -2,6 +2,4 @@ module github.com/grngxd/majorca
 
 go 1.22.6
 
-require golang.org/x/net v0.31.0
-
-require github.com/gorilla/websocket v1.5.3 // indirect
+require github.com/coder/websocket v1.8.13
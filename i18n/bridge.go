@@ -0,0 +1,118 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// Page is what the bridge needs from a browser backend; *chrome.Chrome and
+// majorca windows implement it.
+type Page interface {
+	Bind(name string, f browser.BindingFunc) error
+	AddInitScript(source string) error
+}
+
+// binding is how the page asks Go to switch languages.
+const binding = "__majorcaI18n"
+
+// bridgeScript installs window.i18n and window.t in every document:
+//
+//	t(id, data, count)     translate id, filling {{.Name}} from data and
+//	                       choosing the plural form for count
+//	i18n.locale            current language
+//	i18n.setLocale(lang)   switch language, resolves to the language used
+//
+// A "majorca:locale" event fires on window whenever the language changes.
+const bridgeScript = `(() => {
+	const i18n = window.i18n = window.i18n || {};
+	i18n.locale = "";
+	i18n.messages = {};
+	i18n.t = (id, data, count) => {
+		const m = i18n.messages[id];
+		if (!m) return id;
+		let text = m.other || "";
+		if (count !== undefined) {
+			const form = count === 0 && m.zero ? "zero" : new Intl.PluralRules(i18n.locale).select(count);
+			text = m[form] || m.other || "";
+		}
+		const values = Object.assign({Count: count, PluralCount: count}, data);
+		return text.replace(/\{\{\s*\.(\w+)\s*\}\}/g, (_, k) => values[k] ?? "");
+	};
+	i18n.setLocale = (lang) => window.%s(lang);
+	window.t = i18n.t;
+})()`
+
+// localeScript switches the page to a language. Every switch adds one as an
+// init script, so later documents start with the latest language.
+const localeScript = `(() => {
+	window.i18n.locale = %s;
+	window.i18n.messages = %s;
+	if (document.documentElement) document.documentElement.lang = %s;
+	window.dispatchEvent(new Event("majorca:locale"));
+})()`
+
+// Bridge connects a catalog to a page.
+type Bridge struct {
+	mu      sync.Mutex
+	page    Page
+	catalog *Catalog
+	locale  string
+}
+
+// Install exposes catalog to page in the language best matching locale, or
+// the system locale if locale is empty.
+func Install(page Page, catalog *Catalog, locale string) (*Bridge, error) {
+	if locale == "" {
+		locale = SystemLocale()
+	}
+	b := &Bridge{page: page, catalog: catalog}
+
+	err := page.Bind(binding, func(args []json.RawMessage) (interface{}, error) {
+		var lang string
+		if len(args) != 1 || json.Unmarshal(args[0], &lang) != nil {
+			return nil, fmt.Errorf("setLocale expects a language tag")
+		}
+		if err := b.SetLocale(lang); err != nil {
+			return nil, err
+		}
+		return b.Locale(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := page.AddInitScript(fmt.Sprintf(bridgeScript, binding)); err != nil {
+		return nil, err
+	}
+	if err := b.SetLocale(locale); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// SetLocale switches the page to the catalog language best matching locale.
+func (b *Bridge) SetLocale(locale string) error {
+	lang := b.catalog.Match(locale)
+	messages, err := json.Marshal(b.catalog.Messages(lang))
+	if err != nil {
+		return fmt.Errorf("failed to marshal messages: %w", err)
+	}
+	quoted, _ := json.Marshal(lang)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.page.AddInitScript(fmt.Sprintf(localeScript, quoted, messages, quoted)); err != nil {
+		return err
+	}
+	b.locale = lang
+	return nil
+}
+
+// Locale returns the language the page currently uses.
+func (b *Bridge) Locale() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.locale
+}
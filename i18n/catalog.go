@@ -0,0 +1,182 @@
+// Package i18n lets pages translate their UI with message catalogs managed
+// in Go. Catalogs use go-i18n's JSON message file format, so existing
+// translations and tooling keep working.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Message is a translatable message. Plural forms follow CLDR categories;
+// messages without plurals only set Other.
+type Message struct {
+	ID          string `json:"id,omitempty"`
+	Description string `json:"description,omitempty"`
+	Zero        string `json:"zero,omitempty"`
+	One         string `json:"one,omitempty"`
+	Two         string `json:"two,omitempty"`
+	Few         string `json:"few,omitempty"`
+	Many        string `json:"many,omitempty"`
+	Other       string `json:"other,omitempty"`
+}
+
+// Catalog holds messages per language tag.
+type Catalog struct {
+	mu       sync.Mutex
+	fallback string
+	messages map[string]map[string]Message
+}
+
+// NewCatalog returns an empty catalog falling back to the given language
+// when no better match exists.
+func NewCatalog(fallback string) *Catalog {
+	return &Catalog{
+		fallback: Canonical(fallback),
+		messages: make(map[string]map[string]Message),
+	}
+}
+
+// AddMessages adds or replaces messages for lang.
+func (c *Catalog) AddMessages(lang string, messages ...Message) {
+	lang = Canonical(lang)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.messages[lang] == nil {
+		c.messages[lang] = make(map[string]Message)
+	}
+	for _, m := range messages {
+		c.messages[lang][m.ID] = m
+	}
+}
+
+// LoadMessageFile reads a go-i18n JSON message file. The language is taken
+// from the file name, e.g. "active.de.json" or "de-AT.json".
+func (c *Catalog) LoadMessageFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return c.ParseMessageFileBytes(data, path)
+}
+
+// ParseMessageFileBytes parses message file contents; path only provides
+// the language.
+func (c *Catalog) ParseMessageFileBytes(data []byte, path string) error {
+	parts := strings.Split(filepath.Base(path), ".")
+	if len(parts) < 2 {
+		return fmt.Errorf("no language in message file name %s", path)
+	}
+	lang := parts[len(parts)-2]
+
+	messages, err := parseMessages(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	c.AddMessages(lang, messages...)
+	return nil
+}
+
+// parseMessages accepts both go-i18n layouts: a map from id to a string or
+// message object, and a list of message objects with ids.
+func parseMessages(data []byte) ([]Message, error) {
+	var list []Message
+	if json.Unmarshal(data, &list) == nil {
+		return list, nil
+	}
+
+	var byID map[string]json.RawMessage
+	if err := json.Unmarshal(data, &byID); err != nil {
+		return nil, err
+	}
+	messages := make([]Message, 0, len(byID))
+	for id, raw := range byID {
+		m := Message{ID: id}
+		if err := json.Unmarshal(raw, &m.Other); err != nil {
+			if err := json.Unmarshal(raw, &m); err != nil {
+				return nil, fmt.Errorf("message %s: %w", id, err)
+			}
+			m.ID = id
+		}
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+// Languages returns the languages with messages, sorted.
+func (c *Catalog) Languages() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	langs := make([]string, 0, len(c.messages))
+	for lang := range c.messages {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Match returns the catalog language best matching locale: an exact match,
+// then the base language ("de" for "de-AT"), then another region of it, then
+// the fallback.
+func (c *Catalog) Match(locale string) string {
+	locale = Canonical(locale)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.messages[locale]; ok {
+		return locale
+	}
+	base, _, _ := strings.Cut(locale, "-")
+	if _, ok := c.messages[base]; ok {
+		return base
+	}
+	var regions []string
+	for lang := range c.messages {
+		if strings.HasPrefix(lang, base+"-") {
+			regions = append(regions, lang)
+		}
+	}
+	if len(regions) > 0 {
+		sort.Strings(regions)
+		return regions[0]
+	}
+	return c.fallback
+}
+
+// Messages returns the messages for lang, with messages missing from it
+// filled in from the fallback language.
+func (c *Catalog) Messages(lang string) map[string]Message {
+	lang = Canonical(lang)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]Message, len(c.messages[c.fallback]))
+	for id, m := range c.messages[c.fallback] {
+		out[id] = m
+	}
+	for id, m := range c.messages[lang] {
+		out[id] = m
+	}
+	return out
+}
+
+// Canonical normalizes a locale to a BCP 47 style tag: "de_AT.UTF-8"
+// becomes "de-AT".
+func Canonical(locale string) string {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	parts := strings.Split(strings.ReplaceAll(locale, "_", "-"), "-")
+	for i, p := range parts {
+		if i == 0 {
+			parts[i] = strings.ToLower(p)
+		} else if len(p) == 2 {
+			parts[i] = strings.ToUpper(p)
+		} else if len(p) == 4 {
+			parts[i] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:])
+		}
+	}
+	return strings.Join(parts, "-")
+}
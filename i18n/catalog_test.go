@@ -0,0 +1,49 @@
+package i18n_test
+
+import (
+	"testing"
+
+	"github.com/grngxd/majorca/i18n"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := map[string]string{
+		"de_AT.UTF-8": "de-AT",
+		"en-us":       "en-US",
+		"zh_hant_TW":  "zh-Hant-TW",
+		"fr":          "fr",
+		"sr_RS@latin": "sr-RS",
+	}
+	for in, want := range tests {
+		if got := i18n.Canonical(in); got != want {
+			t.Errorf("Canonical(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	c := i18n.NewCatalog("en")
+	if err := c.ParseMessageFileBytes([]byte(`{
+		"hello": "Hello {{.Name}}",
+		"items": {"one": "{{.Count}} item", "other": "{{.Count}} items"}
+	}`), "active.en.json"); err != nil {
+		t.Fatalf("Failed to parse English messages: %v", err)
+	}
+	if err := c.ParseMessageFileBytes([]byte(`[{"id": "hello", "other": "Hallo {{.Name}}"}]`), "de.json"); err != nil {
+		t.Fatalf("Failed to parse German messages: %v", err)
+	}
+
+	for locale, want := range map[string]string{"de_AT": "de", "de": "de", "ja": "en", "en-GB": "en"} {
+		if got := c.Match(locale); got != want {
+			t.Errorf("Match(%q) = %q, want %q", locale, got, want)
+		}
+	}
+
+	de := c.Messages("de")
+	if de["hello"].Other != "Hallo {{.Name}}" {
+		t.Errorf("German hello = %q", de["hello"].Other)
+	}
+	if de["items"].One != "{{.Count}} item" {
+		t.Errorf("Missing German message was not filled from fallback: %+v", de["items"])
+	}
+}
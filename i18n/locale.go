@@ -0,0 +1,33 @@
+package i18n
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// SystemLocale returns the user's locale as a BCP 47 style tag, or "en" if
+// it cannot be determined.
+func SystemLocale() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" && v != "C" && v != "POSIX" {
+			return Canonical(v)
+		}
+	}
+
+	var out []byte
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		out, err = exec.Command("defaults", "read", "-g", "AppleLocale").Output()
+	case "windows":
+		out, err = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", "(Get-Culture).Name").Output()
+	}
+	if err == nil {
+		if v := strings.TrimSpace(string(out)); v != "" {
+			return Canonical(v)
+		}
+	}
+	return "en"
+}
@@ -0,0 +1,171 @@
+// Package cdptest runs a fake DevTools endpoint for tests: the HTTP
+// discovery routes Chrome serves on its debugging port and a WebSocket per
+// page target that answers commands through a callback.
+package cdptest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/coder/websocket"
+)
+
+// ReplyFunc answers the command method sent to target. A nil result is
+// sent as an empty object; an error result becomes a protocol error.
+type ReplyFunc func(target, method string, params json.RawMessage) interface{}
+
+// Server is a fake DevTools endpoint.
+type Server struct {
+	URL string // http://host:port, as passed to chrome.Attach
+
+	srv   *httptest.Server
+	reply ReplyFunc
+
+	mu      sync.Mutex
+	calls   []string
+	pages   map[string]*page
+	targets int
+}
+
+type page struct {
+	conn *websocket.Conn
+}
+
+// New starts a Server that is shut down when the test ends. The first page
+// target is called "main"; Target.createTarget adds more unless reply
+// answers it.
+func New(t testing.TB, reply ReplyFunc) *Server {
+	t.Helper()
+	s := &Server{reply: reply, pages: make(map[string]*page)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"type":"page","webSocketDebuggerUrl":"ws://%s/devtools/page/main"}]`, r.Host)
+	})
+	mux.HandleFunc("/json/version", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"Browser":"Chrome/120.0.6099.109","Protocol-Version":"1.3"}`)
+	})
+	mux.HandleFunc("/devtools/page/", s.serve)
+	s.srv = httptest.NewServer(mux)
+	s.URL = s.srv.URL
+	t.Cleanup(s.Close)
+	return s
+}
+
+// PageURL returns the WebSocket debugger URL of target.
+func (s *Server) PageURL(target string) string {
+	return "ws" + strings.TrimPrefix(s.URL, "http") + "/devtools/page/" + target
+}
+
+// Close drops all page connections and stops the server.
+func (s *Server) Close() {
+	s.mu.Lock()
+	for _, p := range s.pages {
+		p.conn.CloseNow()
+	}
+	s.mu.Unlock()
+	s.srv.Close()
+}
+
+// Drop closes the connection of target, like a DevTools socket that died,
+// while the server keeps accepting new ones.
+func (s *Server) Drop(target string) {
+	s.mu.Lock()
+	p, ok := s.pages[target]
+	delete(s.pages, target)
+	s.mu.Unlock()
+	if ok {
+		p.conn.CloseNow()
+	}
+}
+
+// Called reports whether any target received method.
+func (s *Server) Called(method string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.calls {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Emit sends an event to target, which must be connected.
+func (s *Server) Emit(target, method string, params interface{}) error {
+	s.mu.Lock()
+	p, ok := s.pages[target]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("target %s is not connected", target)
+	}
+	msg, err := json.Marshal(map[string]interface{}{"method": method, "params": params})
+	if err != nil {
+		return err
+	}
+	return p.write(msg)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	target := strings.TrimPrefix(r.URL.Path, "/devtools/page/")
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{InsecureSkipVerify: true})
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(-1)
+
+	p := &page{conn: conn}
+	s.mu.Lock()
+	s.pages[target] = p
+	s.mu.Unlock()
+
+	for {
+		_, data, err := conn.Read(context.Background())
+		if err != nil {
+			return
+		}
+		var cmd struct {
+			ID     int32           `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if json.Unmarshal(data, &cmd) != nil {
+			continue
+		}
+		s.mu.Lock()
+		s.calls = append(s.calls, cmd.Method)
+		s.mu.Unlock()
+
+		var result interface{}
+		if s.reply != nil {
+			result = s.reply(target, cmd.Method, cmd.Params)
+		}
+		if result == nil && cmd.Method == "Target.createTarget" {
+			s.mu.Lock()
+			s.targets++
+			result = map[string]string{"targetId": fmt.Sprintf("target-%d", s.targets)}
+			s.mu.Unlock()
+		}
+		resp := map[string]interface{}{"id": cmd.ID, "result": result}
+		if result == nil {
+			resp["result"] = struct{}{}
+		}
+		if err, ok := result.(error); ok {
+			resp = map[string]interface{}{"id": cmd.ID, "error": map[string]interface{}{"code": -32000, "message": err.Error()}}
+		}
+		msg, _ := json.Marshal(resp)
+		p.write(msg)
+	}
+}
+
+// write sends data as one text message.
+func (p *page) write(data []byte) error {
+	return p.conn.Write(context.Background(), websocket.MessageText, data)
+}
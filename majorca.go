@@ -0,0 +1,30 @@
+// Package majorca builds desktop apps on top of an installed browser.
+//
+// The browser backends live in browser/chrome and browser/firefox; this
+// package holds app-level helpers that are independent of the engine.
+package majorca
+
+import (
+	"github.com/grngxd/majorca/browser"
+
+	// Register the built-in backends.
+	_ "github.com/grngxd/majorca/browser/chrome"
+	_ "github.com/grngxd/majorca/browser/firefox"
+	_ "github.com/grngxd/majorca/browser/webkit"
+	_ "github.com/grngxd/majorca/browser/webview2"
+)
+
+// New launches a browser using the best backend available on this machine,
+// or the one selected with browser.WithBackend. Out-of-tree backends take
+// part once their package is imported and has called browser.Register.
+func New(opts ...browser.Option) (browser.Browser, error) {
+	return browser.Open(opts...)
+}
+
+// CleanupStale removes temporary profiles left behind by majorca apps that
+// crashed or were killed before they could clean up after themselves. It is
+// safe to call on every startup.
+func CleanupStale() error {
+	_, err := browser.CleanupStale()
+	return err
+}
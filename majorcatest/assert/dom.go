@@ -0,0 +1,157 @@
+// Package assert provides snapshot assertions for UI tests.
+package assert
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+// Evaluator runs JavaScript in a page, e.g. any browser.Browser.
+type Evaluator interface {
+	Eval(expr string) (string, string, error)
+}
+
+// normalizeScript serializes the element matching a selector one node per
+// line, indented by depth. Attributes and class names are sorted, runs of
+// whitespace collapsed and comments dropped, so the output only changes
+// when the rendered structure does.
+const normalizeScript = `(() => {
+	const root = document.querySelector(%s);
+	if (!root) throw new Error("no element matches " + %s);
+	const empty = new Set(["area", "base", "br", "col", "embed", "hr", "img", "input", "link", "meta", "source", "track", "wbr"]);
+	const esc = (s) => s.replace(/&/g, "&amp;").replace(/</g, "&lt;").replace(/>/g, "&gt;");
+	const lines = [];
+	const walk = (node, depth) => {
+		const pad = "  ".repeat(depth);
+		if (node.nodeType === Node.TEXT_NODE) {
+			const text = node.textContent.replace(/\s+/g, " ").trim();
+			if (text) lines.push(pad + esc(text));
+			return;
+		}
+		if (node.nodeType !== Node.ELEMENT_NODE) return;
+		const tag = node.localName;
+		const attrs = Array.from(node.attributes, (a) => {
+			let value = a.value.replace(/\s+/g, " ").trim();
+			if (a.name === "class") value = value.split(" ").sort().join(" ");
+			return a.name + '="' + esc(value).replace(/"/g, "&quot;") + '"';
+		}).sort();
+		lines.push(pad + "<" + [tag, ...attrs].join(" ") + ">");
+		if (empty.has(tag)) return;
+		const children = tag === "template" ? node.content.childNodes : node.childNodes;
+		for (const child of children) walk(child, depth + 1);
+		lines.push(pad + "</" + tag + ">");
+	};
+	walk(root, 0);
+	return lines.join("\n") + "\n";
+})()`
+
+// DOMMatches compares the normalized markup of the first element matching
+// selector with goldenFile and fails t with a diff if they differ. Run the
+// tests with -update to create or rewrite golden files.
+func DOMMatches(t testing.TB, b Evaluator, selector, goldenFile string) {
+	t.Helper()
+	sel := fmt.Sprintf("%q", selector)
+	got, _, err := b.Eval(fmt.Sprintf(normalizeScript, sel, sel))
+	if err != nil {
+		t.Fatalf("failed to capture DOM of %s: %v", selector, err)
+		return
+	}
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(goldenFile), 0755); err != nil {
+			t.Fatalf("failed to create golden file directory: %v", err)
+			return
+		}
+		if err := os.WriteFile(goldenFile, []byte(got), 0644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenFile)
+	if os.IsNotExist(err) {
+		t.Fatalf("golden file %s does not exist; run the test with -update to create it", goldenFile)
+		return
+	}
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+		return
+	}
+	want = bytes.ReplaceAll(want, []byte("\r\n"), []byte("\n"))
+	if string(want) != got {
+		t.Errorf("DOM of %s does not match %s (-want +got):\n%s", selector, goldenFile, diff(string(want), got))
+	}
+}
+
+// diffContext is how many unchanged lines are shown around changes.
+const diffContext = 2
+
+// diff returns a line diff of a and b, marking removed lines with '-' and
+// added lines with '+'.
+func diff(a, b string) string {
+	x := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	y := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:]
+	// and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			lines = append(lines, line{' ', x[i]})
+			i, j = i+1, j+1
+		case j < len(y) && (i == len(x) || lcs[i][j+1] > lcs[i+1][j]):
+			lines = append(lines, line{'+', y[j]})
+			j++
+		default:
+			lines = append(lines, line{'-', x[i]})
+			i++
+		}
+	}
+
+	// Only print unchanged lines close to a change.
+	var out strings.Builder
+	skipped := false
+	for k, l := range lines {
+		near := l.op != ' '
+		for d := 1; d <= diffContext && !near; d++ {
+			near = k-d >= 0 && lines[k-d].op != ' ' || k+d < len(lines) && lines[k+d].op != ' '
+		}
+		if !near {
+			skipped = true
+			continue
+		}
+		if skipped {
+			out.WriteString("  ...\n")
+			skipped = false
+		}
+		fmt.Fprintf(&out, "%c %s\n", l.op, l.text)
+	}
+	return out.String()
+}
@@ -0,0 +1,61 @@
+package assert_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grngxd/majorca/majorcatest/assert"
+)
+
+// page returns fixed markup instead of evaluating anything.
+type page string
+
+func (p page) Eval(expr string) (string, string, error) {
+	return string(p), "string", nil
+}
+
+// recorder captures failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+func TestDOMMatches(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "list.golden")
+	os.WriteFile(golden, []byte("<ul>\n  <li>\n    one\n  </li>\n  <li>\n    two\n  </li>\n</ul>\n"), 0644)
+
+	r := &recorder{TB: t}
+	assert.DOMMatches(r, page("<ul>\n  <li>\n    one\n  </li>\n  <li>\n    two\n  </li>\n</ul>\n"), "ul", golden)
+	if len(r.failures) != 0 {
+		t.Fatalf("Unexpected failures: %v", r.failures)
+	}
+
+	assert.DOMMatches(r, page("<ul>\n  <li>\n    one\n  </li>\n  <li>\n    three\n  </li>\n</ul>\n"), "ul", golden)
+	if len(r.failures) != 1 {
+		t.Fatalf("Expected one failure, got %v", r.failures)
+	}
+	if msg := r.failures[0]; !strings.Contains(msg, "-     two\n+     three\n") {
+		t.Errorf("Failure does not contain the changed lines:\n%s", msg)
+	}
+}
+
+func TestDOMMatchesMissingGolden(t *testing.T) {
+	r := &recorder{TB: t}
+	assert.DOMMatches(r, page("<p>\n</p>\n"), "p", filepath.Join(t.TempDir(), "missing.golden"))
+	if len(r.failures) != 1 || !strings.Contains(r.failures[0], "-update") {
+		t.Errorf("Expected a hint to run with -update, got %v", r.failures)
+	}
+}
@@ -0,0 +1,113 @@
+// Package majorcatest speeds up UI tests by sharing one headless Chrome
+// between all tests of a package. Every test still gets a fresh page in its
+// own browser context, so cookies, storage and cache never leak from one
+// test into the next:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(majorcatest.Main(m))
+//	}
+//
+//	func TestLogin(t *testing.T) {
+//		page := majorcatest.Page(t)
+//		page.Load(server.URL)
+//		...
+//	}
+package majorcatest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/grngxd/majorca/browser"
+	"github.com/grngxd/majorca/browser/chrome"
+)
+
+var (
+	mu      sync.Mutex
+	shared  *chrome.Chrome
+	failed  error // Launch error, reported to every later test
+	options []browser.Option
+	pages   int
+)
+
+// Options sets the options the shared browser is launched with, on top of
+// browser.WithHeadless. Call it from TestMain before the first Page.
+func Options(opts ...browser.Option) {
+	mu.Lock()
+	defer mu.Unlock()
+	options = opts
+}
+
+// Page opens a blank page in a new, isolated browser context and closes
+// both when t finishes. The shared browser is launched on first use and
+// relaunched if it died; a failed launch fails every test asking for a page.
+func Page(t testing.TB) *chrome.Chrome {
+	t.Helper()
+	c, name, err := acquire()
+	if err != nil {
+		t.Fatalf("majorcatest: failed to launch browser: %v", err)
+	}
+
+	ct, err := c.Container(name)
+	if err != nil {
+		t.Fatalf("majorcatest: failed to create browser context: %v", err)
+	}
+	page, err := ct.OpenWindow("about:blank")
+	if err != nil {
+		ct.Discard()
+		t.Fatalf("majorcatest: failed to open page: %v", err)
+	}
+	t.Cleanup(func() {
+		page.Kill()
+		ct.Discard()
+	})
+	return page
+}
+
+// acquire returns the shared browser and a unique context name.
+func acquire() (*chrome.Chrome, string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if failed != nil {
+		return nil, "", failed
+	}
+	if shared != nil {
+		select {
+		case <-shared.Done():
+			shared.Kill()
+			shared = nil
+		default:
+		}
+	}
+	if shared == nil {
+		c, err := chrome.New(append([]browser.Option{browser.WithHeadless()}, options...)...)
+		if err != nil {
+			failed = err
+			return nil, "", err
+		}
+		shared = c
+	}
+	pages++
+	return shared, fmt.Sprintf("majorcatest-%d", pages), nil
+}
+
+// Close shuts the shared browser down. Main calls it after the tests ran.
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if shared == nil {
+		return nil
+	}
+	err := shared.Kill()
+	shared = nil
+	return err
+}
+
+// Main runs the tests and closes the shared browser, returning the exit
+// code for os.Exit.
+func Main(m *testing.M) int {
+	code := m.Run()
+	Close()
+	return code
+}
@@ -0,0 +1,147 @@
+package packaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+)
+
+// iconSet scales a.Icon to every size the manifests refer to.
+func iconSet(a *App) (map[string][]byte, error) {
+	f, err := os.Open(a.Icon)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	src, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode icon %s: %w", a.Icon, err)
+	}
+	if b := src.Bounds(); b.Dx() != b.Dy() {
+		return nil, fmt.Errorf("icon %s is %dx%d, want a square image", a.Icon, b.Dx(), b.Dy())
+	}
+
+	cache := make(map[int][]byte)
+	sized := func(size int) ([]byte, error) {
+		if data, ok := cache[size]; ok {
+			return data, nil
+		}
+		var b bytes.Buffer
+		if err := png.Encode(&b, Scale(src, size)); err != nil {
+			return nil, err
+		}
+		cache[size] = b.Bytes()
+		return cache[size], nil
+	}
+
+	files := make(map[string][]byte)
+	add := func(name string, size int) error {
+		data, err := sized(size)
+		files[name] = data
+		return err
+	}
+	for name, size := range map[string]int{
+		"windows/Assets/Square44x44Logo.png":   44,
+		"windows/Assets/Square150x150Logo.png": 150,
+		"windows/Assets/StoreLogo.png":         50,
+	} {
+		if err := add(name, size); err != nil {
+			return nil, err
+		}
+	}
+	for _, size := range []int{16, 32, 48, 64, 128, 256, 512} {
+		if err := add(fmt.Sprintf("linux/icons/hicolor/%dx%d/apps/%s.png", size, size, a.ID), size); err != nil {
+			return nil, err
+		}
+	}
+
+	if files["windows/app.ico"], err = container(sized, []int{16, 32, 48, 256}, writeICO); err != nil {
+		return nil, err
+	}
+	if files["darwin/"+a.ID+".icns"], err = container(sized, []int{128, 256, 512, 1024}, writeICNS); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// container packs PNGs of the given sizes into an ICO or ICNS file; both
+// formats accept embedded PNG data.
+func container(sized func(int) ([]byte, error), sizes []int, write func(sizes []int, pngs [][]byte) []byte) ([]byte, error) {
+	pngs := make([][]byte, len(sizes))
+	for i, size := range sizes {
+		data, err := sized(size)
+		if err != nil {
+			return nil, err
+		}
+		pngs[i] = data
+	}
+	return write(sizes, pngs), nil
+}
+
+func writeICO(sizes []int, pngs [][]byte) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, [3]uint16{0, 1, uint16(len(pngs))})
+	offset := 6 + 16*len(pngs)
+	for i, data := range pngs {
+		dim := byte(sizes[i]) // 256 wraps to 0, which means 256
+		b.Write([]byte{dim, dim, 0, 0})
+		binary.Write(&b, binary.LittleEndian, [2]uint16{1, 32})
+		binary.Write(&b, binary.LittleEndian, [2]uint32{uint32(len(data)), uint32(offset)})
+		offset += len(data)
+	}
+	for _, data := range pngs {
+		b.Write(data)
+	}
+	return b.Bytes()
+}
+
+// icnsTypes maps pixel sizes to ICNS element types holding PNG data.
+var icnsTypes = map[int]string{128: "ic07", 256: "ic08", 512: "ic09", 1024: "ic10"}
+
+func writeICNS(sizes []int, pngs [][]byte) []byte {
+	var body bytes.Buffer
+	for i, data := range pngs {
+		body.WriteString(icnsTypes[sizes[i]])
+		binary.Write(&body, binary.BigEndian, uint32(8+len(data)))
+		body.Write(data)
+	}
+	var b bytes.Buffer
+	b.WriteString("icns")
+	binary.Write(&b, binary.BigEndian, uint32(8+body.Len()))
+	b.Write(body.Bytes())
+	return b.Bytes()
+}
+
+// Scale resizes src to a size x size square, averaging the source pixels
+// under each target pixel so downscaled icons stay smooth.
+func Scale(src image.Image, size int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	sb := src.Bounds()
+	fx := float64(sb.Dx()) / float64(size)
+	fy := float64(sb.Dy()) / float64(size)
+	for y := 0; y < size; y++ {
+		y0 := sb.Min.Y + int(float64(y)*fy)
+		y1 := max(sb.Min.Y+int(float64(y+1)*fy), y0+1)
+		for x := 0; x < size; x++ {
+			x0 := sb.Min.X + int(float64(x)*fx)
+			x1 := max(sb.Min.X+int(float64(x+1)*fx), x0+1)
+
+			// Average in premultiplied space so transparent pixels don't
+			// darken the edges.
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			c := color.RGBA64{uint16(r / n), uint16(g / n), uint16(b / n), uint16(a / n)}
+			dst.Set(x, y, c)
+		}
+	}
+	return dst
+}
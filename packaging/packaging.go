@@ -0,0 +1,291 @@
+// Package packaging generates the metadata operating systems expect from
+// installable apps: an MSIX AppxManifest.xml for Windows, a .desktop entry
+// for Linux, an Info.plist for macOS and the icon sets they refer to. It
+// does not build installers itself; the output is meant to be fed to
+// makeappx, a .deb/.rpm/Flatpak recipe or an .app bundle layout.
+package packaging
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// App describes the app being packaged.
+type App struct {
+	// Name is the human-readable name, e.g. "Acme Notes".
+	Name string
+	// ID is a reverse-DNS identifier, e.g. "com.acme.notes". It is used as
+	// bundle identifier, MSIX package name and desktop file name.
+	ID string
+	// Version is a dotted version like "1.2.3".
+	Version     string
+	Publisher   string // MSIX publisher, e.g. "CN=Acme Inc"; defaults to "CN=" + Name
+	Description string
+	// Executable is the app binary's file name inside the package, e.g.
+	// "notes.exe" on Windows or "notes" elsewhere.
+	Executable string
+	// Icon is the path of a square PNG, ideally 1024x1024, that all icons
+	// are scaled from. Empty skips icons.
+	Icon string
+	// Scheme, when set, registers the app as handler for scheme:// URLs.
+	Scheme string
+	// SingleInstance asks the OS to focus the running instance instead of
+	// starting a second one, where the platform supports it.
+	SingleInstance bool
+	// Categories are freedesktop.org menu categories, e.g. "Office".
+	Categories []string
+}
+
+var idPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*(\.[A-Za-z][A-Za-z0-9-]*)+$`)
+
+// Validate reports missing or malformed fields.
+func (a *App) Validate() error {
+	switch {
+	case a.Name == "":
+		return fmt.Errorf("app name is required")
+	case !idPattern.MatchString(a.ID):
+		return fmt.Errorf("app id %q is not a reverse-DNS identifier like com.example.app", a.ID)
+	case a.Executable == "":
+		return fmt.Errorf("app executable is required")
+	}
+	if _, err := numericVersion(a.Version, 4); err != nil {
+		return err
+	}
+	if a.Scheme != "" && !regexp.MustCompile(`^[a-z][a-z0-9+.-]*$`).MatchString(a.Scheme) {
+		return fmt.Errorf("invalid URL scheme %q", a.Scheme)
+	}
+	return nil
+}
+
+// Write validates a and generates all manifests and icons under dir:
+//
+//	windows/AppxManifest.xml  windows/Assets/*.png  windows/app.ico
+//	linux/<id>.desktop        linux/icons/hicolor/<size>/apps/<id>.png
+//	darwin/Info.plist         darwin/<id>.icns
+func Write(a *App, dir string) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	files := make(map[string][]byte)
+	var err error
+	if files["windows/AppxManifest.xml"], err = AppxManifest(a); err != nil {
+		return err
+	}
+	if files["linux/"+a.ID+".desktop"], err = DesktopEntry(a); err != nil {
+		return err
+	}
+	if files["darwin/Info.plist"], err = InfoPlist(a); err != nil {
+		return err
+	}
+	if a.Icon != "" {
+		icons, err := iconSet(a)
+		if err != nil {
+			return err
+		}
+		for name, data := range icons {
+			files[name] = data
+		}
+	}
+
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AppxManifest returns the MSIX package manifest. Windows enforces single
+// instancing for packaged desktop apps through the app itself, so
+// SingleInstance only sets the SupportsMultipleInstances flag to false.
+func AppxManifest(a *App) ([]byte, error) {
+	version, err := numericVersion(a.Version, 4)
+	if err != nil {
+		return nil, err
+	}
+	publisher := a.Publisher
+	if publisher == "" {
+		publisher = "CN=" + a.Name
+	}
+	return render(appxTemplate, map[string]interface{}{
+		"App":       a,
+		"Version":   version,
+		"Publisher": publisher,
+	})
+}
+
+// DesktopEntry returns the freedesktop.org .desktop file.
+func DesktopEntry(a *App) ([]byte, error) {
+	return render(desktopTemplate, map[string]interface{}{"App": a})
+}
+
+// InfoPlist returns the macOS bundle Info.plist.
+func InfoPlist(a *App) ([]byte, error) {
+	version, err := numericVersion(a.Version, 3)
+	if err != nil {
+		return nil, err
+	}
+	return render(plistTemplate, map[string]interface{}{
+		"App":     a,
+		"Version": version,
+	})
+}
+
+// numericVersion pads or checks a dotted version to exactly n numeric parts,
+// as MSIX (4) and CFBundleShortVersionString (3) require.
+func numericVersion(v string, n int) (string, error) {
+	if v == "" {
+		v = "0"
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) > n {
+		return "", fmt.Errorf("version %q has more than %d parts", v, n)
+	}
+	for _, p := range parts {
+		if p == "" || strings.Trim(p, "0123456789") != "" {
+			return "", fmt.Errorf("version %q must be numeric, like 1.2.3", v)
+		}
+	}
+	for len(parts) < n {
+		parts = append(parts, "0")
+	}
+	return strings.Join(parts, "."), nil
+}
+
+var funcs = template.FuncMap{
+	"xml": func(s string) string {
+		var b bytes.Buffer
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	},
+	// desktop escapes a value for a .desktop file, where only backslashes
+	// and line breaks are special.
+	"desktop": func(s string) string {
+		return strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\t", `\t`, "\r", `\r`).Replace(s)
+	},
+	"join": strings.Join,
+}
+
+func render(tmpl *template.Template, data interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+var appxTemplate = template.Must(template.New("appx").Funcs(funcs).Parse(`<?xml version="1.0" encoding="utf-8"?>
+<Package
+  xmlns="http://schemas.microsoft.com/appx/manifest/foundation/windows10"
+  xmlns:uap="http://schemas.microsoft.com/appx/manifest/uap/windows10"
+  xmlns:desktop4="http://schemas.microsoft.com/appx/manifest/desktop/windows10/4"
+  xmlns:rescap="http://schemas.microsoft.com/appx/manifest/foundation/windows10/restrictedcapabilities"
+  IgnorableNamespaces="uap desktop4 rescap">
+  <Identity Name="{{xml .App.ID}}" Publisher="{{xml .Publisher}}" Version="{{.Version}}" ProcessorArchitecture="neutral" />
+  <Properties>
+    <DisplayName>{{xml .App.Name}}</DisplayName>
+    <PublisherDisplayName>{{xml .Publisher}}</PublisherDisplayName>
+    <Logo>Assets\StoreLogo.png</Logo>
+  </Properties>
+  <Dependencies>
+    <TargetDeviceFamily Name="Windows.Desktop" MinVersion="10.0.17763.0" MaxVersionTested="10.0.22621.0" />
+  </Dependencies>
+  <Resources>
+    <Resource Language="en-us" />
+  </Resources>
+  <Applications>
+    <Application Id="App" Executable="{{xml .App.Executable}}" EntryPoint="Windows.FullTrustApplication"{{if .App.SingleInstance}} desktop4:SupportsMultipleInstances="false"{{end}}>
+      <uap:VisualElements DisplayName="{{xml .App.Name}}" Description="{{xml (or .App.Description .App.Name)}}"
+        BackgroundColor="transparent" Square150x150Logo="Assets\Square150x150Logo.png" Square44x44Logo="Assets\Square44x44Logo.png" />
+{{- if .App.Scheme}}
+      <Extensions>
+        <uap:Extension Category="windows.protocol">
+          <uap:Protocol Name="{{xml .App.Scheme}}">
+            <uap:DisplayName>{{xml .App.Name}}</uap:DisplayName>
+          </uap:Protocol>
+        </uap:Extension>
+      </Extensions>
+{{- end}}
+    </Application>
+  </Applications>
+  <Capabilities>
+    <rescap:Capability Name="runFullTrust" />
+  </Capabilities>
+</Package>
+`))
+
+var desktopTemplate = template.Must(template.New("desktop").Funcs(funcs).Parse(`[Desktop Entry]
+Type=Application
+Version=1.5
+Name={{desktop .App.Name}}
+{{- if .App.Description}}
+Comment={{desktop .App.Description}}
+{{- end}}
+Exec={{desktop .App.Executable}}{{if .App.Scheme}} %u{{end}}
+Icon={{desktop .App.ID}}
+Terminal=false
+{{- if .App.Categories}}
+Categories={{join .App.Categories ";"}};
+{{- end}}
+{{- if .App.Scheme}}
+MimeType=x-scheme-handler/{{.App.Scheme}};
+{{- end}}
+{{- if .App.SingleInstance}}
+SingleMainWindow=true
+{{- end}}
+StartupWMClass={{desktop .App.ID}}
+`))
+
+var plistTemplate = template.Must(template.New("plist").Funcs(funcs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+  <key>CFBundleName</key>
+  <string>{{xml .App.Name}}</string>
+  <key>CFBundleDisplayName</key>
+  <string>{{xml .App.Name}}</string>
+  <key>CFBundleIdentifier</key>
+  <string>{{xml .App.ID}}</string>
+  <key>CFBundleVersion</key>
+  <string>{{.Version}}</string>
+  <key>CFBundleShortVersionString</key>
+  <string>{{.Version}}</string>
+  <key>CFBundleExecutable</key>
+  <string>{{xml .App.Executable}}</string>
+  <key>CFBundlePackageType</key>
+  <string>APPL</string>
+  <key>CFBundleIconFile</key>
+  <string>{{xml .App.ID}}.icns</string>
+  <key>NSHighResolutionCapable</key>
+  <true/>
+{{- if .App.SingleInstance}}
+  <key>LSMultipleInstancesProhibited</key>
+  <true/>
+{{- end}}
+{{- if .App.Scheme}}
+  <key>CFBundleURLTypes</key>
+  <array>
+    <dict>
+      <key>CFBundleURLName</key>
+      <string>{{xml .App.ID}}</string>
+      <key>CFBundleURLSchemes</key>
+      <array>
+        <string>{{xml .App.Scheme}}</string>
+      </array>
+    </dict>
+  </array>
+{{- end}}
+</dict>
+</plist>
+`))
@@ -0,0 +1,126 @@
+package packaging_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grngxd/majorca/packaging"
+)
+
+func testApp() *packaging.App {
+	return &packaging.App{
+		Name:           "Acme & Notes",
+		ID:             "com.acme.notes",
+		Version:        "1.2",
+		Executable:     "notes.exe",
+		Scheme:         "acme-notes",
+		SingleInstance: true,
+	}
+}
+
+func TestManifestsAreWellFormed(t *testing.T) {
+	a := testApp()
+	for name, gen := range map[string]func(*packaging.App) ([]byte, error){
+		"appx":  packaging.AppxManifest,
+		"plist": packaging.InfoPlist,
+	} {
+		data, err := gen(a)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		d := xml.NewDecoder(bytes.NewReader(data))
+		d.Strict = true
+		for {
+			_, err := d.Token()
+			if err != nil {
+				if err != io.EOF {
+					t.Errorf("%s is not well-formed XML: %v", name, err)
+				}
+				break
+			}
+		}
+		if !bytes.Contains(data, []byte("acme-notes")) {
+			t.Errorf("%s does not register the URL scheme", name)
+		}
+	}
+
+	appx, _ := packaging.AppxManifest(a)
+	if !bytes.Contains(appx, []byte(`Version="1.2.0.0"`)) {
+		t.Errorf("MSIX version was not padded to four parts:\n%s", appx)
+	}
+
+	desktop, err := packaging.DesktopEntry(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Exec=notes.exe %u", "MimeType=x-scheme-handler/acme-notes;", "SingleMainWindow=true"} {
+		if !strings.Contains(string(desktop), want) {
+			t.Errorf("desktop entry lacks %q:\n%s", want, desktop)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	a := testApp()
+	a.ID = "notes"
+	if a.Validate() == nil {
+		t.Error("Expected an error for an id without a domain")
+	}
+	a = testApp()
+	a.Version = "1.0-beta"
+	if a.Validate() == nil {
+		t.Error("Expected an error for a non-numeric version")
+	}
+}
+
+func TestWriteIcons(t *testing.T) {
+	dir := t.TempDir()
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	img.Set(0, 0, color.Transparent)
+	icon := filepath.Join(dir, "icon.png")
+	f, _ := os.Create(icon)
+	png.Encode(f, img)
+	f.Close()
+
+	a := testApp()
+	a.Icon = icon
+	out := filepath.Join(dir, "dist")
+	if err := packaging.Write(a, out); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{
+		"windows/AppxManifest.xml",
+		"windows/Assets/Square44x44Logo.png",
+		"windows/app.ico",
+		"linux/com.acme.notes.desktop",
+		"linux/icons/hicolor/256x256/apps/com.acme.notes.png",
+		"darwin/Info.plist",
+		"darwin/com.acme.notes.icns",
+	} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("missing %s", name)
+		}
+	}
+
+	icns, _ := os.ReadFile(filepath.Join(out, "darwin/com.acme.notes.icns"))
+	if !bytes.HasPrefix(icns, []byte("icns")) {
+		t.Errorf("icns file has no icns header")
+	}
+	f, _ = os.Open(filepath.Join(out, "windows/Assets/Square44x44Logo.png"))
+	defer f.Close()
+	scaled, err := png.Decode(f)
+	if err != nil || scaled.Bounds().Dx() != 44 {
+		t.Errorf("Square44x44Logo.png is not a 44px PNG: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+// Package playlist rotates a browser through pages on a schedule, for
+// digital signage: a list of URLs or HTML bundles is shown in turn, each for
+// its own duration, forever. Items that fail to load or fail their health
+// check are skipped so one broken page never blanks the screen.
+package playlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+)
+
+// DefaultDuration is used for items and playlists without a duration.
+const DefaultDuration = 30 * time.Second
+
+// Item is one entry of a playlist. Exactly one of URL and HTML is set.
+type Item struct {
+	URL  string
+	HTML string // Rendered with LoadHTML, for self-contained bundles
+	// Duration is how long the item stays on screen; zero uses the
+	// playlist's Default.
+	Duration time.Duration
+}
+
+func (it Item) String() string {
+	if it.URL != "" {
+		return it.URL
+	}
+	return "inline HTML"
+}
+
+// Playlist is a schedule of items played in order and then from the start.
+type Playlist struct {
+	Items   []Item
+	Default time.Duration // Duration for items without one; zero means DefaultDuration
+
+	// Check, when set, runs after an item was loaded. An error counts as a
+	// failed load and the item is skipped.
+	Check func(b browser.Browser, item Item) error
+
+	// Before runs before item i is loaded, e.g. to fade the current page
+	// out with Eval. After runs once it is on screen.
+	Before func(b browser.Browser, i int, item Item)
+	After  func(b browser.Browser, i int, item Item)
+
+	// OnError reports items that were skipped.
+	OnError func(i int, item Item, err error)
+
+	// Prefetch, when set and the backend supports it, warms the browser's
+	// cache with the next URL item while the current one is on screen.
+	Prefetch bool
+}
+
+// htmlLoader is implemented by backends that can render HTML directly.
+type htmlLoader interface {
+	LoadHTML(html string) error
+}
+
+// prefetcher is implemented by backends that can warm their HTTP cache.
+type prefetcher interface {
+	Prefetch(urls ...string) error
+}
+
+// Run plays the playlist on b until ctx is cancelled or the browser exits,
+// and returns the reason. When every item failed in a row, Run waits for
+// the default duration before trying again.
+func (p *Playlist) Run(ctx context.Context, b browser.Browser) error {
+	if len(p.Items) == 0 {
+		return errors.New("playlist is empty")
+	}
+	def := p.Default
+	if def <= 0 {
+		def = DefaultDuration
+	}
+
+	failures := 0
+	for i := 0; ; i = (i + 1) % len(p.Items) {
+		item := p.Items[i]
+		wait := item.Duration
+		if wait <= 0 {
+			wait = def
+		}
+
+		if err := p.show(b, i, item); err != nil {
+			if p.OnError != nil {
+				p.OnError(i, item, err)
+			}
+			failures++
+			if failures < len(p.Items) {
+				wait = 0
+			} else {
+				failures = 0
+				wait = def
+			}
+		} else {
+			failures = 0
+			p.prefetch(b, (i+1)%len(p.Items))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.Done():
+			return errors.New("browser exited")
+		case <-time.After(wait):
+		}
+	}
+}
+
+// prefetch warms the cache for item i in the background. Failures are
+// ignored; show reports them when the item is due.
+func (p *Playlist) prefetch(b browser.Browser, i int) {
+	pf, ok := browser.Unwrap(b).(prefetcher)
+	if !p.Prefetch || !ok || p.Items[i].URL == "" {
+		return
+	}
+	go pf.Prefetch(p.Items[i].URL)
+}
+
+func (p *Playlist) show(b browser.Browser, i int, item Item) error {
+	if p.Before != nil {
+		p.Before(b, i, item)
+	}
+
+	var err error
+	switch {
+	case item.URL != "":
+		err = b.Load(item.URL)
+	case item.HTML != "":
+		// WithCriticalRetry and WithLazyLaunch wrap the backend.
+		hl, ok := browser.Unwrap(b).(htmlLoader)
+		if !ok {
+			return fmt.Errorf("backend cannot render inline HTML")
+		}
+		err = hl.LoadHTML(item.HTML)
+	default:
+		return fmt.Errorf("item has neither URL nor HTML")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", item, err)
+	}
+	if p.Check != nil {
+		if err := p.Check(b, item); err != nil {
+			return fmt.Errorf("health check failed for %s: %w", item, err)
+		}
+	}
+
+	if p.After != nil {
+		p.After(b, i, item)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package playlist_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grngxd/majorca/browser"
+	"github.com/grngxd/majorca/playlist"
+)
+
+// screen records what was loaded and fails URLs listed in broken.
+type screen struct {
+	browser.BaseBrowser
+	mu     sync.Mutex
+	shown  []string
+	broken map[string]bool
+}
+
+func (s *screen) Load(url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.broken[url] {
+		return errors.New("unreachable")
+	}
+	s.shown = append(s.shown, url)
+	return nil
+}
+
+func (s *screen) LoadHTML(html string) error {
+	return s.Load("html:" + html)
+}
+
+func TestRunSkipsFailingItems(t *testing.T) {
+	s := &screen{broken: map[string]bool{"b": true}}
+	var skipped []int
+	p := &playlist.Playlist{
+		Items: []playlist.Item{
+			{URL: "a", Duration: time.Millisecond},
+			{URL: "b", Duration: time.Hour},
+			{HTML: "<p>c</p>", Duration: time.Millisecond},
+		},
+		OnError: func(i int, item playlist.Item, err error) { skipped = append(skipped, i) },
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- p.Run(ctx, s) }()
+	for {
+		s.mu.Lock()
+		n := len(s.shown)
+		s.mu.Unlock()
+		if n >= 4 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run returned %v, want context.Canceled", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if want := []string{"a", "html:<p>c</p>", "a", "html:<p>c</p>"}; !reflect.DeepEqual(s.shown[:4], want) {
+		t.Errorf("Shown %v, want %v", s.shown[:4], want)
+	}
+	if len(skipped) < 2 || skipped[0] != 1 {
+		t.Errorf("Skipped %v, want item 1 skipped every round", skipped)
+	}
+}
+
+func TestRunEmpty(t *testing.T) {
+	if err := (&playlist.Playlist{}).Run(context.Background(), &screen{}); err == nil {
+		t.Error("Expected an error for an empty playlist")
+	}
+}
+
+func TestRunWrappedBrowser(t *testing.T) {
+	s := &screen{BaseBrowser: browser.BaseBrowser{Stop: make(chan struct{}), Bindings: make(map[string]browser.BindingFunc)}}
+	browser.Register("test-playlist", browser.Factory{
+		New:       func(...browser.Option) (browser.Browser, error) { return s, nil },
+		Available: func() bool { return false },
+	})
+	b, err := browser.Open(browser.WithBackend("test-playlist"), browser.WithCriticalRetry())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &playlist.Playlist{Items: []playlist.Item{{HTML: "<p>a</p>", Duration: time.Hour}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- p.Run(ctx, b) }()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		n := len(s.shown)
+		s.mu.Unlock()
+		if n >= 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.shown) == 0 || s.shown[0] != "html:<p>a</p>" {
+		t.Errorf("Shown %v, want the inline HTML", s.shown)
+	}
+}
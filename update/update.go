@@ -0,0 +1,350 @@
+// Package update keeps a packaged majorca app up to date. It reads a JSON
+// release feed, downloads the binary for the running platform, verifies its
+// SHA-256 checksum and Ed25519 signature, and swaps it in place so the new
+// version starts on the next launch.
+//
+// A feed looks like this; assets are keyed by GOOS/GOARCH:
+//
+//	{
+//	  "version": "1.4.0",
+//	  "notes": "Faster startup",
+//	  "assets": {
+//	    "windows/amd64": {
+//	      "url": "https://example.com/app-1.4.0.exe",
+//	      "sha256": "9f86d0…",
+//	      "signature": "base64 Ed25519 signature of the Manifest"
+//	    }
+//	  }
+//	}
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Events sent through Updater.Events while updating.
+const (
+	EventAvailable = "update:available" // Payload: *Release
+	EventProgress  = "update:progress"  // Payload: Progress
+	EventReady     = "update:ready"     // Payload: *Release, restart to use it
+	EventError     = "update:error"     // Payload: error message
+)
+
+// ErrNoAsset is returned when the feed has no build for this platform.
+var ErrNoAsset = errors.New("release has no asset for this platform")
+
+// ErrNoPublicKey is returned by Verify and Install when the Updater has no
+// PublicKey; unsigned updates are never installed.
+var ErrNoPublicKey = errors.New("no public key to verify updates with")
+
+// Asset is a downloadable build for one platform.
+type Asset struct {
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// Release is the newest version announced by the feed.
+type Release struct {
+	Version string           `json:"version"`
+	Notes   string           `json:"notes,omitempty"`
+	Assets  map[string]Asset `json:"assets"`
+}
+
+// Asset returns the build for the running platform.
+func (r *Release) Asset() (Asset, error) {
+	a, ok := r.Assets[runtime.GOOS+"/"+runtime.GOARCH]
+	if !ok {
+		return Asset{}, fmt.Errorf("%w %s/%s", ErrNoAsset, runtime.GOOS, runtime.GOARCH)
+	}
+	return a, nil
+}
+
+// Progress reports how much of an update was downloaded.
+type Progress struct {
+	Version    string `json:"version"`
+	Downloaded int64  `json:"downloaded"`
+	Total      int64  `json:"total"` // -1 when unknown
+}
+
+// Emitter receives update events, e.g. a *majorca.App, which broadcasts
+// them to every window.
+type Emitter interface {
+	Broadcast(event string, payload interface{}) error
+}
+
+// Updater checks for and installs updates of the running executable.
+type Updater struct {
+	FeedURL string
+	// Current is the running app's version.
+	Current string
+	// PublicKey verifies the Ed25519 signature every asset must carry, see
+	// Sign. It is required to install updates.
+	PublicKey ed25519.PublicKey
+	// Client fetches the feed and assets; nil uses http.DefaultClient.
+	Client *http.Client
+	// Events, when set, receives the Event* events.
+	Events Emitter
+	// Executable is the file to replace; empty means os.Executable.
+	Executable string
+}
+
+// Check fetches the feed and returns the release if it is newer than
+// Current, or nil when the app is up to date.
+func (u *Updater) Check(ctx context.Context) (*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.FeedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release feed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch release feed: %s", resp.Status)
+	}
+
+	var r Release
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("failed to decode release feed: %w", err)
+	}
+	if Compare(r.Version, u.Current) <= 0 {
+		return nil, nil
+	}
+	u.emit(EventAvailable, &r)
+	return &r, nil
+}
+
+// Install downloads r's asset next to the executable, verifies it and swaps
+// it in. The running process keeps using the old binary; the new one starts
+// on the next launch. Call Cleanup on startup to remove the old binary.
+func (u *Updater) Install(ctx context.Context, r *Release) error {
+	err := u.install(ctx, r)
+	if err != nil {
+		u.emit(EventError, err.Error())
+		return err
+	}
+	u.emit(EventReady, r)
+	return nil
+}
+
+func (u *Updater) install(ctx context.Context, r *Release) error {
+	if u.PublicKey == nil {
+		return ErrNoPublicKey
+	}
+	if Compare(r.Version, u.Current) <= 0 {
+		return fmt.Errorf("release %s is not newer than %s", r.Version, u.Current)
+	}
+	asset, err := r.Asset()
+	if err != nil {
+		return err
+	}
+	exe, err := u.executable()
+	if err != nil {
+		return err
+	}
+
+	staged := exe + ".new"
+	if err := u.download(ctx, r.Version, asset, staged); err != nil {
+		os.Remove(staged)
+		return err
+	}
+	if err := u.Verify(staged, r); err != nil {
+		os.Remove(staged)
+		return err
+	}
+	if info, err := os.Stat(exe); err == nil {
+		os.Chmod(staged, info.Mode().Perm())
+	}
+	return swap(exe, staged)
+}
+
+// Manifest returns what the signature of an asset covers: the release
+// version, the GOOS/GOARCH platform and the asset's SHA-256 checksum, so a
+// signed build can be neither swapped nor replayed as another version.
+func Manifest(version, platform, sha256 string) []byte {
+	return []byte("majorca-update\nversion " + version + "\nplatform " + platform +
+		"\nsha256 " + strings.ToLower(sha256) + "\n")
+}
+
+// Sign returns the base64 Ed25519 signature of asset's Manifest, for
+// Asset.Signature. Publishers call it when building the feed.
+func Sign(key ed25519.PrivateKey, version, platform string, asset Asset) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, Manifest(version, platform, asset.SHA256)))
+}
+
+// Verify checks the file at path against the checksum and signature of r's
+// asset for the running platform. It fails without a PublicKey.
+func (u *Updater) Verify(path string, r *Release) error {
+	if u.PublicKey == nil {
+		return ErrNoPublicKey
+	}
+	asset, err := r.Asset()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), asset.SHA256) {
+		return fmt.Errorf("checksum mismatch for %s", asset.URL)
+	}
+	manifest := Manifest(r.Version, runtime.GOOS+"/"+runtime.GOARCH, asset.SHA256)
+	sig, err := base64.StdEncoding.DecodeString(asset.Signature)
+	if err != nil || !ed25519.Verify(u.PublicKey, manifest, sig) {
+		return fmt.Errorf("invalid signature for %s", asset.URL)
+	}
+	return nil
+}
+
+func (u *Updater) download(ctx context.Context, version string, asset Asset, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := u.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download update: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download update: %s", resp.Status)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	p := &progressWriter{u: u, p: Progress{Version: version, Total: resp.ContentLength}}
+	if _, err := io.Copy(f, io.TeeReader(resp.Body, p)); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to download update: %w", err)
+	}
+	return f.Close()
+}
+
+// Cleanup removes the binary replaced by an earlier Install. It is safe to
+// call on every startup.
+func Cleanup() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(exe + ".old"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// swap moves the running executable aside and the staged one into its
+// place. Renaming a running binary works on every platform, even Windows.
+func swap(exe, staged string) error {
+	old := exe + ".old"
+	os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		return fmt.Errorf("failed to move current executable aside: %w", err)
+	}
+	if err := os.Rename(staged, exe); err != nil {
+		os.Rename(old, exe)
+		return fmt.Errorf("failed to install update: %w", err)
+	}
+	return nil
+}
+
+func (u *Updater) executable() (string, error) {
+	if u.Executable != "" {
+		return u.Executable, nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return exe, nil
+}
+
+func (u *Updater) client() *http.Client {
+	if u.Client != nil {
+		return u.Client
+	}
+	return http.DefaultClient
+}
+
+func (u *Updater) emit(event string, payload interface{}) {
+	if u.Events != nil {
+		u.Events.Broadcast(event, payload)
+	}
+}
+
+// progressWriter emits progress every time another percent, or another
+// megabyte of an unknown total, was downloaded.
+type progressWriter struct {
+	u    *Updater
+	p    Progress
+	last int64
+}
+
+func (w *progressWriter) Write(b []byte) (int, error) {
+	w.p.Downloaded += int64(len(b))
+	step := int64(1 << 20)
+	if w.p.Total > 0 {
+		step = w.p.Total / 100
+	}
+	if w.p.Downloaded-w.last >= step || w.p.Downloaded == w.p.Total {
+		w.last = w.p.Downloaded
+		w.u.emit(EventProgress, w.p)
+	}
+	return len(b), nil
+}
+
+// Compare compares dotted numeric versions, ignoring a leading "v" and any
+// pre-release suffix after '-'. It returns -1, 0 or 1.
+func Compare(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(v string) []int {
+	v = strings.TrimPrefix(v, "v")
+	v, _, _ = strings.Cut(v, "-")
+	var parts []int
+	for _, s := range strings.Split(v, ".") {
+		n, _ := strconv.Atoi(s)
+		parts = append(parts, n)
+	}
+	return parts
+}
@@ -0,0 +1,139 @@
+package update_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/grngxd/majorca/update"
+)
+
+func TestCompare(t *testing.T) {
+	for _, c := range []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.10.0", "1.9.9", 1},
+		{"1.2", "1.2.1", -1},
+		{"2.0.0-beta", "2.0", 0},
+	} {
+		if got := update.Compare(c.a, c.b); got != c.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+type events []string
+
+func (e *events) Broadcast(event string, payload interface{}) error {
+	*e = append(*e, event)
+	return nil
+}
+
+func TestInstall(t *testing.T) {
+	binary := []byte("new binary")
+	sum := sha256.Sum256(binary)
+	pub, priv, _ := ed25519.GenerateKey(nil)
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/app" {
+			w.Write(binary)
+			return
+		}
+		platform := runtime.GOOS + "/" + runtime.GOARCH
+		asset := update.Asset{URL: srv.URL + "/app", SHA256: hex.EncodeToString(sum[:])}
+		asset.Signature = update.Sign(priv, "1.1.0", platform, asset)
+		json.NewEncoder(w).Encode(update.Release{
+			Version: "1.1.0",
+			Assets:  map[string]update.Asset{platform: asset},
+		})
+	}))
+	defer srv.Close()
+
+	exe := filepath.Join(t.TempDir(), "app")
+	os.WriteFile(exe, []byte("old binary"), 0755)
+	var ev events
+	u := &update.Updater{FeedURL: srv.URL, Current: "1.0.0", PublicKey: pub, Events: &ev, Executable: exe}
+
+	r, err := u.Check(context.Background())
+	if err != nil || r == nil {
+		t.Fatalf("Check returned %v, %v; want a release", r, err)
+	}
+	if err := u.Install(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(exe); string(got) != string(binary) {
+		t.Errorf("executable contains %q after install", got)
+	}
+	if old, _ := os.ReadFile(exe + ".old"); string(old) != "old binary" {
+		t.Errorf("old binary was not kept aside")
+	}
+	if len(ev) == 0 || ev[len(ev)-1] != update.EventReady {
+		t.Errorf("events %v do not end with %s", ev, update.EventReady)
+	}
+
+	u.Current = "1.1.0"
+	if r, err := u.Check(context.Background()); err != nil || r != nil {
+		t.Errorf("Check reported %v, %v for an up-to-date app", r, err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app")
+	os.WriteFile(path, []byte("data"), 0644)
+	sum := sha256.Sum256([]byte("data"))
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+
+	release := func(version string, asset update.Asset) *update.Release {
+		return &update.Release{Version: version, Assets: map[string]update.Asset{platform: asset}}
+	}
+	asset := update.Asset{SHA256: hex.EncodeToString(sum[:])}
+	asset.Signature = update.Sign(priv, "1.1.0", platform, asset)
+
+	u := &update.Updater{PublicKey: pub}
+	if err := u.Verify(path, release("1.1.0", asset)); err != nil {
+		t.Errorf("Verify rejected a valid signature: %v", err)
+	}
+	if err := u.Verify(path, release("1.2.0", asset)); err == nil {
+		t.Error("Verify accepted a signature made for another version")
+	}
+
+	// A signature of the bare file, not of its manifest.
+	raw := asset
+	raw.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte("data")))
+	if err := u.Verify(path, release("1.1.0", raw)); err == nil {
+		t.Error("Verify accepted a signature of the file alone")
+	}
+
+	unsigned := &update.Updater{}
+	if err := unsigned.Verify(path, release("1.1.0", asset)); !errors.Is(err, update.ErrNoPublicKey) {
+		t.Errorf("Verify without a public key = %v, want ErrNoPublicKey", err)
+	}
+}
+
+func TestVerifyRejectsBadSignature(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app")
+	os.WriteFile(path, []byte("data"), 0644)
+	sum := sha256.Sum256([]byte("data"))
+	pub, _, _ := ed25519.GenerateKey(nil)
+
+	u := &update.Updater{PublicKey: pub}
+	asset := update.Asset{SHA256: hex.EncodeToString(sum[:]), Signature: base64.StdEncoding.EncodeToString(make([]byte, ed25519.SignatureSize))}
+	err := u.Verify(path, &update.Release{Version: "1.1.0", Assets: map[string]update.Asset{runtime.GOOS + "/" + runtime.GOARCH: asset}})
+	if err == nil {
+		t.Error("Expected a signature error")
+	}
+}